@@ -0,0 +1,198 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zchee/llmstxt-generator/config"
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and prune the LLM response cache",
+	Long: `Inspect and prune the LLM response cache.
+
+Responses are cached on disk by a hash of the model, the prompt and the truncated page content,
+so that unchanged pages are not summarized again across runs. Entries older than --cache-ttl are
+ignored by the generator and removed by "cache prune".`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:           "stats",
+	Short:         "Print the number and size of the cached responses",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cacheStats(cmd)
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:           "prune",
+	Short:         "Remove the cached responses older than --older-than, or --cache-ttl by default",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cachePrune(cmd)
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:           "clear",
+	Short:         "Remove all cached responses",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cacheClear(cmd)
+	},
+}
+
+var (
+	cacheDir            string
+	cacheTTL            time.Duration
+	cachePruneOlderThan time.Duration
+)
+
+func init() {
+	llmstxtGeneratorCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheStatsCmd, cachePruneCmd, cacheClearCmd)
+
+	cacheCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", gollm.DefaultCacheDir(), "Directory of the LLM response cache")
+	cacheCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", config.DefaultCacheTTL, "Maximum age of the cached LLM responses (0 for no expiry)")
+	cachePruneCmd.Flags().DurationVar(&cachePruneOlderThan, "older-than", 0, "Remove the responses older than this duration instead of --cache-ttl")
+}
+
+func cacheStats(cmd *cobra.Command) error {
+	cache, err := gollm.OpenCache(cacheDir, cacheTTL)
+	if err != nil {
+		return err
+	}
+
+	type modelStats struct {
+		entries, expired int
+	}
+	var (
+		entries, expired int
+		size             int64
+		oldest, newest   time.Time
+		models           = make(map[string]*modelStats)
+		names            []string
+	)
+	err = cache.Walk(func(e *gollm.CacheEntry, n int64) error {
+		entries++
+		size += n
+		if oldest.IsZero() || e.CreatedAt.Before(oldest) {
+			oldest = e.CreatedAt
+		}
+		if e.CreatedAt.After(newest) {
+			newest = e.CreatedAt
+		}
+
+		stats, ok := models[e.Model]
+		if !ok {
+			stats = &modelStats{}
+			models[e.Model] = stats
+			names = append(names, e.Model)
+		}
+		stats.entries++
+		if cache.Expired(e) {
+			expired++
+			stats.expired++
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("read cache: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "Cache directory: %s\n", cache.Dir())
+	fmt.Fprintf(w, "Entries: %d (%d expired)\n", entries, expired)
+	fmt.Fprintf(w, "Size: %s\n", formatBytes(size))
+	if entries == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "Oldest: %s\n", oldest.Local().Format(time.DateTime))
+	fmt.Fprintf(w, "Newest: %s\n", newest.Local().Format(time.DateTime))
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s: %d entries (%d expired)\n", name, models[name].entries, models[name].expired)
+	}
+
+	return nil
+}
+
+func cachePrune(cmd *cobra.Command) error {
+	cache, err := gollm.OpenCache(cacheDir, cacheTTL)
+	if err != nil {
+		return err
+	}
+
+	var removed int
+	switch {
+	case cachePruneOlderThan > 0:
+		removed, err = cache.Prune(time.Now().Add(-cachePruneOlderThan))
+	case cacheTTL > 0:
+		removed, err = cache.PruneExpired()
+	default:
+		return fmt.Errorf("cache-ttl is 0, so no entry expires; use --older-than or cache clear")
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed %d cached responses from %s\n", removed, cache.Dir())
+
+	return nil
+}
+
+func cacheClear(cmd *cobra.Command) error {
+	cache, err := gollm.OpenCache(cacheDir, cacheTTL)
+	if err != nil {
+		return err
+	}
+	if err := cache.Clear(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Cleared %s\n", cache.Dir())
+
+	return nil
+}
+
+// formatBytes formats n as a human-readable size, such as "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -19,7 +19,9 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"net/url"
 	"os"
@@ -29,6 +31,8 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
 	"github.com/spf13/cobra"
 
 	"github.com/zchee/llmstxt-generator/config"
@@ -37,10 +41,13 @@ import (
 )
 
 var llmstxtGeneratorCmd = &cobra.Command{
-	Use:   "llmstxt-generator <url>",
+	Use:   "llmstxt-generator <url|directory>",
 	Short: "Generate llms.txt and llms-full.txt files for websites using Firecrawl",
 	Long: `Go implementation of the llms.txt generator that uses Firecrawl to map and scrape websites,
-and OpenAI to generate titles and descriptions for creating structured llms.txt files.`,
+and OpenAI to generate titles and descriptions for creating structured llms.txt files.
+
+If the argument is a local directory, Markdown, MDX and HTML files are read from it instead,
+and their paths are mapped to public URLs under --base-url.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		return generate(cmd, args)
@@ -101,185 +108,191 @@ func init() {
 		defer func() { cfg.APIKey = restoreAPIKey() }()
 	}
 
-	llmstxtGeneratorCmd.Flags().StringVar(&cfg.Model, "model", cfg.Model, "LLM model for summaries and generating concise titles and descriptions")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.Backend, "backend", cfg.Backend, `Backend for mapping and scraping websites ("firecrawl" or "crawler")`)
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.BaseURL, "base-url", cfg.BaseURL, "Public URL of the site when generating from a local directory")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.Discovery, "discovery", cfg.Discovery, `URL discovery mode ("map" or "sitemap")`)
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.Model, "model", cfg.Model, `LLM model as "<provider>:<model>", "<provider>/<model>" or a known model name (see the "models" command)`)
 	llmstxtGeneratorCmd.Flags().IntVar(&cfg.MaxURLs, "max-urls", cfg.MaxURLs, "Maximum number of URLs to process")
+	llmstxtGeneratorCmd.Flags().IntVar(&cfg.MapLimit, "map-limit", cfg.MapLimit, "Maximum number of URLs to discover before filtering (0 for --max-urls)")
+	llmstxtGeneratorCmd.Flags().StringArrayVar(&cfg.IncludePatterns, "include", cfg.IncludePatterns, `Only process URLs whose path matches the glob or "re:" regular expression (repeatable)`)
+	llmstxtGeneratorCmd.Flags().StringArrayVar(&cfg.ExcludePatterns, "exclude", cfg.ExcludePatterns, `Skip URLs whose path matches the glob or "re:" regular expression (repeatable)`)
+	llmstxtGeneratorCmd.Flags().StringArrayVar(&cfg.OptionalPatterns, "optional", cfg.OptionalPatterns, `List URLs whose path matches the glob or "re:" regular expression under the "Optional" section (repeatable)`)
+	llmstxtGeneratorCmd.Flags().Float64Var(&cfg.OptionalPriority, "optional-priority", cfg.OptionalPriority, `List pages with a sitemap priority below it under the "Optional" section (0 disables)`)
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.SectionMode, "sections", cfg.SectionMode, `Grouping of pages into llms.txt sections ("path" or "llm" to also classify pages by topic)`)
+	llmstxtGeneratorCmd.Flags().IntVar(&cfg.SectionDepth, "section-depth", cfg.SectionDepth, "Maximum number of URL path segments of a section")
+	llmstxtGeneratorCmd.Flags().StringArrayVar(&cfg.Taxonomy, "taxonomy", cfg.Taxonomy, `Section name assigned by --sections=llm, in order (repeatable; proposed by the LLM if omitted)`)
 	llmstxtGeneratorCmd.Flags().StringVar(&cfg.OutputDir, "output-dir", cfg.OutputDir, "Directory to save output files")
 	llmstxtGeneratorCmd.Flags().StringVar(&cfg.FirecrawlAPIKey, "firecrawl-api-key", fireCrawlAPIKey, "Firecrawl API key")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.FirecrawlAPIURL, "firecrawl-api-url", cfg.FirecrawlAPIURL, "Firecrawl API URL (for self-hosted Firecrawl instances)")
 	llmstxtGeneratorCmd.Flags().StringVar(&cfg.APIKey, "api-key", apiKey, "LLM client API key")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.OpenAIOption.BaseURL, "openai-base-url", cfg.OpenAIOption.BaseURL, "Base URL of an OpenAI-compatible API (e.g. vLLM, LiteLLM or OpenRouter)")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.OpenAIOption.Organization, "openai-organization", cfg.OpenAIOption.Organization, "OpenAI organization ID")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.OpenAIOption.Project, "openai-project", cfg.OpenAIOption.Project, "OpenAI project ID")
+	llmstxtGeneratorCmd.Flags().StringToStringVar(&cfg.OpenAIOption.Headers, "openai-header", cfg.OpenAIOption.Headers, "Extra HTTP header sent to the OpenAI-compatible API as key=value (repeatable)")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.OpenAIOption.AzureEndpoint, "azure-endpoint", cfg.OpenAIOption.AzureEndpoint, "Azure OpenAI resource endpoint (e.g. https://<resource>.openai.azure.com)")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.OpenAIOption.AzureDeployment, "azure-deployment", cfg.OpenAIOption.AzureDeployment, "Azure OpenAI deployment name (defaults to --model)")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.OpenAIOption.AzureAPIVersion, "azure-api-version", cfg.OpenAIOption.AzureAPIVersion, "Azure OpenAI API version")
+	llmstxtGeneratorCmd.Flags().IntVar(&cfg.OpenAIOption.MaxCompletionTokens, "openai-max-completion-tokens", cfg.OpenAIOption.MaxCompletionTokens, "max_completion_tokens of the OpenAI requests (0 sends it only to the OpenAI reasoning models)")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.OpenAIOption.Verbosity, "openai-verbosity", cfg.OpenAIOption.Verbosity, "Verbosity of the OpenAI responses: low, medium or high (empty sends it only to the OpenAI GPT-5 models)")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.AnthropicOption.CacheTTL, "anthropic-cache-ttl", cfg.AnthropicOption.CacheTTL, "Lifetime of the Anthropic prompt cache of the shared prompt prefix (5m, 1h or off)")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.OllamaOption.Host, "ollama-host", cfg.OllamaOption.Host, `Ollama server address for "ollama/<model>" models`)
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.LlamaCppOption.BaseURL, "llamacpp-url", cfg.LlamaCppOption.BaseURL, `llama.cpp server URL for "llamacpp/<model>" models`)
 	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.NoFullText, "no-full-text", cfg.NoFullText, "Don't generate llms-full.txt file")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.MirrorDir, "mirror-dir", cfg.MirrorDir, "Directory to write the Markdown mirror of each page to, at its URL path with .md appended (index.html.md for directories)")
+	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.LinkMirrors, "link-mirrors", cfg.LinkMirrors, "Link the pages of llms.txt to their Markdown mirror instead of their URL (requires --mirror-dir)")
+	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.DryRun, "dry-run", cfg.DryRun, "Plan the run and print the estimated LLM tokens and cost of each stage without summarizing")
+	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.DryRunScrape, "dry-run-scrape", cfg.DryRunScrape, "Scrape the planned pages in --dry-run to estimate from their content")
+	llmstxtGeneratorCmd.Flags().Float64Var(&cfg.MaxCost, "max-cost", cfg.MaxCost, "Stop the run before the LLM requests cost more than this amount in US dollars (0 for unlimited)")
+	llmstxtGeneratorCmd.Flags().IntVar(&cfg.MaxTokens, "max-tokens", cfg.MaxTokens, "Stop the run before the LLM requests use more than this number of tokens (0 for unlimited)")
+	llmstxtGeneratorCmd.Flags().IntVar(&cfg.MaxCredits, "max-credits", cfg.MaxCredits, "Stop the run before it uses more than this number of Firecrawl credits (0 for unlimited)")
+	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.Incremental, "incremental", cfg.Incremental, "Reuse the unchanged pages of the previous run's manifest instead of scraping and summarizing them again")
+	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.Resume, "resume", cfg.Resume, "Resume an interrupted run from its journal in the output directory, skipping the completed URLs")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.CacheDir, "cache-dir", cfg.CacheDir, "Directory of the LLM response cache")
+	llmstxtGeneratorCmd.Flags().DurationVar(&cfg.CacheTTL, "cache-ttl", cfg.CacheTTL, "Maximum age of the cached LLM responses (0 for no expiry)")
+	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.NoCache, "no-cache", cfg.NoCache, "Don't read or write the LLM response cache")
+	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.NoCtx, "no-ctx", cfg.NoCtx, "Don't generate llms-ctx.txt and llms-ctx-full.txt context files")
 	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.Verbose, "verbose", cfg.Verbose, "Enable verbose logging")
 	llmstxtGeneratorCmd.Flags().IntVar(&cfg.BatchSize, "batch-size", cfg.BatchSize, "Number of URLs to process in each batch")
 	llmstxtGeneratorCmd.Flags().IntVar(&cfg.MaxWorkers, "max-workers", cfg.MaxWorkers, "Maximum number of concurrent workers")
 	llmstxtGeneratorCmd.Flags().DurationVar(&cfg.BatchDelay, "batch-delay", cfg.BatchDelay, "Delay between batches")
+	llmstxtGeneratorCmd.Flags().MarkDeprecated("batch-delay", "use --politeness-delay instead")
+	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.RespectRobots, "respect-robots", cfg.RespectRobots, "Respect robots.txt Allow, Disallow and Crawl-delay rules for the user agent")
+	llmstxtGeneratorCmd.Flags().DurationVar(&cfg.PolitenessDelay, "politeness-delay", cfg.PolitenessDelay, "Minimum delay between requests to the same host (defaults to 0 with the firecrawl backend)")
 	llmstxtGeneratorCmd.Flags().DurationVar(&cfg.Timeout, "timeout", cfg.Timeout, "Timeout for individual URL processing")
 	llmstxtGeneratorCmd.Flags().IntVar(&cfg.MaxContentLength, "max-content-length", cfg.MaxContentLength, "Maximum content length for OpenAI processing (0 for unlimited)")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.Summarize, "summarize", cfg.Summarize, "Strategy for pages longer than --max-content-length (chunked or truncate)")
+	llmstxtGeneratorCmd.Flags().IntVar(&cfg.MaxChunks, "max-chunks", cfg.MaxChunks, "Maximum number of chunks of a long page summarized by --summarize=chunked")
+	llmstxtGeneratorCmd.Flags().IntVar(&cfg.RetryOptions.MaxAttempts, "llm-max-attempts", cfg.RetryOptions.MaxAttempts, "Maximum number of attempts of an LLM request failing with a retryable error (1 disables retries)")
+	llmstxtGeneratorCmd.Flags().DurationVar(&cfg.RetryOptions.MaxDelay, "llm-retry-max-delay", cfg.RetryOptions.MaxDelay, "Maximum backoff between LLM request attempts, including Retry-After")
+	llmstxtGeneratorCmd.Flags().IntVar(&cfg.MaxReasks, "llm-max-reasks", cfg.MaxReasks, "Maximum number of times a title or description of the wrong length is re-asked with the violation (0 disables)")
+	llmstxtGeneratorCmd.Flags().BoolVar(&cfg.LLMBatch, "llm-batch", cfg.LLMBatch, "Summarize the pages through the batch API of the provider at half price, once all pages are scraped (may take up to 24 hours)")
+	llmstxtGeneratorCmd.Flags().DurationVar(&cfg.BatchOptions.PollInterval, "llm-batch-poll-interval", cfg.BatchOptions.PollInterval, "Interval between the polls of the status of an --llm-batch batch")
+	llmstxtGeneratorCmd.Flags().StringVar(&cfg.CrawlerOptions.UserAgent, "user-agent", cfg.CrawlerOptions.UserAgent, "User-Agent sent when fetching pages, robots.txt and sitemaps directly")
+	llmstxtGeneratorCmd.Flags().IntVar(&cfg.CrawlerOptions.MaxDepth, "crawler-max-depth", cfg.CrawlerOptions.MaxDepth, "Maximum link depth followed by the crawler backend (0 for unlimited)")
 }
 
-// OpenAI:
-// - "chatgpt-4o-latest"
-// - "codex-mini-latest"
-// - "gpt-3.5-turbo"
-// - "gpt-3.5-turbo-0125"
-// - "gpt-3.5-turbo-0301"
-// - "gpt-3.5-turbo-0613"
-// - "gpt-3.5-turbo-1106"
-// - "gpt-3.5-turbo-16k"
-// - "gpt-3.5-turbo-16k-0613"
-// - "gpt-4"
-// - "gpt-4-0125-preview"
-// - "gpt-4-0314"
-// - "gpt-4-0613"
-// - "gpt-4.1"
-// - "gpt-4-1106-preview"
-// - "gpt-4.1-2025-04-14"
-// - "gpt-4.1-mini"
-// - "gpt-4.1-mini-2025-04-14"
-// - "gpt-4.1-nano"
-// - "gpt-4.1-nano-2025-04-14"
-// - "gpt-4-32k"
-// - "gpt-4-32k-0314"
-// - "gpt-4-32k-0613"
-// - "gpt-4o"
-// - "gpt-4o-2024-05-13"
-// - "gpt-4o-2024-08-06"
-// - "gpt-4o-2024-11-20"
-// - "gpt-4o-audio-preview"
-// - "gpt-4o-audio-preview-2024-10-01"
-// - "gpt-4o-audio-preview-2024-12-17"
-// - "gpt-4o-audio-preview-2025-06-03"
-// - "gpt-4o-mini"
-// - "gpt-4o-mini-2024-07-18"
-// - "gpt-4o-mini-audio-preview"
-// - "gpt-4o-mini-audio-preview-2024-12-17"
-// - "gpt-4o-mini-search-preview"
-// - "gpt-4o-mini-search-preview-2025-03-11"
-// - "gpt-4o-search-preview"
-// - "gpt-4o-search-preview-2025-03-11"
-// - "gpt-4-turbo"
-// - "gpt-4-turbo-2024-04-09"
-// - "gpt-4-turbo-preview"
-// - "gpt-4-vision-preview"
-// - "o1"
-// - "o1-2024-12-17"
-// - "o1-mini"
-// - "o1-mini-2024-09-12"
-// - "o1-preview"
-// - "o1-preview-2024-09-12"
-// - "o3"
-// - "o3-2025-04-16"
-// - "o3-mini"
-// - "o3-mini-2025-01-31"
-// - "o4-mini"
-// - "o4-mini-2025-04-16"
-//
-// Anthropic:
-// - "claude-3-5-haiku-20241022"
-// - "claude-3-5-haiku-latest"
-// - "claude-3-5-sonnet-20241022"
-// - "claude-3-5-sonnet-latest"
-// - "claude-3-7-sonnet-20250219"
-// - "claude-3-7-sonnet-latest"
-// - "claude-4-opus-20250514"
-// - "claude-4-sonnet-20250514"
-// - "claude-3-5-sonnet-20240620"
-// - "claude-opus-4-0"
-// - "claude-opus-4-1-20250805"
-// - "claude-opus-4-20250514"
-// - "claude-sonnet-4-0"
-// - "claude-sonnet-4-20250514"
-
-func detectClientFromModel(cfg *config.Config) gollm.SummarizerClient {
-	var summarizerFunc func(apiKey string) gollm.SummarizerClient
-
-	isOpenAI := func(model string) bool {
-		openAIModelPrefixes := []string{
-			"chatgpt-",
-			"codex-",
-			"gpt-",
-			"o1",
-			"o3",
-			"o4",
-		}
-		for _, prefix := range openAIModelPrefixes {
-			if strings.HasPrefix(model, prefix) {
-				return true
-			}
+// newFirecrawlClient creates the [generator.FirecrawlClient] for the configured backend and discovery mode.
+func newFirecrawlClient(cfg *config.Config, policy *generator.CrawlPolicy) (generator.FirecrawlClient, error) {
+	var client generator.FirecrawlClient
+	switch cfg.Backend {
+	case config.BackendLocal:
+		return generator.NewLocalClient(cfg.SourceDir, cfg.BaseURL)
+	case config.BackendCrawler:
+		client = generator.NewCrawlerClient(cfg.CrawlerOptions, policy)
+	default:
+		var err error
+		client, err = generator.NewFirecrawlClient(cfg.FirecrawlAPIKey, cfg.FirecrawlAPIURL)
+		if err != nil {
+			return nil, err
 		}
-		return false
 	}
 
-	switch {
-	case strings.HasPrefix(cfg.Model, "claude-"):
-		if cfg.APIKey == "" {
-			cfg.APIKey = cfg.AnthropicOption.APIKey
-		}
-		summarizerFunc = func(apiKey string) gollm.SummarizerClient {
-			return gollm.NewAnthropicClient(apiKey, cfg.Model, cfg.MaxContentLength)
-		}
+	if cfg.Discovery == config.DiscoverySitemap {
+		client = generator.NewSitemapClient(client, cfg.CrawlerOptions.UserAgent)
+	}
 
-	case isOpenAI(cfg.Model):
-		if cfg.APIKey == "" {
-			cfg.APIKey = cfg.OpenAIOption.APIKey
-		}
-		summarizerFunc = func(apiKey string) gollm.SummarizerClient {
-			return gollm.NewOpenAIClient(apiKey, cfg.Model, cfg.MaxContentLength)
-		}
+	return client, nil
+}
 
-	default:
-		panic(fmt.Errorf("unkonwn model: %v", cfg.Model))
+// newGenerationOptions returns the [generator.GenerationOptions] of cfg.
+func newGenerationOptions(cfg *config.Config, policy *generator.CrawlPolicy) generator.GenerationOptions {
+	return generator.GenerationOptions{
+		Model:            cfg.Model,
+		MaxURLs:          cfg.MaxURLs,
+		MapLimit:         cfg.MapLimit,
+		IncludePatterns:  cfg.IncludePatterns,
+		ExcludePatterns:  cfg.ExcludePatterns,
+		OptionalPatterns: cfg.OptionalPatterns,
+		OptionalPriority: cfg.OptionalPriority,
+		SectionMode:      cfg.SectionMode,
+		SectionDepth:     cfg.SectionDepth,
+		Taxonomy:         cfg.Taxonomy,
+		LinkMirrors:      cfg.LinkMirrors,
+		OutputDir:        cfg.OutputDir,
+		NoFullText:       cfg.NoFullText,
+		NoCtx:            cfg.NoCtx,
+		Verbose:          cfg.Verbose,
+		BatchSize:        cfg.BatchSize,
+		MaxWorkers:       cfg.MaxWorkers,
+		Timeout:          cfg.Timeout,
+		MaxContentLength: cfg.MaxContentLength,
+		Summarize:        cfg.Summarize,
+		MaxChunks:        cfg.MaxChunks,
+		FirecrawlOptions: cfg.FirecrawlOptions,
+		CrawlPolicy:      policy,
+		Budget:           cfg.Budget(),
 	}
-
-	return summarizerFunc(cfg.APIKey)
 }
 
 func generate(cmd *cobra.Command, args []string) (err error) {
+	targetURL := args[0]
+	if info, err := os.Stat(targetURL); err == nil && info.IsDir() {
+		cfg.Backend = config.BackendLocal
+		cfg.SourceDir = targetURL
+		targetURL = cfg.BaseURL
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	targetURL := args[0]
 	targetURL, err = normalizeURL(targetURL)
 	if err != nil {
 		return fmt.Errorf("normalize URL: %w", err)
 	}
 
-	stat, err := os.Stat(cfg.OutputDir)
-	if err != nil && os.IsNotExist(err) {
-		if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
-			return fmt.Errorf("create output directory: %w", err)
-		}
+	logger := setupLogger(cfg.Verbose)
+
+	switch {
+	case cmd.Flags().Changed("politeness-delay"):
+		// nothing to do
+	case cmd.Flags().Changed("batch-delay"):
+		cfg.PolitenessDelay = cfg.BatchDelay
+	case cfg.Backend == config.BackendFirecrawl:
+		// Firecrawl fetches the pages and paces its own requests, so the default delay would only serialize the workers
+		cfg.PolitenessDelay = 0
 	}
-	if !stat.IsDir() {
-		return fmt.Errorf("output-dir exist but not directory: %w", err)
+	var policy *generator.CrawlPolicy
+	if cfg.Backend != config.BackendLocal {
+		policy = generator.NewCrawlPolicy(cfg.CrawlerOptions.UserAgent, cfg.RespectRobots, cfg.PolitenessDelay)
 	}
 
-	logger := setupLogger(cfg.Verbose)
-
-	firecrawlClient, err := generator.NewFirecrawlClient(cfg.FirecrawlAPIKey)
+	firecrawlClient, err := newFirecrawlClient(cfg, policy)
 	if err != nil {
 		return err
 	}
 
-	client := detectClientFromModel(cfg)
-	options := generator.GenerationOptions{
-		Model:            cfg.Model,
-		MaxURLs:          cfg.MaxURLs,
-		OutputDir:        cfg.OutputDir,
-		NoFullText:       cfg.NoFullText,
-		Verbose:          cfg.Verbose,
-		BatchSize:        cfg.BatchSize,
-		MaxWorkers:       cfg.MaxWorkers,
-		BatchDelay:       cfg.BatchDelay,
-		Timeout:          cfg.Timeout,
-		MaxContentLength: cfg.MaxContentLength,
-		FirecrawlOptions: cfg.FirecrawlOptions,
+	if cfg.DryRun {
+		return dryRun(cmd, firecrawlClient, policy, targetURL)
 	}
 
-	gen := generator.NewLLMsTxtGenerator(firecrawlClient, client, options)
+	if err := ensureDir(cfg.OutputDir); err != nil {
+		return fmt.Errorf("output-dir: %w", err)
+	}
+	if cfg.MirrorDir != "" {
+		if err := ensureDir(cfg.MirrorDir); err != nil {
+			return fmt.Errorf("mirror-dir: %w", err)
+		}
+	}
 
-	result, err := gen.GenerateLLMsTXT(cmd.Context(), targetURL)
+	llmOptions := cfg.LLMOptions()
+	if !cfg.NoCache {
+		cache, err := gollm.OpenCache(cfg.CacheDir, cfg.CacheTTL)
+		if err != nil {
+			return fmt.Errorf("cache-dir: %w", err)
+		}
+		llmOptions.Cache = cache
+	}
+	client, err := gollm.New(cfg.Model, llmOptions)
 	if err != nil {
-		return fmt.Errorf("generate llms.txt: %w", err)
+		return err
+	}
+	var batch gollm.BatchClient
+	if cfg.LLMBatch {
+		batch, err = gollm.NewBatch(cfg.Model, llmOptions)
+		if err != nil {
+			return err
+		}
 	}
 
 	domain, err := generator.ParseDomainFromURL(targetURL)
@@ -287,6 +300,41 @@ func generate(cmd *cobra.Command, args []string) (err error) {
 		return fmt.Errorf("extract domain from URL: %w", err)
 	}
 
+	manifestPath := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s-llms-manifest.json", domain))
+	var previous *generator.Manifest
+	if cfg.Incremental {
+		previous, err = generator.ReadManifest(manifestPath)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			logger.InfoContext(cmd.Context(), "No previous manifest, processing all pages", "path", manifestPath)
+		case err != nil:
+			return err
+		default:
+			logger.InfoContext(cmd.Context(), "Loaded previous manifest", "path", manifestPath, "pages", len(previous.Pages), "generated_at", previous.GeneratedAt)
+		}
+	}
+	journalPath := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s-llms-journal.jsonl", domain))
+	journal, err := generator.OpenJournal(journalPath, cfg.Resume)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+
+	options := newGenerationOptions(cfg, policy)
+	options.Previous = previous
+	options.Journal = journal
+	options.Batch = batch
+
+	gen := generator.NewLLMsTxtGenerator(firecrawlClient, client, options)
+
+	result, err := gen.GenerateLLMsTXT(cmd.Context(), targetURL)
+	if err != nil {
+		if cmd.Context().Err() != nil {
+			return fmt.Errorf("generate llms.txt: %w; run again with --resume to continue from %s", err, journalPath)
+		}
+		return fmt.Errorf("generate llms.txt: %w", err)
+	}
+
 	llmsTxtPath := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s-llms.txt", domain))
 	if err := os.WriteFile(llmsTxtPath, []byte(result.LLMsTxt), 0644); err != nil {
 		return fmt.Errorf("write llms.txt file: %w", err)
@@ -302,12 +350,134 @@ func generate(cmd *cobra.Command, args []string) (err error) {
 		logger.InfoContext(cmd.Context(), "Saved llms-full.txt", "path", llmsFullTxtPath)
 	}
 
+	if !cfg.NoCtx {
+		llmsCtxTxtPath := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s-llms-ctx.txt", domain))
+		if err := os.WriteFile(llmsCtxTxtPath, []byte(result.LLMsCtxTxt), 0644); err != nil {
+			return fmt.Errorf("write llms-ctx.txt file: %w", err)
+		}
+		logger.InfoContext(cmd.Context(), "Saved llms-ctx.txt", "path", llmsCtxTxtPath)
+
+		llmsCtxFullTxtPath := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s-llms-ctx-full.txt", domain))
+		if err := os.WriteFile(llmsCtxFullTxtPath, []byte(result.LLMsCtxFullTxt), 0644); err != nil {
+			return fmt.Errorf("write llms-ctx-full.txt file: %w", err)
+		}
+		logger.InfoContext(cmd.Context(), "Saved llms-ctx-full.txt", "path", llmsCtxFullTxtPath)
+	}
+
+	if cfg.MirrorDir != "" {
+		n, err := generator.WriteMarkdownMirror(cfg.MirrorDir, result.Sections)
+		if err != nil {
+			return fmt.Errorf("write Markdown mirror: %w", err)
+		}
+		logger.InfoContext(cmd.Context(), "Saved Markdown mirror", "path", cfg.MirrorDir, "files", n)
+	}
+
+	if err := result.Manifest.WriteFile(manifestPath); err != nil {
+		return err
+	}
+	logger.InfoContext(cmd.Context(), "Saved manifest", "path", manifestPath, "pages", len(result.Manifest.Pages))
+
+	failuresPath := filepath.Join(cfg.OutputDir, fmt.Sprintf("%s-llms-failures.json", domain))
+	if err := writeFailures(failuresPath, result.Failures); err != nil {
+		return err
+	}
+	if len(result.Failures) > 0 {
+		logger.WarnContext(cmd.Context(), "Saved failures", "path", failuresPath, "count", len(result.Failures))
+	}
+
+	// keep the journal of a run stopped by the budget, so that it can be resumed with a larger budget
+	if !result.BudgetExhausted {
+		if err := journal.Remove(); err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprintf(cmd.OutOrStdout(), "\nSuccess! Processed %d out of %d URLs\n", result.ProcessedCount, result.TotalCount)
+	if len(result.Failures) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Failed %d URLs, see %s\n", len(result.Failures), failuresPath)
+	}
+	if result.BudgetExhausted {
+		fmt.Fprintf(cmd.OutOrStdout(), "Budget exhausted: skipped %d URLs, the output is partial; run again with --resume and a larger budget to continue\n", result.Skipped)
+	}
+	if result.Spent != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "Spent %d LLM tokens, $%.4f and %d Firecrawl credits of the budget\n", result.Spent.Tokens, result.Spent.Cost, result.Spent.Credits)
+	}
+	if result.Filter != nil {
+		filtered := result.Filter.NotIncluded
+		for _, stat := range result.Filter.Rules {
+			filtered += stat.Filtered
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Filtered %d URLs by include and exclude patterns\n", filtered)
+	}
+	if result.Reused > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Reused %d unchanged pages from the previous run\n", result.Reused)
+	}
+	if result.Usage.CacheHits > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Served %d LLM responses from the cache\n", result.Usage.CacheHits)
+	}
+	if result.Usage.InputTokens > 0 || result.Usage.OutputTokens > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Used %d input and %d output LLM tokens", result.Usage.InputTokens, result.Usage.OutputTokens)
+		if result.Usage.CacheReadTokens > 0 || result.Usage.CacheWriteTokens > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), ", read %d and wrote %d prompt cache tokens", result.Usage.CacheReadTokens, result.Usage.CacheWriteTokens)
+		}
+		if cost, ok := formatCost(result.Usage, result.BatchUsage); ok {
+			fmt.Fprintf(cmd.OutOrStdout(), " (%s)", cost)
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+	}
+	if result.Usage.Retries > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Retried %d of %d LLM requests\n", result.Usage.Retries, result.Usage.Requests)
+	}
+	if result.Usage.Reasks > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Re-asked %d LLM responses of the wrong length\n", result.Usage.Reasks)
+	}
+	if len(result.Excluded) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Excluded %d URLs by crawl policy\n", len(result.Excluded))
+	}
 	fmt.Fprintf(cmd.OutOrStdout(), "Files saved to %s/\n", cfg.OutputDir)
 
 	return nil
 }
 
+// ensureDir creates the directory dir if it doesn't exist.
+func ensureDir(dir string) error {
+	stat, err := os.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !stat.IsDir() {
+		return fmt.Errorf("%s exists but is not a directory", dir)
+	}
+
+	return nil
+}
+
+// writeFailures writes failures to path as a JSON array, or removes a stale file of a previous run if there are none.
+func writeFailures(path string, failures []generator.Failure) error {
+	if len(failures) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove stale failures file: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(failures, jsontext.WithIndent("  "))
+	if err != nil {
+		return fmt.Errorf("marshal failures: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write failures file: %w", err)
+	}
+
+	return nil
+}
+
 func TruncateText(text string, maxLength int) string {
 	if len(text) <= maxLength {
 		return text
@@ -0,0 +1,118 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+
+	"github.com/zchee/llmstxt-generator/generator"
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+func TestWriteFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llms-failures.json")
+	failures := []generator.Failure{
+		{URL: "https://example.com/a", Stage: generator.StageScrape, Error: "scrape failed", Attempts: 1},
+		{URL: "https://example.com/b", Stage: generator.StageSummarize, Error: "summarize failed", Attempts: 3},
+	}
+
+	if err := writeFailures(path, failures); err != nil {
+		t.Fatalf("writeFailures() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []generator.Failure
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal failures file: %v\n%s", err, data)
+	}
+	if !slices.Equal(got, failures) {
+		t.Errorf("writeFailures() wrote %+v, want %+v", got, failures)
+	}
+
+	// a run without failures removes the report of the previous run
+	if err := writeFailures(path, nil); err != nil {
+		t.Fatalf("writeFailures() error = %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("writeFailures() left a stale failures file: %v", err)
+	}
+	if err := writeFailures(path, nil); err != nil {
+		t.Errorf("writeFailures() without a failures file error = %v", err)
+	}
+}
+
+func TestFormatCost(t *testing.T) {
+	usage := gollm.Usage{Requests: 10, InputTokens: 1_000_000, OutputTokens: 1_000_000}
+	tests := map[string]struct {
+		model  string
+		batch  gollm.Usage
+		want   string
+		wantOK bool
+	}{
+		"standard": {
+			model:  "gpt-4o-mini",
+			want:   "$0.7500",
+			wantOK: true,
+		},
+		"batch": {
+			model:  "gpt-4o-mini",
+			batch:  usage,
+			want:   "$0.3750",
+			wantOK: true,
+		},
+		"partial batch": {
+			model:  "gpt-4o-mini",
+			batch:  gollm.Usage{Requests: 5, InputTokens: 1_000_000},
+			want:   "$0.6750",
+			wantOK: true,
+		},
+		"anthropic": {
+			model:  "claude-3-5-haiku-latest",
+			want:   "$4.8000",
+			wantOK: true,
+		},
+		"local": {
+			model:  "ollama:llama3.2",
+			want:   "$0.0000",
+			wantOK: true,
+		},
+		"unknown model": {
+			model: "mistral-large",
+			want:  "unknown",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func(model string) { cfg.Model = model }(cfg.Model)
+			cfg.Model = tt.model
+
+			got, ok := formatCost(usage, tt.batch)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("formatCost() = %q, %t, want %q, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
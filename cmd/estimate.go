@@ -0,0 +1,92 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zchee/llmstxt-generator/generator"
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+// dryRun plans the run of targetURL and prints the estimated usage and cost of each stage, without summarizing.
+func dryRun(cmd *cobra.Command, firecrawlClient generator.FirecrawlClient, policy *generator.CrawlPolicy, targetURL string) error {
+	// the estimate never calls the summarizer
+	gen := generator.NewLLMsTxtGenerator(firecrawlClient, nil, newGenerationOptions(cfg, policy))
+
+	estimate, err := gen.Estimate(cmd.Context(), targetURL, cfg.DryRunScrape)
+	if err != nil {
+		return fmt.Errorf("estimate llms.txt: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "\nDry run of %s with %s\n", estimate.URL, estimate.Model)
+	fmt.Fprintf(w, "Planned %d URLs", estimate.URLs)
+	if len(estimate.Excluded) > 0 {
+		fmt.Fprintf(w, ", excluded %d by crawl policy", len(estimate.Excluded))
+	}
+	fmt.Fprintln(w)
+	if cfg.DryRunScrape {
+		fmt.Fprintf(w, "Scraped %d pages", estimate.Scraped)
+		if len(estimate.Failures) > 0 {
+			fmt.Fprintf(w, ", failed %d (left out of the estimate)", len(estimate.Failures))
+		}
+		fmt.Fprintln(w)
+	} else {
+		fmt.Fprintf(w, "Assuming %d bytes of content per page, use --dry-run-scrape to measure the pages\n", estimate.AssumedPageLength)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Stage\tRequests\tInput tokens\tOutput tokens\tCost\t\n")
+	var batch gollm.Usage
+	for _, stage := range estimate.Stages {
+		var stageBatch gollm.Usage
+		if cfg.LLMBatch && stage.Stage == generator.EstimatePages {
+			// only the pages are summarized in a batch
+			stageBatch = stage.Usage
+			batch.Add(stage.Usage)
+		}
+		cost, _ := formatCost(stage.Usage, stageBatch)
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t\n", stage.Stage, stage.Usage.Requests, stage.Usage.InputTokens, stage.Usage.OutputTokens, cost)
+	}
+	total := estimate.Total()
+	cost, _ := formatCost(total, batch)
+	fmt.Fprintf(tw, "total\t%d\t%d\t%d\t%s\t\n", total.Requests, total.InputTokens, total.OutputTokens, cost)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nTokens are estimated at 4 bytes per token and %d output tokens per request, without retries,\n", gollm.EstimatedOutputTokens)
+	fmt.Fprintln(w, "cached responses and the reasoning tokens of reasoning models.")
+
+	return nil
+}
+
+// formatCost formats the cost of u with the price of the configured model, such as "$0.0123".
+// The part of u sent through a batch API, batch, is billed at the batch price.
+func formatCost(u, batch gollm.Usage) (string, bool) {
+	price, ok := gollm.LookupPrice(cfg.Model, cfg.LLMOptions())
+	if !ok {
+		return "unknown", false
+	}
+	cost := price.Cost(u) - price.Cost(batch) + price.Batch().Cost(batch)
+	return fmt.Sprintf("$%.4f", cost), true
+}
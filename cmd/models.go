@@ -0,0 +1,75 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+var modelsCmd = &cobra.Command{
+	Use:   "models [provider]",
+	Short: "List the registered LLM providers and their known models",
+	Long: `List the registered LLM providers and their known models.
+
+Models are addressed as "<provider>:<model>" or "<provider>/<model>", or by their name alone if
+it starts with one of the model prefixes of a provider. Providers may accept models which are not
+listed. With --openai-base-url or --azure-endpoint, "<provider>/<model>" is sent unchanged to the
+OpenAI-compatible endpoint, so only "<provider>:<model>" selects another provider.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listModels(cmd, args)
+	},
+}
+
+func init() {
+	llmstxtGeneratorCmd.AddCommand(modelsCmd)
+}
+
+func listModels(cmd *cobra.Command, args []string) error {
+	providers := gollm.Providers()
+	if len(args) > 0 {
+		var found bool
+		for _, p := range providers {
+			if p.Name == args[0] {
+				providers, found = []gollm.Provider{p}, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown provider %q", args[0])
+		}
+	}
+
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "Default model: %s\n", gollm.DefaultModel)
+	for _, p := range providers {
+		fmt.Fprintf(w, "\n%s: %s\n", p.Name, p.Description)
+		if len(p.Prefixes) > 0 {
+			fmt.Fprintf(w, "  prefixes: %s\n", strings.Join(p.Prefixes, ", "))
+		}
+		for _, model := range p.Models {
+			fmt.Fprintf(w, "  %s/%s\n", p.Name, model)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/spf13/cobra"
+
+	"github.com/zchee/llmstxt-generator/llmstxt"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <file|url>",
+	Short: "Validate an llms.txt file against the llms.txt format",
+	Long: `Validate an llms.txt file against the format of https://llmstxt.org/#format.
+
+The file is read from a local path, from an http(s) URL, or from stdin if the argument is "-".
+Diagnostics are printed as "<file>:<line>: <severity>: <message> (<code>)", or as JSON with --json.
+The command exits with a non-zero status if any error is found, or any warning with --strict.`,
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return validate(cmd, args[0])
+	},
+}
+
+var (
+	validateJSON   bool
+	validateStrict bool
+)
+
+// maxValidateSize is the maximum size of an llms.txt read by the validate command.
+const maxValidateSize = 32 << 20
+
+func init() {
+	llmstxtGeneratorCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "Print the diagnostics as JSON")
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Exit with a non-zero status on warnings too")
+}
+
+// validateReport is the JSON output of the validate command.
+type validateReport struct {
+	File        string               `json:"file"`
+	Valid       bool                 `json:"valid"`
+	Errors      int                  `json:"errors"`
+	Warnings    int                  `json:"warnings"`
+	Sections    int                  `json:"sections"`
+	Links       int                  `json:"links"`
+	Diagnostics []llmstxt.Diagnostic `json:"diagnostics"`
+}
+
+func validate(cmd *cobra.Command, name string) error {
+	src, err := readLLMsTxt(cmd.Context(), cmd.InOrStdin(), name)
+	if err != nil {
+		return err
+	}
+
+	doc, diags := llmstxt.Parse(src)
+
+	report := validateReport{
+		File:        name,
+		Sections:    len(doc.Sections),
+		Diagnostics: diags,
+	}
+	for _, section := range doc.Sections {
+		report.Links += len(section.Links)
+	}
+	for _, d := range diags {
+		switch d.Severity {
+		case llmstxt.SeverityError:
+			report.Errors++
+		case llmstxt.SeverityWarning:
+			report.Warnings++
+		}
+	}
+	report.Valid = report.Errors == 0 && (!validateStrict || report.Warnings == 0)
+	if report.Diagnostics == nil {
+		report.Diagnostics = []llmstxt.Diagnostic{}
+	}
+
+	w := cmd.OutOrStdout()
+	if validateJSON {
+		data, err := json.Marshal(report, jsontext.WithIndent("  "))
+		if err != nil {
+			return fmt.Errorf("marshal diagnostics: %w", err)
+		}
+		fmt.Fprintf(w, "%s\n", data)
+	} else {
+		for _, d := range diags {
+			fmt.Fprintf(w, "%s:%s\n", name, d)
+		}
+		fmt.Fprintf(w, "%s: %d sections, %d links, %d errors, %d warnings\n", name, report.Sections, report.Links, report.Errors, report.Warnings)
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("%s is not a valid llms.txt: %d errors, %d warnings", name, report.Errors, report.Warnings)
+	}
+
+	return nil
+}
+
+// readLLMsTxt reads name from stdin if it is "-", over HTTP if it is an http(s) URL, or from the file system.
+func readLLMsTxt(ctx context.Context, stdin io.Reader, name string) (string, error) {
+	var r io.Reader
+	switch {
+	case name == "-":
+		r = stdin
+
+	case strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://"):
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, name, nil)
+		if err != nil {
+			return "", fmt.Errorf("create request: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("fetch %s: %w", name, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("fetch %s: %s", name, resp.Status)
+		}
+		r = resp.Body
+
+	default:
+		f, err := os.Open(name)
+		if err != nil {
+			return "", fmt.Errorf("open llms.txt: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxValidateSize+1))
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", name, err)
+	}
+	if len(data) > maxValidateSize {
+		return "", fmt.Errorf("read %s: larger than %d bytes", name, maxValidateSize)
+	}
+
+	return string(data), nil
+}
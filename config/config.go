@@ -22,52 +22,130 @@
 //   - Configuration validation
 //   - API key management
 //   - Processing parameters (timeouts, batch sizes, etc.)
-//   - Firecrawl and OpenAI client configuration options
+//   - Firecrawl and LLM client configuration options
 package config
 
 import (
+	"cmp"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/zchee/llmstxt-generator/generator"
 	"github.com/zchee/llmstxt-generator/gollm"
 )
 
+// Backends for mapping and scraping websites.
+const (
+	// BackendFirecrawl maps and scrapes websites using the Firecrawl API.
+	BackendFirecrawl = "firecrawl"
+	// BackendCrawler maps and scrapes websites using the built-in crawler.
+	BackendCrawler = "crawler"
+	// BackendLocal reads pages from a local directory. It is selected when the target is a directory.
+	BackendLocal = "local"
+)
+
+// Discovery modes for finding the URLs of a website.
+const (
+	// DiscoveryMap discovers URLs using the MapWebsite of the configured backend.
+	DiscoveryMap = "map"
+	// DiscoverySitemap discovers URLs from robots.txt and sitemap.xml, falling back to [DiscoveryMap].
+	DiscoverySitemap = "sitemap"
+)
+
+// DefaultCacheTTL is the default maximum age of the cached LLM responses.
+const DefaultCacheTTL = 30 * 24 * time.Hour
+
 // Config represents the configuration for the llmstxt-generator.
 type Config struct {
+	Backend          string
+	Discovery        string
+	SourceDir        string
+	BaseURL          string
 	FirecrawlAPIKey  string
+	FirecrawlAPIURL  string
 	APIKey           string
 	Model            string
 	MaxURLs          int
+	MapLimit         int
+	IncludePatterns  []string
+	ExcludePatterns  []string
+	OptionalPatterns []string
+	OptionalPriority float64
+	SectionMode      string
+	SectionDepth     int
+	Taxonomy         []string
 	OutputDir        string
 	NoFullText       bool
+	NoCtx            bool
+	MirrorDir        string
+	LinkMirrors      bool
+	Incremental      bool
+	Resume           bool
+	DryRun           bool
+	DryRunScrape     bool
+	MaxCost          float64
+	MaxTokens        int
+	MaxCredits       int
+	CacheDir         string
+	CacheTTL         time.Duration
+	NoCache          bool
 	Verbose          bool
 	BatchSize        int
 	MaxWorkers       int
 	BatchDelay       time.Duration
+	RespectRobots    bool
+	PolitenessDelay  time.Duration
 	Timeout          time.Duration
 	MaxContentLength int
+	Summarize        string
+	MaxChunks        int
+	RetryOptions     gollm.RetryOptions
+	MaxReasks        int
+	LLMBatch         bool
+	BatchOptions     gollm.BatchOptions
 	FirecrawlOptions generator.FirecrawlOptions
+	CrawlerOptions   generator.CrawlerOptions
 	OpenAIOption     gollm.OpenAIConfig
 	AnthropicOption  gollm.AnthropicConfig
+	OllamaOption     gollm.OllamaConfig
+	LlamaCppOption   gollm.LlamaCppConfig
 }
 
 // New returns the default configuration for the llmstxt-generator.
 func New() *Config {
 	return &Config{
-		FirecrawlAPIKey: os.Getenv("FIRECRAWL_API_KEY"),
-		MaxURLs:         20,
-		OutputDir:       ".",
-		NoFullText:      false,
-		Verbose:         false,
-		BatchSize:       10,
-		MaxWorkers:      5,
-		BatchDelay:      time.Second,
-		Timeout:         30 * time.Second,
+		Backend:          BackendFirecrawl,
+		Discovery:        DiscoveryMap,
+		FirecrawlAPIKey:  os.Getenv("FIRECRAWL_API_KEY"),
+		FirecrawlAPIURL:  cmp.Or(os.Getenv("FIRECRAWL_API_URL"), generator.DefaultFirecrawlAPIURL),
+		Model:            gollm.DefaultModel,
+		MaxURLs:          20,
+		OptionalPriority: 0.5,
+		SectionMode:      generator.SectionsByPath,
+		SectionDepth:     2,
+		OutputDir:        ".",
+		NoFullText:       false,
+		CacheDir:         gollm.DefaultCacheDir(),
+		CacheTTL:         DefaultCacheTTL,
+		Verbose:          false,
+		BatchSize:        10,
+		MaxWorkers:       5,
+		BatchDelay:       time.Second,
+		RespectRobots:    true,
+		PolitenessDelay:  time.Second,
+		Timeout:          30 * time.Second,
 		// TODO(zchee): `4000` default value is the same as [mendableai/create-llmstxt-py](https://github.com/mendableai/create-llmstxt-py) for the moment.
 		// See https://github.com/mendableai/create-llmstxt-py/blob/c015913a7e71/generate-llmstxt.py#L133
 		MaxContentLength: 4000,
+		Summarize:        generator.SummarizeChunked,
+		MaxChunks:        gollm.DefaultMaxChunks,
+		RetryOptions:     gollm.DefaultRetryOptions,
+		MaxReasks:        gollm.DefaultMaxReasks,
+		BatchOptions: gollm.BatchOptions{
+			PollInterval: gollm.DefaultBatchPollInterval,
+		},
 		FirecrawlOptions: generator.FirecrawlOptions{
 			OnlyMainContent:   true,                 // Default to previous hard-coded value
 			Timeout:           30000,                // Default to previous hard-coded value (30 seconds in ms)
@@ -75,29 +153,102 @@ func New() *Config {
 			IncludeSubdomains: false,                // Default conservative setting
 			IgnoreSitemap:     false,                // Default conservative setting
 		},
+		CrawlerOptions: generator.CrawlerOptions{
+			UserAgent: generator.DefaultUserAgent,
+			MaxDepth:  3,
+		},
 		OpenAIOption: gollm.OpenAIConfig{
 			Config: gollm.Config{
-				APIKey: os.Getenv("OPENAI_API_KEY"),
+				APIKey: cmp.Or(os.Getenv("OPENAI_API_KEY"), os.Getenv("AZURE_OPENAI_API_KEY")),
 			},
+			BaseURL:         os.Getenv("OPENAI_BASE_URL"),
+			Organization:    os.Getenv("OPENAI_ORG_ID"),
+			Project:         os.Getenv("OPENAI_PROJECT_ID"),
+			AzureEndpoint:   os.Getenv("AZURE_OPENAI_ENDPOINT"),
+			AzureDeployment: os.Getenv("AZURE_OPENAI_DEPLOYMENT"),
+			AzureAPIVersion: cmp.Or(os.Getenv("OPENAI_API_VERSION"), gollm.DefaultAzureAPIVersion),
 		},
 		AnthropicOption: gollm.AnthropicConfig{
 			Config: gollm.Config{
 				APIKey: os.Getenv("ANTHROPIC_API_KEY"),
 			},
+			CacheTTL: gollm.AnthropicCacheTTL5m,
+		},
+		OllamaOption: gollm.OllamaConfig{
+			Host: cmp.Or(os.Getenv("OLLAMA_HOST"), gollm.DefaultOllamaHost),
+		},
+		LlamaCppOption: gollm.LlamaCppConfig{
+			Config: gollm.Config{
+				APIKey: os.Getenv("LLAMACPP_API_KEY"),
+			},
+			BaseURL: cmp.Or(os.Getenv("LLAMACPP_BASE_URL"), gollm.DefaultLlamaCppURL),
 		},
 	}
 }
 
 // Validate validates for each [Config] field value.
 func (c *Config) Validate() error {
-	if c.FirecrawlAPIKey == "" {
-		return fmt.Errorf("Firecrawl API key not provided. Set FIRECRAWL_API_KEY environment variable or use --firecrawl-api-key flag")
+	switch c.Backend {
+	case BackendFirecrawl:
+		// self-hosted Firecrawl instances may run without authentication
+		if c.FirecrawlAPIKey == "" && c.IsHostedFirecrawl() {
+			return fmt.Errorf("Firecrawl API key not provided. Set FIRECRAWL_API_KEY environment variable or use --firecrawl-api-key flag")
+		}
+	case BackendCrawler:
+		if c.CrawlerOptions.MaxDepth < 0 {
+			return fmt.Errorf("crawler-max-depth must be greater than or equal to 0")
+		}
+	case BackendLocal:
+		if c.BaseURL == "" {
+			return fmt.Errorf("base-url must be provided when generating from the local directory %s", c.SourceDir)
+		}
+		if c.Discovery == DiscoverySitemap {
+			return fmt.Errorf("sitemap discovery is not supported when generating from a local directory")
+		}
+	default:
+		return fmt.Errorf("unknown backend %q: must be %q or %q", c.Backend, BackendFirecrawl, BackendCrawler)
+	}
+
+	switch c.Discovery {
+	case DiscoveryMap, DiscoverySitemap:
+	default:
+		return fmt.Errorf("unknown discovery mode %q: must be %q or %q", c.Discovery, DiscoveryMap, DiscoverySitemap)
 	}
 
 	if c.MaxURLs <= 0 {
 		return fmt.Errorf("max-urls must be greater than 0")
 	}
 
+	if c.MapLimit < 0 {
+		return fmt.Errorf("map-limit must be greater than or equal to 0")
+	}
+
+	if _, err := generator.NewURLFilter(c.IncludePatterns, c.ExcludePatterns); err != nil {
+		return err
+	}
+
+	if _, err := generator.NewURLFilter(c.OptionalPatterns, nil); err != nil {
+		return fmt.Errorf("optional %w", err)
+	}
+
+	if c.OptionalPriority < 0 || c.OptionalPriority > 1 {
+		return fmt.Errorf("optional-priority must be between 0 and 1")
+	}
+
+	switch c.SectionMode {
+	case generator.SectionsByPath, generator.SectionsByLLM:
+	default:
+		return fmt.Errorf("unknown section mode %q: must be %q or %q", c.SectionMode, generator.SectionsByPath, generator.SectionsByLLM)
+	}
+
+	if c.SectionDepth <= 0 {
+		return fmt.Errorf("section-depth must be greater than 0")
+	}
+
+	if c.LinkMirrors && c.MirrorDir == "" {
+		return fmt.Errorf("link-mirrors requires mirror-dir to write the Markdown mirrors")
+	}
+
 	if c.BatchSize <= 0 {
 		return fmt.Errorf("batch-size must be greater than 0")
 	}
@@ -106,9 +257,120 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max-workers must be greater than 0")
 	}
 
+	if c.PolitenessDelay < 0 {
+		return fmt.Errorf("politeness-delay must be greater than or equal to 0")
+	}
+
+	if c.OpenAIOption.BaseURL != "" && c.OpenAIOption.IsAzure() {
+		return fmt.Errorf("openai-base-url and azure-endpoint are mutually exclusive")
+	}
+
+	if c.OpenAIOption.MaxCompletionTokens < 0 {
+		return fmt.Errorf("openai-max-completion-tokens must be greater than or equal to 0")
+	}
+
+	switch c.OpenAIOption.Verbosity {
+	case "", "low", "medium", "high":
+	default:
+		return fmt.Errorf("unknown openai-verbosity %q: must be \"low\", \"medium\" or \"high\"", c.OpenAIOption.Verbosity)
+	}
+
 	if c.MaxContentLength < 0 {
 		return fmt.Errorf("max-content-length must be greater than or equal to 0")
 	}
 
+	switch c.Summarize {
+	case generator.SummarizeChunked, generator.SummarizeTruncate:
+	default:
+		return fmt.Errorf("unknown summarize strategy %q: must be %q or %q", c.Summarize, generator.SummarizeChunked, generator.SummarizeTruncate)
+	}
+
+	if c.MaxChunks <= 0 {
+		return fmt.Errorf("max-chunks must be greater than 0")
+	}
+
+	switch c.AnthropicOption.CacheTTL {
+	case gollm.AnthropicCacheTTL5m, gollm.AnthropicCacheTTL1h, gollm.AnthropicCacheTTLOff:
+	default:
+		return fmt.Errorf("unknown anthropic-cache-ttl %q: must be %q, %q or %q", c.AnthropicOption.CacheTTL, gollm.AnthropicCacheTTL5m, gollm.AnthropicCacheTTL1h, gollm.AnthropicCacheTTLOff)
+	}
+
+	if c.MaxCost < 0 || c.MaxTokens < 0 || c.MaxCredits < 0 {
+		return fmt.Errorf("max-cost, max-tokens and max-credits must be greater than or equal to 0")
+	}
+
+	if c.MaxCost > 0 {
+		if _, ok := gollm.LookupPrice(c.Model, c.LLMOptions()); !ok {
+			return fmt.Errorf("max-cost requires a model with a known price, but the price of %s is unknown: use max-tokens instead", c.Model)
+		}
+	}
+
+	if c.MaxCredits > 0 && c.Backend != BackendFirecrawl {
+		return fmt.Errorf("max-credits only applies to the %s backend", BackendFirecrawl)
+	}
+
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("cache-ttl must be greater than or equal to 0")
+	}
+
+	if c.RetryOptions.MaxAttempts <= 0 {
+		return fmt.Errorf("llm-max-attempts must be greater than 0")
+	}
+
+	if c.MaxReasks < 0 {
+		return fmt.Errorf("llm-max-reasks must be greater than or equal to 0")
+	}
+
+	if _, _, err := gollm.Resolve(c.Model, c.LLMOptions()); err != nil {
+		return err
+	}
+
+	if c.LLMBatch {
+		if !gollm.SupportsBatch(c.Model, c.LLMOptions()) {
+			return fmt.Errorf("llm-batch requires a provider with a batch API, such as %s or %s, but %s has none", gollm.ProviderOpenAI, gollm.ProviderAnthropic, c.Model)
+		}
+		if c.BatchOptions.PollInterval <= 0 {
+			return fmt.Errorf("llm-batch-poll-interval must be greater than 0")
+		}
+	}
+
 	return nil
 }
+
+// IsHostedFirecrawl reports whether the [Config.FirecrawlAPIURL] points at the hosted Firecrawl API.
+func (c *Config) IsHostedFirecrawl() bool {
+	apiURL := strings.TrimRight(c.FirecrawlAPIURL, "/")
+	return apiURL == "" || apiURL == generator.DefaultFirecrawlAPIURL
+}
+
+// Budget returns the [generator.Budget] of the run, or nil if it is unlimited.
+func (c *Config) Budget() *generator.Budget {
+	if c.MaxCost <= 0 && c.MaxTokens <= 0 && c.MaxCredits <= 0 {
+		return nil
+	}
+
+	price, _ := gollm.LookupPrice(c.Model, c.LLMOptions())
+	return &generator.Budget{
+		MaxCost:     c.MaxCost,
+		MaxTokens:   c.MaxTokens,
+		MaxCredits:  c.MaxCredits,
+		Price:       price,
+		MaxAttempts: c.RetryOptions.MaxAttempts,
+		MaxReasks:   c.MaxReasks,
+	}
+}
+
+// LLMOptions returns the [gollm.Options] for creating the summarizer client of [Config.Model].
+func (c *Config) LLMOptions() *gollm.Options {
+	return &gollm.Options{
+		APIKey:           c.APIKey,
+		MaxContentLength: c.MaxContentLength,
+		Retry:            c.RetryOptions,
+		MaxReasks:        c.MaxReasks,
+		Batch:            c.BatchOptions,
+		OpenAI:           c.OpenAIOption,
+		Anthropic:        c.AnthropicOption,
+		Ollama:           c.OllamaOption,
+		LlamaCpp:         c.LlamaCppOption,
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+// summarizeBatch summarizes the pending pages of results in a single batch of [GenerationOptions.Batch], updating their
+// title and description in place, and checkpoints them to the journal. It returns the failures of the pages the batch
+// failed to summarize, which keep the title and description of their metadata, and the usage of the batch.
+//
+// If the batch itself fails, every pending page fails with its error. The error is only returned when ctx is done;
+// the pending pages are then left out of the journal, to be summarized again when the run is resumed.
+func (g *LLMsTxtGenerator) summarizeBatch(ctx context.Context, results []ProcessedURL, logger *slog.Logger) ([]Failure, gollm.Usage, error) {
+	var pending []int
+	for i := range results {
+		if results[i].pending {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, gollm.Usage{}, nil
+	}
+
+	reqs := make([]gollm.BatchRequest, len(pending))
+	for j, i := range pending {
+		reqs[j] = gollm.BatchRequest{Prompt: g.pagePrompt(results[i].URL), Content: results[i].Markdown}
+	}
+
+	logger.InfoContext(ctx, "Summarizing pages in a batch, which may take up to 24 hours", "pages", len(pending))
+	stats := gollm.NewStats(gollm.StatsFromContext(ctx))
+	batchResults, err := g.batch.SummarizeBatch(gollm.WithStats(ctx, stats), reqs)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, gollm.Usage{}, ctxErr
+		}
+		logger.WarnContext(ctx, "Failed to summarize pages in a batch", "pages", len(pending), "error", err)
+		batchResults = make([]gollm.BatchResult, len(pending))
+		for j := range batchResults {
+			batchResults[j].Err = err
+		}
+	}
+
+	var failures []Failure
+	for j, i := range pending {
+		result, res := &results[i], batchResults[j]
+		result.pending = false
+		g.budget.settle(g.estimateBatch(ctx, reqs[j].Prompt, reqs[j].Content), g.budget.batchSpending(res.Usage))
+
+		var failure *Failure
+		if res.Err != nil {
+			failure = &Failure{URL: result.URL, Stage: StageSummarize, Error: res.Err.Error(), Attempts: max(res.Usage.Requests, 1)}
+			logger.WarnContext(ctx, "Failed to process URL", "url", result.URL, "stage", failure.Stage, "error", failure.Error)
+			failures = append(failures, *failure)
+		} else {
+			result.Title, result.Description = res.Title, res.Description
+		}
+
+		if err := g.options.Journal.record(result, failure); err != nil {
+			logger.WarnContext(ctx, "Failed to checkpoint URL", "url", result.URL, "error", err)
+		}
+	}
+
+	return failures, stats.Usage(), nil
+}
+
+// estimateBatch returns the spending reserved for summarizing content with prompt in a batch: the estimated spending of
+// a request, for each of its re-asks.
+func (g *LLMsTxtGenerator) estimateBatch(ctx context.Context, prompt gollm.Prompt, content string) Spending {
+	if g.budget == nil {
+		return Spending{}
+	}
+
+	estimate := gollm.NewStats(nil)
+	g.batchEstimator.SummarizeContent(gollm.WithStats(ctx, estimate), prompt, content)
+	return g.budget.batchSpending(estimate.Usage()).times(g.budget.maxBatchRequests())
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+// ErrBudgetExhausted is returned for the work refused by the [Budget] of a run.
+var ErrBudgetExhausted = errors.New("budget exhausted")
+
+// Budget is the hard spending limit of a run. Zero fields are unlimited. See [GenerationOptions.Budget].
+//
+// Each scrape and each LLM request reserves its estimated spending before it is sent, and is refused if the spending
+// and the reservations so far would exceed the budget. An LLM request reserves the worst case of its retries and
+// re-asks, and releases the unused part once it completes. Once any work is refused, no new URL is scheduled; the URLs
+// in flight finish, and the run completes with the pages processed so far.
+type Budget struct {
+	// MaxCost is the maximum cost of the LLM requests in US dollars, at Price.
+	MaxCost float64
+	// MaxTokens is the maximum number of input and output LLM tokens.
+	MaxTokens int
+	// MaxCredits is the maximum number of Firecrawl credits, counted as one per map and scrape request.
+	MaxCredits int
+	// Price is the price of the model, used to compute the cost of the LLM requests.
+	Price gollm.Price
+	// MaxAttempts is the maximum number of attempts of an LLM request, see [gollm.RetryOptions]. Zero means 1.
+	MaxAttempts int
+	// MaxReasks is the maximum number of re-asks of an LLM response, see [gollm.Options.MaxReasks].
+	MaxReasks int
+}
+
+// Spending is an amount spent from a [Budget].
+type Spending struct {
+	Tokens  int     `json:"tokens"`
+	Cost    float64 `json:"cost"`
+	Credits int     `json:"credits"`
+}
+
+func (s *Spending) add(s2 Spending) {
+	s.Tokens += s2.Tokens
+	s.Cost += s2.Cost
+	s.Credits += s2.Credits
+}
+
+func (s *Spending) sub(s2 Spending) {
+	s.Tokens -= s2.Tokens
+	s.Cost -= s2.Cost
+	s.Credits -= s2.Credits
+}
+
+// times returns s spent n times.
+func (s Spending) times(n int) Spending {
+	return Spending{
+		Tokens:  s.Tokens * n,
+		Cost:    s.Cost * float64(n),
+		Credits: s.Credits * n,
+	}
+}
+
+// accountant enforces a [Budget] across the concurrent work of a run.
+//
+// A nil *accountant allows everything.
+type accountant struct {
+	budget Budget
+
+	mu        sync.Mutex
+	spent     Spending
+	reserved  Spending
+	exhausted bool
+}
+
+// newAccountant returns the accountant of budget, or nil if budget is nil or unlimited.
+func newAccountant(budget *Budget) *accountant {
+	if budget == nil || (budget.MaxCost <= 0 && budget.MaxTokens <= 0 && budget.MaxCredits <= 0) {
+		return nil
+	}
+	return &accountant{budget: *budget}
+}
+
+// reserve reserves s if it fits in the budget along with the spending and the reservations so far. Otherwise the
+// budget is marked as exhausted and false is returned.
+func (a *accountant) reserve(s Spending) bool {
+	if a == nil {
+		return true
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	total := a.spent
+	total.add(a.reserved)
+	total.add(s)
+	if (a.budget.MaxCost > 0 && total.Cost > a.budget.MaxCost) ||
+		(a.budget.MaxTokens > 0 && total.Tokens > a.budget.MaxTokens) ||
+		(a.budget.MaxCredits > 0 && total.Credits > a.budget.MaxCredits) {
+		a.exhausted = true
+		return false
+	}
+	a.reserved.add(s)
+
+	return true
+}
+
+// settle releases the reservation r and records the actual spending s.
+func (a *accountant) settle(r, s Spending) {
+	if a == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.reserved.sub(r)
+	a.spent.add(s)
+}
+
+// maxRequests returns the maximum number of requests sent by the summarizer for a single summary, each re-ask being
+// retried like the first request.
+func (a *accountant) maxRequests() int {
+	return max(a.budget.MaxAttempts, 1) * a.maxBatchRequests()
+}
+
+// maxBatchRequests returns the maximum number of batch requests sent for a single summary. The batches are not retried,
+// but re-asked like the summarizer.
+func (a *accountant) maxBatchRequests() int {
+	return max(a.budget.MaxReasks, 0) + 1
+}
+
+// isExhausted reports whether any work was refused.
+func (a *accountant) isExhausted() bool {
+	if a == nil {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.exhausted
+}
+
+// spending returns the spending recorded so far.
+func (a *accountant) spending() Spending {
+	if a == nil {
+		return Spending{}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.spent
+}
+
+// budgetClient reserves the estimated spending of each request from the accountant before sending it to client,
+// and records the actual tokens reported by the provider afterwards.
+//
+// The reservation covers every request client may send for the summary, see [accountant.maxRequests], so that the
+// retries and re-asks of the concurrent workers never overrun the budget.
+type budgetClient struct {
+	client    gollm.SummarizerClient
+	estimator gollm.SummarizerClient
+	acct      *accountant
+}
+
+var _ gollm.SummarizerClient = (*budgetClient)(nil)
+
+// newBudgetClient wraps client with acct, or returns client as is if acct is nil.
+func newBudgetClient(client gollm.SummarizerClient, acct *accountant, maxContentLength int) gollm.SummarizerClient {
+	if acct == nil {
+		return client
+	}
+	return &budgetClient{
+		client:    client,
+		estimator: gollm.NewEstimateClient(maxContentLength),
+		acct:      acct,
+	}
+}
+
+// SummarizeContent implements [gollm.SummarizerClient].
+func (c *budgetClient) SummarizeContent(ctx context.Context, prompt gollm.Prompt, content string) (title, description string, err error) {
+	estimate := gollm.NewStats(nil)
+	c.estimator.SummarizeContent(gollm.WithStats(ctx, estimate), prompt, content)
+	r := c.spending(estimate.Usage()).times(c.acct.maxRequests())
+	if !c.acct.reserve(r) {
+		return "", "", ErrBudgetExhausted
+	}
+
+	// record the tokens of this request, including its retries and re-asks, separately to settle the reservation
+	stats := gollm.NewStats(gollm.StatsFromContext(ctx))
+	title, description, err = c.client.SummarizeContent(gollm.WithStats(ctx, stats), prompt, content)
+	c.acct.settle(r, c.spending(stats.Usage()))
+
+	return title, description, err
+}
+
+func (c *budgetClient) spending(u gollm.Usage) Spending {
+	return spendingOf(u, c.acct.budget.Price)
+}
+
+// batchSpending returns the spending of the requests of u sent through a batch API.
+func (a *accountant) batchSpending(u gollm.Usage) Spending {
+	if a == nil {
+		return Spending{}
+	}
+	return spendingOf(u, a.budget.Price.Batch())
+}
+
+// spendingOf returns the spending of the tokens of u at price.
+func spendingOf(u gollm.Usage, price gollm.Price) Spending {
+	return Spending{
+		Tokens: u.InputTokens + u.CacheReadTokens + u.CacheWriteTokens + u.OutputTokens,
+		Cost:   price.Cost(u),
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+// countingSummarizer is a [gollm.SummarizerClient] counting its requests.
+type countingSummarizer struct {
+	calls int
+}
+
+func (c *countingSummarizer) SummarizeContent(context.Context, gollm.Prompt, string) (title, description string, err error) {
+	c.calls++
+	return "Page Title", "A description of the page content.", nil
+}
+
+func TestBudgetClientReservesWorstCase(t *testing.T) {
+	const maxContentLength = 4000
+	prompt := (&LLMsTxtGenerator{}).pagePrompt("https://example.com/")
+	content := "# Getting Started\n\nInstall the tool and run it."
+
+	estimate := gollm.NewStats(nil)
+	gollm.NewEstimateClient(maxContentLength).SummarizeContent(gollm.WithStats(context.Background(), estimate), prompt, content)
+	single := spendingOf(estimate.Usage(), gollm.Price{}).Tokens
+	if single == 0 {
+		t.Fatal("estimated no tokens")
+	}
+
+	tests := map[string]struct {
+		budget  Budget
+		wantErr error
+	}{
+		"single request": {
+			budget: Budget{MaxTokens: single},
+		},
+		"worst case fits": {
+			budget: Budget{MaxTokens: single * 4 * 3, MaxAttempts: 4, MaxReasks: 2},
+		},
+		"retries exceed": {
+			budget:  Budget{MaxTokens: single * 3, MaxAttempts: 4},
+			wantErr: ErrBudgetExhausted,
+		},
+		"re-asks exceed": {
+			budget:  Budget{MaxTokens: single * 2, MaxReasks: 2},
+			wantErr: ErrBudgetExhausted,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			acct := newAccountant(&tt.budget)
+			inner := &countingSummarizer{}
+			client := newBudgetClient(inner, acct, maxContentLength)
+
+			_, _, err := client.SummarizeContent(context.Background(), prompt, content)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SummarizeContent() error = %v, want %v", err, tt.wantErr)
+			}
+			if want := map[bool]int{true: 0, false: 1}[tt.wantErr != nil]; inner.calls != want {
+				t.Errorf("got %d requests, want %d", inner.calls, want)
+			}
+			if acct.reserved != (Spending{}) {
+				t.Errorf("reserved = %+v after the request, want none", acct.reserved)
+			}
+			if got := acct.isExhausted(); got != (tt.wantErr != nil) {
+				t.Errorf("isExhausted() = %t, want %t", got, tt.wantErr != nil)
+			}
+		})
+	}
+}
+
+func TestAccountantSettleReleasesReservation(t *testing.T) {
+	acct := newAccountant(&Budget{MaxTokens: 100, MaxCredits: 2})
+
+	worst := Spending{Tokens: 30}.times(3)
+	if !acct.reserve(worst) {
+		t.Fatal("reserve() = false, want true")
+	}
+	if acct.reserve(Spending{Tokens: 11}) {
+		t.Fatal("reserve() beyond the reservation = true, want false")
+	}
+
+	acct.settle(worst, Spending{Tokens: 30})
+	if got, want := acct.spending(), (Spending{Tokens: 30}); got != want {
+		t.Errorf("spending() = %+v, want %+v", got, want)
+	}
+	if !acct.reserve(Spending{Tokens: 70, Credits: 2}) {
+		t.Error("reserve() of the released tokens = false, want true")
+	}
+}
+
+func TestCreditsOf(t *testing.T) {
+	firecrawl, err := NewFirecrawlClient("fc-test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := NewLocalClient(t.TempDir(), "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	crawler := NewCrawlerClient(CrawlerOptions{}, nil)
+
+	tests := map[string]struct {
+		client FirecrawlClient
+		want   int
+	}{
+		"firecrawl":         {client: firecrawl, want: 1},
+		"crawler":           {client: crawler, want: 0},
+		"local":             {client: local, want: 0},
+		"firecrawl sitemap": {client: NewSitemapClient(firecrawl, "test"), want: 1},
+		"crawler sitemap":   {client: NewSitemapClient(crawler, "test"), want: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := creditsOf(tt.client); got != tt.want {
+				t.Errorf("creditsOf() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrapeURLSpendsNoCreditsWithoutFirecrawl(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "index.md"), []byte("# Home\n\nWelcome.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewLocalClient(root, "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewLLMsTxtGenerator(client, &countingSummarizer{}, GenerationOptions{
+		MaxURLs: 10,
+		Budget:  &Budget{MaxTokens: 1000},
+	})
+
+	urls, err := g.mapWebsite(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uri := range urls {
+		if _, err := g.scrapeURL(context.Background(), uri); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := g.budget.spending().Credits; got != 0 {
+		t.Errorf("spent %d credits, want 0", got)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// buildLLMsCtx renders the context file of llms.txt, in which the content of each linked page is inlined, in the
+// format of llms_txt2ctx of https://github.com/AnswerDotAI/llms-txt:
+//
+//	<project title="Example" summary="A short summary">
+//	<guides>
+//	<doc title="Getting Started" url="https://example.com/guides/start" desc="How to get started">
+//	...page content...
+//	</doc>
+//	</guides>
+//	</project>
+//
+// Each section is an element named after the section. The [SectionOptional] section is only included if optional is
+// true, which is the difference between llms-ctx-full.txt and llms-ctx.txt.
+func buildLLMsCtx(site Site, sections []Section, optional bool) string {
+	var sb strings.Builder
+
+	sb.WriteString(`<project title="`)
+	sb.WriteString(html.EscapeString(singleLine(site.Name)))
+	sb.WriteString(`"`)
+	if site.Summary != "" {
+		sb.WriteString(` summary="`)
+		sb.WriteString(html.EscapeString(singleLine(site.Summary)))
+		sb.WriteString(`"`)
+	}
+	sb.WriteString(">\n")
+
+	for _, section := range sections {
+		if section.Name == SectionOptional && !optional {
+			continue
+		}
+
+		tag := ctxTagName(section.Name)
+		sb.WriteString("<" + tag + ">\n")
+		for _, page := range section.Pages {
+			sb.WriteString(`<doc title="`)
+			sb.WriteString(html.EscapeString(singleLine(page.Title)))
+			sb.WriteString(`" url="`)
+			sb.WriteString(html.EscapeString(page.URL))
+			sb.WriteString(`"`)
+			if page.Description != "" {
+				sb.WriteString(` desc="`)
+				sb.WriteString(html.EscapeString(singleLine(page.Description)))
+				sb.WriteString(`"`)
+			}
+			sb.WriteString(">\n")
+			if content := strings.TrimSpace(page.Markdown); content != "" {
+				sb.WriteString(content)
+				sb.WriteString("\n")
+			}
+			sb.WriteString("</doc>\n")
+		}
+		sb.WriteString("</" + tag + ">\n")
+	}
+
+	sb.WriteString("</project>\n")
+
+	return sb.String()
+}
+
+// ctxTagName converts a section name such as "Tutorials / Python" to an element name such as "tutorials-python".
+func ctxTagName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+		case sb.Len() > 0 && !strings.HasSuffix(sb.String(), "-"):
+			sb.WriteByte('-')
+		}
+	}
+
+	tag := strings.TrimSuffix(sb.String(), "-")
+	if r, _ := utf8.DecodeRuneInString(tag); !unicode.IsLetter(r) {
+		// element names must start with a letter
+		tag = "section-" + tag
+	}
+
+	return strings.TrimSuffix(tag, "-")
+}
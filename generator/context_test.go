@@ -0,0 +1,91 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import "testing"
+
+func TestBuildLLMsCtx(t *testing.T) {
+	site := Site{Name: "Example", Summary: `Tools for "examples" & more`}
+	sections := []Section{
+		{Name: "Tutorials / Python", Pages: []ProcessedURL{
+			{
+				URL:         "https://example.com/tutorials/python/1?lang=en&v=2",
+				Title:       "Step <1>",
+				Description: "The first\nstep.",
+				Markdown:    "\n# Step 1\n\nInstall Python.\n\n",
+			},
+			{URL: "https://example.com/tutorials/python/2", Title: "Step 2"},
+		}},
+		{Name: SectionOptional, Pages: []ProcessedURL{
+			{URL: "https://example.com/changelog", Title: "Changelog", Description: "The changes.", Markdown: "# Changelog"},
+		}},
+	}
+	const ctx = `<project title="Example" summary="Tools for &#34;examples&#34; &amp; more">
+<tutorials-python>
+<doc title="Step &lt;1&gt;" url="https://example.com/tutorials/python/1?lang=en&amp;v=2" desc="The first step.">
+# Step 1
+
+Install Python.
+</doc>
+<doc title="Step 2" url="https://example.com/tutorials/python/2">
+</doc>
+</tutorials-python>
+`
+
+	tests := map[string]struct {
+		optional bool
+		want     string
+	}{
+		"llms-ctx.txt": {
+			optional: false,
+			want:     ctx + "</project>\n",
+		},
+		"llms-ctx-full.txt": {
+			optional: true,
+			want: ctx + `<optional>
+<doc title="Changelog" url="https://example.com/changelog" desc="The changes.">
+# Changelog
+</doc>
+</optional>
+</project>
+`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := buildLLMsCtx(site, sections, tt.optional); got != tt.want {
+				t.Errorf("buildLLMsCtx(%t) =\n%s\nwant:\n%s", tt.optional, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCtxTagName(t *testing.T) {
+	tests := map[string]string{
+		"Pages":              "pages",
+		"Tutorials / Python": "tutorials-python",
+		"API Reference (v2)": "api-reference-v2",
+		"2024 Releases":      "section-2024-releases",
+		"Guías":              "guías",
+		"--":                 "section",
+	}
+	for name, want := range tests {
+		if got := ctxTagName(name); got != want {
+			t.Errorf("ctxTagName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
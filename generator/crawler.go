@@ -0,0 +1,234 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent sent by the native crawler when none is configured.
+const DefaultUserAgent = "llmstxt-generator/1.0 (+https://github.com/zchee/llmstxt-generator)"
+
+// skippedExtensions are the path extensions of resources that never contain crawlable documents.
+var skippedExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".ico": true, ".avif": true,
+	".css": true, ".js": true, ".mjs": true, ".map": true, ".json": true, ".xml": true, ".rss": true, ".atom": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true, ".eot": true,
+	".zip": true, ".gz": true, ".tgz": true, ".tar": true, ".bz2": true, ".xz": true, ".7z": true, ".rar": true,
+	".mp3": true, ".mp4": true, ".webm": true, ".ogg": true, ".wav": true, ".mov": true, ".avi": true,
+	".pdf": true, ".exe": true, ".dmg": true, ".pkg": true, ".deb": true, ".rpm": true,
+}
+
+type crawlerClient struct {
+	fetcher *fetcher
+	policy  *CrawlPolicy
+	options CrawlerOptions
+	logger  *slog.Logger
+}
+
+var _ FirecrawlClient = (*crawlerClient)(nil)
+
+// NewCrawlerClient creates a new [FirecrawlClient] which maps and scrapes websites natively over HTTP without Firecrawl.
+//
+// While mapping, pages are only fetched when allowed by policy, which may be nil.
+func NewCrawlerClient(options CrawlerOptions, policy *CrawlPolicy) FirecrawlClient {
+	if options.UserAgent == "" {
+		options.UserAgent = DefaultUserAgent
+	}
+
+	return &crawlerClient{
+		fetcher: newFetcher(options.UserAgent),
+		policy:  policy,
+		options: options,
+		logger:  slog.Default().WithGroup("crawler"),
+	}
+}
+
+// MapWebsite discovers the pages of the website by a breadth-first crawl from rawURL,
+// following same-origin links up to [CrawlerOptions.MaxDepth] and stopping after limit pages.
+//
+// Links disallowed by the crawl policy are not fetched, but are appended to the result without counting
+// against limit so that the generator can report them as excluded.
+//
+// MapWebsite implements [FirecrawlClient].
+func (c *crawlerClient) MapWebsite(ctx context.Context, rawURL string, limit int, options FirecrawlOptions) ([]string, error) {
+	c.logger.InfoContext(ctx, "Mapping website", "url", rawURL, "limit", limit, "max_depth", c.options.MaxDepth)
+
+	start, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse URL: %w", err)
+	}
+	start.Fragment = ""
+
+	type queued struct {
+		url   string
+		depth int
+	}
+	queue := []queued{{url: start.String()}}
+	seen := map[string]bool{start.String(): true}
+
+	var urls, disallowed []string
+	for len(queue) > 0 && (limit <= 0 || len(urls) < limit) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		item := queue[0]
+		queue = queue[1:]
+
+		if ok, reason := c.policy.Allowed(ctx, item.url); !ok {
+			c.logger.DebugContext(ctx, "Skipping page excluded by crawl policy", "url", item.url, "reason", reason)
+			disallowed = append(disallowed, item.url)
+			continue
+		}
+		if err := c.policy.Wait(ctx, item.url); err != nil {
+			return nil, err
+		}
+
+		page, err := c.fetch(ctx, item.url, options)
+		if err != nil {
+			c.logger.DebugContext(ctx, "Skipping unreachable page", "url", item.url, "error", err)
+			continue
+		}
+		if !sameSite(start, page.url, options.IncludeSubdomains) {
+			c.logger.DebugContext(ctx, "Skipping page redirected off-site", "url", item.url, "location", page.url.String())
+			continue
+		}
+		urls = append(urls, item.url)
+
+		if !page.isHTML() || (c.options.MaxDepth > 0 && item.depth >= c.options.MaxDepth) {
+			continue
+		}
+
+		doc, err := parseHTMLDocument(page.body, page.url)
+		if err != nil {
+			c.logger.DebugContext(ctx, "Failed to parse page", "url", item.url, "error", err)
+			continue
+		}
+		for _, link := range doc.Links() {
+			u, err := url.Parse(link)
+			if err != nil || !sameSite(start, u, options.IncludeSubdomains) || skippedExtensions[strings.ToLower(path.Ext(u.Path))] {
+				continue
+			}
+			// the http:// and https:// links of the site are the same page, crawled with the scheme of the start URL
+			u.Scheme = start.Scheme
+			link = u.String()
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+			queue = append(queue, queued{url: link, depth: item.depth + 1})
+		}
+	}
+
+	if len(urls) == 0 && len(disallowed) == 0 {
+		return nil, fmt.Errorf("no pages reachable from %s", rawURL)
+	}
+
+	c.logger.InfoContext(ctx, "Found URLs", "count", len(urls), "disallowed", len(disallowed))
+	return append(urls, disallowed...), nil
+}
+
+// ScrapeURL fetches the page and converts it to Markdown.
+//
+// ScrapeURL implements [FirecrawlClient].
+func (c *crawlerClient) ScrapeURL(ctx context.Context, rawURL string, options FirecrawlOptions) (*ScrapedData, error) {
+	c.logger.DebugContext(ctx, "Scraping URL", "url", rawURL)
+
+	page, err := c.fetch(ctx, rawURL, options)
+	if errors.Is(err, ErrNotModified) {
+		return nil, err
+	}
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Failed to scrape URL", "url", rawURL, "error", err)
+		return nil, fmt.Errorf("scrape %s: %w", rawURL, err)
+	}
+
+	metadata := make(map[string]string)
+	if etag := page.header.Get("ETag"); etag != "" {
+		metadata[metadataETag] = etag
+	}
+	if lastModified := page.header.Get("Last-Modified"); lastModified != "" {
+		metadata[metadataHTTPLastModified] = lastModified
+	}
+	var markdown string
+	switch {
+	case page.isHTML():
+		doc, err := parseHTMLDocument(page.body, page.url)
+		if err != nil {
+			return nil, fmt.Errorf("scrape %s: %w", rawURL, err)
+		}
+		markdown = doc.Markdown(options.OnlyMainContent)
+		if title := doc.Title(); title != "" {
+			metadata["title"] = title
+		}
+		if description := doc.Description(); description != "" {
+			metadata["description"] = description
+		}
+
+	case page.isText():
+		markdown = strings.TrimSpace(page.body)
+
+	default:
+		return nil, fmt.Errorf("unsupported content type %q for %s", page.contentType, rawURL)
+	}
+
+	if markdown == "" {
+		c.logger.ErrorContext(ctx, "No markdown content returned from scrape", "url", rawURL)
+		return nil, fmt.Errorf("no markdown content returned for %s", rawURL)
+	}
+
+	return &ScrapedData{
+		URL:      rawURL,
+		Markdown: markdown,
+		Metadata: metadata,
+	}, nil
+}
+
+func (c *crawlerClient) fetch(ctx context.Context, rawURL string, options FirecrawlOptions) (*fetchedPage, error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(options.Timeout)*time.Millisecond)
+		defer cancel()
+	}
+
+	return c.fetcher.fetch(ctx, rawURL, "text/html,application/xhtml+xml,text/markdown;q=0.9,text/plain;q=0.8")
+}
+
+// sameSite reports whether u belongs to the same website as start.
+//
+// The "www." prefix is ignored, and subdomains of start are accepted only when includeSubdomains is true.
+func sameSite(start, u *url.URL, includeSubdomains bool) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	base := strings.TrimPrefix(start.Hostname(), "www.")
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	if host == base {
+		return true
+	}
+
+	return includeSubdomains && strings.HasSuffix(host, "."+base)
+}
@@ -0,0 +1,380 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testSite is an httptest stand-in of a website, serving pages by path and recording the paths requested.
+type testSite struct {
+	*httptest.Server
+
+	mu   sync.Mutex
+	hits []string
+}
+
+// testPage is a response of a [testSite].
+type testPage struct {
+	status      int
+	contentType string
+	header      map[string]string
+	body        string
+	location    string
+	// gzip compresses body, like a sitemap.xml.gz
+	gzip bool
+}
+
+// newTestSite starts a [testSite] serving pages, which may be filled after it starts.
+func newTestSite(t *testing.T, pages map[string]testPage) *testSite {
+	t.Helper()
+
+	s := &testSite{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits = append(s.hits, r.URL.Path)
+		s.mu.Unlock()
+
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		for k, v := range page.header {
+			w.Header().Set(k, v)
+		}
+		if page.location != "" {
+			_, port, _ := net.SplitHostPort(r.Host)
+			http.Redirect(w, r, strings.ReplaceAll(page.location, "$LOCALHOST", "http://localhost:"+port), http.StatusFound)
+			return
+		}
+		if page.contentType != "" {
+			w.Header().Set("Content-Type", page.contentType)
+		}
+		if page.status != 0 {
+			w.WriteHeader(page.status)
+		}
+		if page.gzip {
+			zw := gzip.NewWriter(w)
+			zw.Write([]byte(page.body))
+			zw.Close()
+			return
+		}
+		w.Write([]byte(page.body))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// hit reports whether path was requested.
+func (s *testSite) hit(path string) bool {
+	return s.count(path) > 0
+}
+
+// count returns the number of requests of path.
+func (s *testSite) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for _, hit := range s.hits {
+		if hit == path {
+			n++
+		}
+	}
+	return n
+}
+
+func htmlPage(body string) testPage {
+	return testPage{contentType: "text/html; charset=utf-8", body: body}
+}
+
+// crawlerTestPages is a website of a few linked pages. The page /away redirects to the same server by the name
+// "localhost", which is another site than the "127.0.0.1" of the start URL.
+var crawlerTestPages = map[string]testPage{
+	"/robots.txt": {contentType: "text/plain", body: "User-agent: *\nDisallow: /private\n"},
+	"/": htmlPage(`<html><body>
+<a href="/a">A</a> <a href="/b">B</a> <a href="/a#section">A again</a>
+<a href="/redirect">Redirect</a> <a href="/private/x">Private</a> <a href="/away">Away</a>
+<a href="/logo.png">Logo</a> <a href="https://other.example/">External</a> <a href="mailto:a@example.com">Mail</a>
+</body></html>`),
+	"/a":         htmlPage(`<a href="/a/deep">Deep</a>`),
+	"/a/deep":    htmlPage(`<a href="/a/deeper">Deeper</a>`),
+	"/a/deeper":  htmlPage(`<p>The end</p>`),
+	"/b":         {contentType: "text/plain", body: "plain text"},
+	"/redirect":  {location: "/c"},
+	"/c":         htmlPage(`<p>Redirected</p>`),
+	"/private/x": htmlPage(`<p>Private</p>`),
+	"/away":      {location: "$LOCALHOST/c"},
+	"/logo.png":  {contentType: "image/png", body: "\x89PNG"},
+}
+
+func TestCrawlerClientMapWebsite(t *testing.T) {
+	tests := map[string]struct {
+		maxDepth      int
+		limit         int
+		respectRobots bool
+		want          []string
+		wantNotHit    []string
+	}{
+		"unlimited": {
+			respectRobots: true,
+			want:          []string{"/", "/a", "/b", "/redirect", "/a/deep", "/a/deeper", "/private/x"},
+			wantNotHit:    []string{"/private/x", "/logo.png"},
+		},
+		"max depth 1": {
+			maxDepth:      1,
+			respectRobots: true,
+			want:          []string{"/", "/a", "/b", "/redirect", "/private/x"},
+			wantNotHit:    []string{"/a/deep", "/private/x"},
+		},
+		"max depth 2": {
+			maxDepth:      2,
+			respectRobots: true,
+			want:          []string{"/", "/a", "/b", "/redirect", "/a/deep", "/private/x"},
+			wantNotHit:    []string{"/a/deeper"},
+		},
+		"limit": {
+			limit:         2,
+			respectRobots: true,
+			want:          []string{"/", "/a"},
+			wantNotHit:    []string{"/b"},
+		},
+		"robots ignored": {
+			maxDepth: 1,
+			want:     []string{"/", "/a", "/b", "/redirect", "/private/x"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			site := newTestSite(t, crawlerTestPages)
+			policy := NewCrawlPolicy("", tt.respectRobots, 0)
+			c := NewCrawlerClient(CrawlerOptions{MaxDepth: tt.maxDepth}, policy)
+
+			got, err := c.MapWebsite(t.Context(), site.URL+"/", tt.limit, FirecrawlOptions{})
+			if err != nil {
+				t.Fatalf("MapWebsite() error = %v", err)
+			}
+
+			var want []string
+			for _, p := range tt.want {
+				want = append(want, site.URL+p)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("MapWebsite() = %q, want %q", got, want)
+			}
+			for _, p := range tt.wantNotHit {
+				if site.hit(p) {
+					t.Errorf("MapWebsite() fetched %s", p)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlerClientMapWebsiteNormalizesScheme(t *testing.T) {
+	pages := map[string]testPage{}
+	site := newTestSite(t, pages)
+	host := strings.TrimPrefix(site.URL, "http://")
+	pages["/"] = htmlPage(`<a href="http://` + host + `/a">A</a> <a href="https://` + host + `/a">A over TLS</a>
+<a href="https://` + host + `/b">B over TLS</a>`)
+	pages["/a"] = htmlPage(`<a href="/">Home</a>`)
+	pages["/b"] = htmlPage(`<p>B</p>`)
+
+	c := NewCrawlerClient(CrawlerOptions{}, nil)
+	got, err := c.MapWebsite(t.Context(), site.URL+"/", 0, FirecrawlOptions{})
+	if err != nil {
+		t.Fatalf("MapWebsite() error = %v", err)
+	}
+
+	want := []string{site.URL + "/", site.URL + "/a", site.URL + "/b"}
+	if !slices.Equal(got, want) {
+		t.Errorf("MapWebsite() = %q, want %q", got, want)
+	}
+	if n := site.count("/a"); n != 1 {
+		t.Errorf("MapWebsite() fetched /a %d times, want 1", n)
+	}
+}
+
+func TestCrawlerClientMapWebsiteUnreachable(t *testing.T) {
+	site := newTestSite(t, map[string]testPage{})
+
+	c := NewCrawlerClient(CrawlerOptions{}, nil)
+	if _, err := c.MapWebsite(t.Context(), site.URL+"/", 0, FirecrawlOptions{}); err == nil {
+		t.Error("MapWebsite() error = nil, want an error for a website without pages")
+	}
+}
+
+func TestCrawlerClientMapWebsiteCanceled(t *testing.T) {
+	site := newTestSite(t, crawlerTestPages)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	c := NewCrawlerClient(CrawlerOptions{}, nil)
+	if _, err := c.MapWebsite(ctx, site.URL+"/", 0, FirecrawlOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("MapWebsite() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestCrawlerClientScrapeURL(t *testing.T) {
+	tests := map[string]struct {
+		page            testPage
+		onlyMainContent bool
+		wantMarkdown    string
+		wantMetadata    map[string]string
+		wantErr         bool
+	}{
+		"html": {
+			page: testPage{
+				contentType: "text/html",
+				header:      map[string]string{"ETag": `"v1"`, "Last-Modified": "Mon, 02 Jan 2006 15:04:05 GMT"},
+				body: `<html><head><title> Getting   Started </title><meta name="description" content="How to start."></head>
+<body><nav><a href="/">Home</a></nav><main><h1>Install</h1><p>Run <code>go install</code> and see <a href="/docs">the docs</a>.</p></main></body></html>`,
+			},
+			onlyMainContent: true,
+			wantMarkdown:    "# Install\n\nRun `go install` and see [the docs]($SERVER/docs).",
+			wantMetadata: map[string]string{
+				"title":                  "Getting Started",
+				"description":            "How to start.",
+				metadataETag:             `"v1"`,
+				metadataHTTPLastModified: "Mon, 02 Jan 2006 15:04:05 GMT",
+			},
+		},
+		"html with chrome": {
+			page:         htmlPage(`<nav>Menu</nav><h2>Title</h2><ul><li>one</li><li>two</li></ul><pre><code class="language-go">fmt.Println()</code></pre>`),
+			wantMarkdown: "Menu\n\n## Title\n\n- one\n- two\n\n```go\nfmt.Println()\n```",
+			wantMetadata: map[string]string{},
+		},
+		"og description": {
+			page:         htmlPage(`<head><meta property="og:description" content="From Open Graph."></head><p>Body</p>`),
+			wantMarkdown: "Body",
+			wantMetadata: map[string]string{"description": "From Open Graph."},
+		},
+		"markdown": {
+			page:         testPage{contentType: "text/markdown", body: "\n# Title\n\nText\n"},
+			wantMarkdown: "# Title\n\nText",
+			wantMetadata: map[string]string{},
+		},
+		"redirect": {
+			page:         testPage{location: "/target"},
+			wantMarkdown: "Target",
+			wantMetadata: map[string]string{},
+		},
+		"unsupported content type": {
+			page:    testPage{contentType: "application/octet-stream", body: "\x00\x01"},
+			wantErr: true,
+		},
+		"empty page": {
+			page:    htmlPage(`<script>var x;</script>`),
+			wantErr: true,
+		},
+		"not found": {
+			page:    testPage{status: http.StatusNotFound, contentType: "text/html", body: "missing"},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			site := newTestSite(t, map[string]testPage{
+				"/page":   tt.page,
+				"/target": htmlPage(`<p>Target</p>`),
+			})
+
+			c := NewCrawlerClient(CrawlerOptions{}, nil)
+			got, err := c.ScrapeURL(t.Context(), site.URL+"/page", FirecrawlOptions{OnlyMainContent: tt.onlyMainContent})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ScrapeURL() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ScrapeURL() error = %v", err)
+			}
+
+			if want := strings.ReplaceAll(tt.wantMarkdown, "$SERVER", site.URL); got.Markdown != want {
+				t.Errorf("ScrapeURL().Markdown = %q, want %q", got.Markdown, want)
+			}
+			if len(got.Metadata) != len(tt.wantMetadata) {
+				t.Errorf("ScrapeURL().Metadata = %q, want %q", got.Metadata, tt.wantMetadata)
+			}
+			for k, v := range tt.wantMetadata {
+				if got.Metadata[k] != v {
+					t.Errorf("ScrapeURL().Metadata[%q] = %q, want %q", k, got.Metadata[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlerClientScrapeURLNotModified(t *testing.T) {
+	site := newTestSite(t, map[string]testPage{
+		"/page": {status: http.StatusNotModified},
+	})
+
+	ctx := withValidators(t.Context(), validators{etag: `"v1"`})
+	c := NewCrawlerClient(CrawlerOptions{}, nil)
+	if _, err := c.ScrapeURL(ctx, site.URL+"/page", FirecrawlOptions{}); !errors.Is(err, ErrNotModified) {
+		t.Errorf("ScrapeURL() error = %v, want %v", err, ErrNotModified)
+	}
+}
+
+func TestSameSite(t *testing.T) {
+	start := mustParseURL(t, "https://www.example.com/docs/")
+
+	tests := map[string]struct {
+		url               string
+		includeSubdomains bool
+		want              bool
+	}{
+		"same host":              {url: "https://www.example.com/a", want: true},
+		"without www":            {url: "http://example.com/a", want: true},
+		"subdomain":              {url: "https://docs.example.com/a", want: false},
+		"subdomain included":     {url: "https://docs.example.com/a", includeSubdomains: true, want: true},
+		"suffix of another name": {url: "https://notexample.com/a", includeSubdomains: true, want: false},
+		"other scheme":           {url: "ftp://example.com/a", want: false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := sameSite(start, mustParseURL(t, tt.url), tt.includeSubdomains); got != tt.want {
+				t.Errorf("sameSite(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
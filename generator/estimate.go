@@ -0,0 +1,222 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+// Stages of an [Estimate].
+const (
+	// EstimatePages is the summarization of each page, including the chunks of [SummarizeChunked].
+	EstimatePages = "pages"
+	// EstimateSite is the summarization of the site from the summaries of its pages.
+	EstimateSite = "site"
+	// EstimateSections is the proposal of the taxonomy and the classification of each page by [SectionsByLLM].
+	EstimateSections = "sections"
+)
+
+// defaultEstimatePageLength is the content length assumed for the pages which are not scraped when
+// [GenerationOptions.MaxContentLength] is unlimited.
+const defaultEstimatePageLength = 4000
+
+// Estimate is the forecast of the LLM usage of a run, made by [LLMsTxtGenerator.Estimate] without calling the
+// summarizer.
+type Estimate struct {
+	URL   string `json:"url"`
+	Model string `json:"model"`
+	// URLs is the number of URLs planned for the run, after the filters, the crawl policy and the maximum number of
+	// URLs are applied.
+	URLs int `json:"urls"`
+	// Excluded lists the mapped URLs which would be skipped by the crawl policy.
+	Excluded []ExcludedURL `json:"excluded,omitempty"`
+	// Filter reports the URLs removed by the include and exclude patterns, if any.
+	Filter *FilterReport `json:"filter,omitempty"`
+	// Scraped is the number of pages scraped to measure their content. Zero if the pages were not scraped.
+	Scraped int `json:"scraped"`
+	// Failures lists the URLs which failed to scrape. They are left out of the estimate.
+	Failures []Failure `json:"failures,omitempty"`
+	// AssumedPageLength is the content length assumed for each page if the pages were not scraped.
+	AssumedPageLength int `json:"assumed_page_length,omitempty"`
+	// Stages are the estimated usage of each stage of the run, in order.
+	Stages []StageEstimate `json:"stages"`
+}
+
+// StageEstimate is the estimated usage of a stage of a run.
+type StageEstimate struct {
+	// Stage is one of [EstimatePages], [EstimateSite] or [EstimateSections].
+	Stage string      `json:"stage"`
+	Usage gollm.Usage `json:"usage"`
+}
+
+// Total returns the estimated usage of all stages.
+func (e *Estimate) Total() gollm.Usage {
+	var total gollm.Usage
+	for _, stage := range e.Stages {
+		total.Add(stage.Usage)
+	}
+	return total
+}
+
+// Estimate plans a run of targetURL like [LLMsTxtGenerator.GenerateLLMsTXT], mapping the website and applying the
+// filters and the crawl policy, and estimates the tokens of each LLM request of the run without calling the
+// summarizer. See [gollm.NewEstimateClient].
+//
+// If scrape is true, the planned pages are scraped to measure their content, which also estimates the chunks of long
+// pages. Otherwise each page is assumed to be as long as [GenerationOptions.MaxContentLength].
+func (g *LLMsTxtGenerator) Estimate(ctx context.Context, targetURL string, scrape bool) (*Estimate, error) {
+	logger := slog.Default()
+	logger.InfoContext(ctx, "Estimating llms.txt", "url", targetURL, "scrape", scrape)
+
+	plan, err := g.planURLs(ctx, targetURL, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &Estimate{
+		URL:      targetURL,
+		Model:    g.options.Model,
+		URLs:     len(plan.URLs),
+		Excluded: plan.Excluded,
+		Filter:   plan.Filter,
+	}
+
+	var results []ProcessedURL
+	if scrape {
+		results, estimate.Failures, err = g.scrapeForEstimate(ctx, plan.URLs, logger)
+		if err != nil {
+			return nil, err
+		}
+		estimate.Scraped = len(results)
+	} else {
+		estimate.AssumedPageLength = cmp.Or(g.options.MaxContentLength, defaultEstimatePageLength)
+		placeholder := strings.Repeat("x", estimate.AssumedPageLength)
+		for i, uri := range plan.URLs {
+			results = append(results, ProcessedURL{URL: uri, Markdown: placeholder, Index: i})
+		}
+	}
+	for i := range results {
+		if entry, ok := g.sitemapEntry(results[i].URL); ok {
+			results[i].LastModified, results[i].Priority = entry.LastModified, entry.Priority
+		}
+	}
+
+	// a copy of g whose summarizers only record the estimated usage
+	est := *g
+	est.summarizer = gollm.NewEstimateClient(g.options.MaxContentLength)
+	est.pageSummarizer = est.summarizer
+	if g.options.Summarize != SummarizeTruncate {
+		est.pageSummarizer = gollm.NewChunkedClient(est.summarizer, g.options.MaxContentLength, g.options.MaxChunks)
+	}
+
+	pages := gollm.NewStats(nil)
+	for i := range results {
+		results[i].Title, results[i].Description, _ = est.pageSummarizer.SummarizeContent(gollm.WithStats(ctx, pages), g.pagePrompt(results[i].URL), results[i].Markdown)
+	}
+	estimate.Stages = append(estimate.Stages, StageEstimate{Stage: EstimatePages, Usage: pages.Usage()})
+
+	site := gollm.NewStats(nil)
+	est.summarizeSite(gollm.WithStats(ctx, site), targetURL, results, logger)
+	estimate.Stages = append(estimate.Stages, StageEstimate{Stage: EstimateSite, Usage: site.Usage()})
+
+	if g.options.SectionMode == SectionsByLLM {
+		optionalFilter, err := NewURLFilter(g.options.OptionalPatterns, nil)
+		if err != nil {
+			return nil, fmt.Errorf("compile optional patterns: %w", err)
+		}
+		sections := groupSections(results, cmp.Or(g.options.SectionDepth, defaultSectionDepth), g.isOptional(optionalFilter))
+
+		stats := gollm.NewStats(nil)
+		sectionsCtx := gollm.WithStats(ctx, stats)
+		taxonomy := g.options.Taxonomy
+		if len(taxonomy) == 0 {
+			// the placeholder response is not a taxonomy, so the request is only counted
+			est.proposeTaxonomy(sectionsCtx, sections)
+			for i := range maxTaxonomySize {
+				taxonomy = append(taxonomy, fmt.Sprintf("Category %d", i+1))
+			}
+		}
+		for _, result := range results {
+			if result.Section != SectionOptional {
+				est.classifyPage(sectionsCtx, result, taxonomy)
+			}
+		}
+		estimate.Stages = append(estimate.Stages, StageEstimate{Stage: EstimateSections, Usage: stats.Usage()})
+	}
+
+	return estimate, nil
+}
+
+// scrapeForEstimate scrapes urls concurrently with the crawl policy of a run, and returns the pages which were
+// scraped and the failures of the others. The error is only returned when ctx is done.
+func (g *LLMsTxtGenerator) scrapeForEstimate(ctx context.Context, urls []string, logger *slog.Logger) ([]ProcessedURL, []Failure, error) {
+	results := make([]*ProcessedURL, len(urls))
+	failures := make([]*Failure, len(urls))
+
+	var eg errgroup.Group
+	eg.SetLimit(g.options.MaxWorkers)
+	for i, uri := range urls {
+		eg.Go(func() error {
+			if err := g.options.CrawlPolicy.Wait(ctx, uri); err != nil {
+				return err
+			}
+
+			urlCtx, cancel := context.WithTimeout(ctx, g.options.Timeout)
+			defer cancel()
+
+			scrapedData, err := g.scrapeURL(urlCtx, uri)
+			if err == nil && (scrapedData == nil || scrapedData.Markdown == "") {
+				err = fmt.Errorf("no markdown content")
+			}
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+				logger.WarnContext(ctx, "Failed to scrape URL", "url", uri, "error", err)
+				failures[i] = &Failure{URL: uri, Stage: StageScrape, Error: err.Error(), Attempts: 1}
+				return nil
+			}
+
+			results[i] = &ProcessedURL{URL: uri, Markdown: scrapedData.Markdown, Index: i}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	var pages []ProcessedURL
+	var pageFailures []Failure
+	for i := range urls {
+		if results[i] != nil {
+			pages = append(pages, *results[i])
+		}
+		if failures[i] != nil {
+			pageFailures = append(pageFailures, *failures[i])
+		}
+	}
+
+	return pages, pageFailures, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxResponseSize is the maximum number of bytes read from a single HTTP response.
+//
+// It is the same as the maximum uncompressed size of a sitemap file.
+const maxResponseSize = 50 << 20
+
+// fetcher fetches resources directly from websites.
+type fetcher struct {
+	httpClient *http.Client
+	userAgent  string
+}
+
+func newFetcher(userAgent string) *fetcher {
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	return &fetcher{
+		httpClient: &http.Client{},
+		userAgent:  userAgent,
+	}
+}
+
+// fetchedPage is the response of a single fetcher request.
+type fetchedPage struct {
+	url         *url.URL
+	statusCode  int
+	contentType string
+	header      http.Header
+	body        string
+}
+
+func (p *fetchedPage) isHTML() bool {
+	return p.contentType == "text/html" || p.contentType == "application/xhtml+xml"
+}
+
+func (p *fetchedPage) isText() bool {
+	return p.contentType == "text/markdown" || p.contentType == "text/x-markdown" || p.contentType == "text/plain"
+}
+
+// fetch GETs rawURL and returns the response body, transparently decompressing gzip payloads such as sitemap.xml.gz.
+//
+// If ctx carries the validators of the previous run, the request is conditional and a 304 Not Modified response is
+// returned as [ErrNotModified]. Any other status than 200 OK is returned as an [*httpStatusError].
+func (f *fetcher) fetch(ctx context.Context, rawURL, accept string) (*fetchedPage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if v, ok := validatorsFromContext(ctx); ok {
+		if v.etag != "" {
+			req.Header.Set("If-None-Match", v.etag)
+		}
+		if v.lastModified != "" {
+			req.Header.Set("If-Modified-Since", v.lastModified)
+		}
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, fmt.Errorf("fetch %s: %w", rawURL, ErrNotModified)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &httpStatusError{
+			URL:        rawURL,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("read body of %s: %w", rawURL, err)
+	}
+
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("decompress %s: %w", rawURL, err)
+		}
+		body, err = io.ReadAll(io.LimitReader(zr, maxResponseSize))
+		if err != nil {
+			return nil, fmt.Errorf("decompress %s: %w", rawURL, err)
+		}
+	}
+
+	contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || contentType == "application/gzip" || contentType == "application/x-gzip" {
+		contentType, _, _ = strings.Cut(http.DetectContentType(body), ";")
+	}
+
+	return &fetchedPage{
+		url:         resp.Request.URL,
+		statusCode:  resp.StatusCode,
+		contentType: contentType,
+		header:      resp.Header,
+		body:        string(body),
+	}, nil
+}
+
+// httpStatusError is returned by [fetcher.fetch] when the server responds with an unexpected status.
+type httpStatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+// Error implements [error].
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("fetch %s: unexpected status %s", e.URL, e.Status)
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// regexPatternPrefix marks a filter pattern as a regular expression instead of a glob.
+const regexPatternPrefix = "re:"
+
+// Kinds of [FilterStat].
+const (
+	FilterInclude = "include"
+	FilterExclude = "exclude"
+)
+
+// URLFilter filters mapped URLs by include and exclude path patterns.
+//
+// Patterns are matched against the URL path. A glob must match the whole path, where "*" matches any sequence
+// of characters within a path segment, "**" matches across segments and "?" matches a single character.
+// A pattern prefixed with "re:" is a regular expression matched anywhere in the path.
+//
+// A URL is kept if it matches at least one include pattern (or there are none) and no exclude pattern.
+type URLFilter struct {
+	include []*urlRule
+	exclude []*urlRule
+}
+
+type urlRule struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// NewURLFilter compiles the include and exclude patterns into a [URLFilter].
+func NewURLFilter(include, exclude []string) (*URLFilter, error) {
+	f := &URLFilter{}
+
+	for _, pattern := range include {
+		rule, err := compileURLRule(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("include pattern %q: %w", pattern, err)
+		}
+		f.include = append(f.include, rule)
+	}
+	for _, pattern := range exclude {
+		rule, err := compileURLRule(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("exclude pattern %q: %w", pattern, err)
+		}
+		f.exclude = append(f.exclude, rule)
+	}
+
+	return f, nil
+}
+
+// Empty reports whether f has no patterns.
+func (f *URLFilter) Empty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+// Apply returns the URLs kept by f in their original order, along with a report of what each pattern filtered.
+func (f *URLFilter) Apply(urls []string) ([]string, *FilterReport) {
+	report := &FilterReport{}
+	includeStats := make([]FilterStat, len(f.include))
+	for i, rule := range f.include {
+		includeStats[i] = FilterStat{Kind: FilterInclude, Pattern: rule.pattern}
+	}
+	excludeStats := make([]FilterStat, len(f.exclude))
+	for i, rule := range f.exclude {
+		excludeStats[i] = FilterStat{Kind: FilterExclude, Pattern: rule.pattern}
+	}
+
+	kept := make([]string, 0, len(urls))
+	for _, rawURL := range urls {
+		p := rawURL
+		if u, err := url.Parse(rawURL); err == nil {
+			p = u.Path
+		}
+		if p == "" {
+			p = "/"
+		}
+
+		included := len(f.include) == 0
+		for i, rule := range f.include {
+			if rule.re.MatchString(p) {
+				includeStats[i].Matched++
+				included = true
+			}
+		}
+		if !included {
+			report.NotIncluded++
+			continue
+		}
+
+		excluded := false
+		for i, rule := range f.exclude {
+			if !rule.re.MatchString(p) {
+				continue
+			}
+			excludeStats[i].Matched++
+			if !excluded {
+				// the first matching exclude pattern is credited for filtering the URL
+				excludeStats[i].Filtered++
+				excluded = true
+			}
+		}
+		if excluded {
+			continue
+		}
+
+		kept = append(kept, rawURL)
+	}
+
+	report.Rules = append(includeStats, excludeStats...)
+	return kept, report
+}
+
+// FilterReport describes how many URLs were filtered by a [URLFilter].
+type FilterReport struct {
+	// NotIncluded is the number of URLs which matched none of the include patterns.
+	NotIncluded int `json:"not_included"`
+	// Rules contains the statistics of each pattern, include patterns first.
+	Rules []FilterStat `json:"rules"`
+}
+
+// FilterStat is the statistics of a single include or exclude pattern.
+type FilterStat struct {
+	Kind    string `json:"kind"`
+	Pattern string `json:"pattern"`
+	// Matched is the number of URLs matched by the pattern.
+	Matched int `json:"matched"`
+	// Filtered is the number of URLs removed because of the pattern. It is always zero for include patterns.
+	Filtered int `json:"filtered"`
+}
+
+func compileURLRule(pattern string) (*urlRule, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	expr, ok := strings.CutPrefix(pattern, regexPatternPrefix)
+	if !ok {
+		expr = globToRegexp(pattern)
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &urlRule{
+		pattern: pattern,
+		re:      re,
+	}, nil
+}
+
+// globToRegexp converts a path glob to an anchored regular expression.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+				continue
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+
+	return sb.String()
+}
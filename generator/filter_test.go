@@ -0,0 +1,158 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestURLFilter(t *testing.T) {
+	const base = "https://example.com"
+	tests := map[string]struct {
+		include, exclude []string
+		paths            []string
+		want             []string
+		wantReport       FilterReport
+	}{
+		"no patterns": {
+			paths:      []string{"/", "/docs/intro"},
+			want:       []string{"/", "/docs/intro"},
+			wantReport: FilterReport{},
+		},
+		"glob": {
+			include: []string{"/docs/*"},
+			paths:   []string{"/docs", "/docs/intro", "/docs/api/v1", "/blog/docs/intro"},
+			want:    []string{"/docs/intro"},
+			wantReport: FilterReport{
+				NotIncluded: 3,
+				Rules:       []FilterStat{{Kind: FilterInclude, Pattern: "/docs/*", Matched: 1}},
+			},
+		},
+		"double star glob": {
+			include: []string{"/docs/**"},
+			paths:   []string{"/docs", "/docs/intro", "/docs/api/v1"},
+			want:    []string{"/docs/intro", "/docs/api/v1"},
+			wantReport: FilterReport{
+				NotIncluded: 1,
+				Rules:       []FilterStat{{Kind: FilterInclude, Pattern: "/docs/**", Matched: 2}},
+			},
+		},
+		"question mark and metacharacters": {
+			include: []string{"/v?/api.html"},
+			paths:   []string{"/v1/api.html", "/v10/api.html", "/v2/apixhtml", "/v/api.html"},
+			want:    []string{"/v1/api.html"},
+			wantReport: FilterReport{
+				NotIncluded: 3,
+				Rules:       []FilterStat{{Kind: FilterInclude, Pattern: "/v?/api.html", Matched: 1}},
+			},
+		},
+		"regular expression matches anywhere": {
+			exclude: []string{`re:/v\d+/`},
+			paths:   []string{"/api/v1/users", "/api/latest/users", "/v2/"},
+			want:    []string{"/api/latest/users"},
+			wantReport: FilterReport{
+				Rules: []FilterStat{{Kind: FilterExclude, Pattern: `re:/v\d+/`, Matched: 2, Filtered: 2}},
+			},
+		},
+		"include then exclude": {
+			include: []string{"/docs/**", "re:^/blog/"},
+			exclude: []string{"**/internal/**", "re:draft", "/docs/internal/*"},
+			paths: []string{
+				"/docs/intro", "/docs/internal/design", "/blog/draft-post", "/blog/hello",
+				"/about", "/internal/secret", "", "/docs/drafts/internal/x",
+			},
+			want: []string{"/docs/intro", "/blog/hello"},
+			wantReport: FilterReport{
+				NotIncluded: 3,
+				Rules: []FilterStat{
+					{Kind: FilterInclude, Pattern: "/docs/**", Matched: 3},
+					{Kind: FilterInclude, Pattern: "re:^/blog/", Matched: 2},
+					// the first matching exclude pattern is credited
+					{Kind: FilterExclude, Pattern: "**/internal/**", Matched: 2, Filtered: 2},
+					{Kind: FilterExclude, Pattern: "re:draft", Matched: 2, Filtered: 1},
+					{Kind: FilterExclude, Pattern: "/docs/internal/*", Matched: 1, Filtered: 0},
+				},
+			},
+		},
+		"root path": {
+			include: []string{"/"},
+			paths:   []string{"", "/", "/docs"},
+			want:    []string{"", "/"},
+			wantReport: FilterReport{
+				NotIncluded: 1,
+				Rules:       []FilterStat{{Kind: FilterInclude, Pattern: "/", Matched: 2}},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewURLFilter(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("NewURLFilter() error = %v", err)
+			}
+			if got, want := f.Empty(), len(tt.include)+len(tt.exclude) == 0; got != want {
+				t.Errorf("Empty() = %t, want %t", got, want)
+			}
+
+			var urls, want []string
+			for _, p := range tt.paths {
+				urls = append(urls, base+p)
+			}
+			for _, p := range tt.want {
+				want = append(want, base+p)
+			}
+
+			got, report := f.Apply(urls)
+			if !slices.Equal(got, want) {
+				t.Errorf("Apply() = %q, want %q", got, want)
+			}
+			if report.NotIncluded != tt.wantReport.NotIncluded || !slices.Equal(report.Rules, tt.wantReport.Rules) {
+				t.Errorf("Apply() report = %+v, want %+v", *report, tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestNewURLFilterErrors(t *testing.T) {
+	tests := map[string]struct {
+		include, exclude []string
+		wantErr          string
+	}{
+		"invalid include regexp": {
+			include: []string{"re:("},
+			wantErr: `include pattern "re:(": error parsing regexp`,
+		},
+		"invalid exclude regexp": {
+			exclude: []string{"/docs/**", "re:[a-"},
+			wantErr: `exclude pattern "re:[a-": error parsing regexp`,
+		},
+		"empty pattern": {
+			include: []string{""},
+			wantErr: `include pattern "": empty pattern`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewURLFilter(tt.include, tt.exclude)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("NewURLFilter() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
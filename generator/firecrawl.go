@@ -20,26 +20,60 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"strings"
+	"time"
 
 	firecrawl "github.com/mendableai/firecrawl-go/v2"
 )
 
-const firecrawlAPIURL = "https://api.firecrawl.dev"
+// DefaultFirecrawlAPIURL is the URL of the hosted Firecrawl API.
+const DefaultFirecrawlAPIURL = "https://api.firecrawl.dev"
+
+// firecrawlHTTPTimeout is the same as the default timeout of [firecrawl.NewFirecrawlApp].
+const firecrawlHTTPTimeout = 120 * time.Second
 
 type firecrawlClient struct {
 	client *firecrawl.FirecrawlApp
 	logger *slog.Logger
 }
 
-// NewFirecrawlClient initializes a new [*firecrawl.FirecrawlApp] given an API key.
-func NewFirecrawlClient(apiKey string) (FirecrawlClient, error) {
+var (
+	_ FirecrawlClient = (*firecrawlClient)(nil)
+	_ creditCoster    = (*firecrawlClient)(nil)
+)
+
+// NewFirecrawlClient initializes a new [*firecrawl.FirecrawlApp] given an API key and API URL.
+//
+// If apiURL is empty, [DefaultFirecrawlAPIURL] is used. apiKey may be empty only when apiURL points at a
+// self-hosted Firecrawl instance which doesn't require authentication.
+func NewFirecrawlClient(apiKey, apiURL string) (FirecrawlClient, error) {
 	logger := slog.Default()
 
-	client, err := firecrawl.NewFirecrawlApp(apiKey, firecrawlAPIURL)
-	if err != nil {
-		logger.Error("Failed to initialize FirecrawlApp", "error", err)
-		return nil, fmt.Errorf("initialize FirecrawlApp: %w", err)
+	apiURL = strings.TrimRight(apiURL, "/")
+	if apiURL == "" {
+		apiURL = DefaultFirecrawlAPIURL
+	}
+
+	var client *firecrawl.FirecrawlApp
+	if apiKey == "" && apiURL != DefaultFirecrawlAPIURL {
+		// [firecrawl.NewFirecrawlApp] rejects an empty API key, but self-hosted instances may run without authentication
+		client = &firecrawl.FirecrawlApp{
+			APIURL: apiURL,
+			Client: &http.Client{
+				Timeout:   firecrawlHTTPTimeout,
+				Transport: keylessTransport{base: http.DefaultTransport},
+			},
+		}
+	} else {
+		var err error
+		client, err = firecrawl.NewFirecrawlApp(apiKey, apiURL, firecrawlHTTPTimeout)
+		if err != nil {
+			logger.Error("Failed to initialize FirecrawlApp", "error", err)
+			return nil, fmt.Errorf("initialize FirecrawlApp: %w", err)
+		}
 	}
+	logger.Debug("Initialized FirecrawlApp", "api_url", apiURL)
 
 	return &firecrawlClient{
 		client: client,
@@ -47,6 +81,28 @@ func NewFirecrawlClient(apiKey string) (FirecrawlClient, error) {
 	}, nil
 }
 
+// keylessTransport is the [http.RoundTripper] of a [firecrawl.FirecrawlApp] without an API key.
+//
+// [firecrawl.FirecrawlApp] always sends an Authorization header, which is an empty bearer token without an API key,
+// and which self-hosted instances may reject as malformed.
+type keylessTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t keylessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.TrimSpace(req.Header.Get("Authorization")) == "Bearer" {
+		req = req.Clone(req.Context())
+		req.Header.Del("Authorization")
+	}
+	return t.base.RoundTrip(req)
+}
+
+// credits implements [creditCoster]; every map and scrape request of the Firecrawl API spends a credit.
+func (f *firecrawlClient) credits() int {
+	return 1
+}
+
 func (f *firecrawlClient) MapWebsite(ctx context.Context, url string, limit int, options FirecrawlOptions) ([]string, error) {
 	f.logger.InfoContext(ctx, "Mapping website", "url", url, "limit", limit)
 
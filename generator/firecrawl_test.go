@@ -0,0 +1,125 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// firecrawlServer is a fake of the map and scrape endpoints of a self-hosted Firecrawl instance.
+type firecrawlServer struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	paths []string
+	// auths are the Authorization headers of the requests, "<none>" if absent.
+	auths []string
+}
+
+func newFirecrawlServer(t *testing.T) *firecrawlServer {
+	t.Helper()
+
+	s := &firecrawlServer{}
+	record := func(r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.paths = append(s.paths, r.URL.Path)
+		auth := "<none>"
+		if v, ok := r.Header["Authorization"]; ok {
+			auth = v[0]
+		}
+		s.auths = append(s.auths, auth)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/map", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"success":true,"links":["https://example.com/","https://example.com/docs"]}`)
+	})
+	mux.HandleFunc("POST /v1/scrape", func(w http.ResponseWriter, r *http.Request) {
+		record(r)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"success":true,"data":{"markdown":"# Docs\n\nThe docs.","metadata":{"title":"Docs","description":"The docs of Example.","sourceURL":"https://example.com/docs","statusCode":200}}}`)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestFirecrawlClientSelfHosted(t *testing.T) {
+	tests := map[string]struct {
+		apiKey   string
+		wantAuth string
+	}{
+		"api key":    {apiKey: "fc-test", wantAuth: "Bearer fc-test"},
+		"no api key": {apiKey: "", wantAuth: "<none>"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newFirecrawlServer(t)
+			client, err := NewFirecrawlClient(tt.apiKey, srv.URL+"/")
+			if err != nil {
+				t.Fatalf("NewFirecrawlClient() error = %v", err)
+			}
+
+			urls, err := client.MapWebsite(context.Background(), "https://example.com", 10, FirecrawlOptions{})
+			if err != nil {
+				t.Fatalf("MapWebsite() error = %v", err)
+			}
+			if want := []string{"https://example.com/", "https://example.com/docs"}; !slices.Equal(urls, want) {
+				t.Errorf("MapWebsite() = %v, want %v", urls, want)
+			}
+
+			data, err := client.ScrapeURL(context.Background(), "https://example.com/docs", FirecrawlOptions{Formats: []string{"markdown"}})
+			if err != nil {
+				t.Fatalf("ScrapeURL() error = %v", err)
+			}
+			if data.Markdown != "# Docs\n\nThe docs." || data.Metadata["title"] != "Docs" || data.Metadata["description"] != "The docs of Example." {
+				t.Errorf("ScrapeURL() = %+v", data)
+			}
+
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if want := []string{"/v1/map", "/v1/scrape"}; !slices.Equal(srv.paths, want) {
+				t.Errorf("requested paths = %v, want %v", srv.paths, want)
+			}
+			for i, auth := range srv.auths {
+				if auth != tt.wantAuth {
+					t.Errorf("Authorization of request %d = %q, want %q", i, auth, tt.wantAuth)
+				}
+			}
+		})
+	}
+}
+
+func TestNewFirecrawlClientRequiresAPIKeyForHostedAPI(t *testing.T) {
+	t.Setenv("FIRECRAWL_API_KEY", "")
+
+	for _, apiURL := range []string{"", DefaultFirecrawlAPIURL, DefaultFirecrawlAPIURL + "/"} {
+		if _, err := NewFirecrawlClient("", apiURL); err == nil {
+			t.Errorf("NewFirecrawlClient(%q, %q) error = nil, want an error", "", apiURL)
+		}
+	}
+}
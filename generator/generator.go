@@ -28,13 +28,14 @@ package generator
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -42,6 +43,15 @@ import (
 	"github.com/zchee/llmstxt-generator/gollm"
 )
 
+// Strategies for summarizing the pages longer than [GenerationOptions.MaxContentLength].
+const (
+	// SummarizeChunked splits a long page into chunks at its Markdown headings, summarizes each chunk, and summarizes
+	// the page from the summaries of the chunks. See [gollm.NewChunkedClient].
+	SummarizeChunked = "chunked"
+	// SummarizeTruncate summarizes the beginning of a long page, up to [GenerationOptions.MaxContentLength].
+	SummarizeTruncate = "truncate"
+)
+
 // NewLLMsTxtGenerator creates a new instance of LLMsTxtGenerator with the provided clients and options.
 //
 // Parameters:
@@ -51,40 +61,111 @@ import (
 //
 // Returns a configured generator ready to process websites and generate llms.txt files.
 func NewLLMsTxtGenerator(firecrawlClient FirecrawlClient, SummarizerClient gollm.SummarizerClient, options GenerationOptions) *LLMsTxtGenerator {
-	return &LLMsTxtGenerator{
+	budget := newAccountant(options.Budget)
+	summarizer := newBudgetClient(SummarizerClient, budget, options.MaxContentLength)
+	g := &LLMsTxtGenerator{
 		firecrawlClient: firecrawlClient,
-		summarizer:      SummarizerClient,
+		summarizer:      summarizer,
+		pageSummarizer:  summarizer,
+		budget:          budget,
 		options:         options,
 	}
+	if options.Summarize != SummarizeTruncate {
+		// each chunk is a request of the budget
+		g.pageSummarizer = gollm.NewChunkedClient(summarizer, options.MaxContentLength, options.MaxChunks)
+	}
+	if options.Batch != nil {
+		g.batch = options.Batch
+		g.batchEstimator = gollm.NewEstimateClient(options.MaxContentLength)
+		if options.Summarize != SummarizeTruncate {
+			g.batch = gollm.NewChunkedBatchClient(options.Batch, options.MaxContentLength, options.MaxChunks)
+			g.batchEstimator = gollm.NewChunkedClient(g.batchEstimator, options.MaxContentLength, options.MaxChunks)
+		}
+	}
+	g.previous = g.previousPages()
+
+	return g
 }
 
 const (
-	systemPrompt  = `You are a helpful assistant that generates concise titles and descriptions for web pages.`
-	userPromptFmt = `Generate a 9-10 word description and a 3-4 word title of the entire page based on ALL the content one will find on the page for this url: %s. This will help in a user finding the page for its intended purpose.
+	systemPrompt = `You are a helpful assistant that generates concise titles and descriptions for web pages.`
+	// userPrompt is the same for every page, so that it is cached by the providers; the URL follows in pageSuffixFmt.
+	userPrompt = `Generate a 9-10 word description and a 3-4 word title of the entire page based on ALL the content one will find on the page at the URL given below. This will help in a user finding the page for its intended purpose.
 
 Return the response in JSON format:
 {
     "title": "3-4 word title",
     "description": "9-10 word description"
 }`
+
+	pageSuffixFmt = `URL: %s`
+
+	sitePromptFmt = `Below are the titles and descriptions of the pages of the website %s. Return the name of the website or project as the title, and a 1-2 sentence summary of what the website or project is and who it is for as the description. The summary must describe the whole website, not a single page.
+
+Return the response in JSON format:
+{
+    "title": "name of the website or project",
+    "description": "1-2 sentence summary"
+}`
+
+	taxonomyPromptFmt = `Below are the pages of a website, grouped by their URL path. Propose at most %d categories which group the pages by topic for a reader of the documentation, such as "Getting Started", "Guides", "API Reference" or "Tutorials". Each page must fit exactly one category. Return the category names separated by " | " as the title, in the order a reader should read them.
+
+Return the response in JSON format:
+{
+    "title": "Category 1 | Category 2 | Category 3",
+    "description": "short rationale of the categories"
+}`
+
+	classifyPromptFmt = `Assign the page given below to exactly one of the categories.
+
+Categories:
+%s
+
+Return the category name exactly as listed as the title.
+
+Return the response in JSON format:
+{
+    "title": "category name",
+    "description": "short reason"
+}`
+
+	classifySuffixFmt = `URL: %s
+Title: %s
+Description: %s`
 )
 
 func (g *LLMsTxtGenerator) SystemPrompt() string {
 	return systemPrompt
 }
 
-func (g *LLMsTxtGenerator) UserPrompt(uri string) string {
-	return fmt.Sprintf(userPromptFmt, uri)
+func (g *LLMsTxtGenerator) UserPrompt() string {
+	return userPrompt
+}
+
+// pagePrompt returns the prompt summarizing the page of uri, with the word ranges requested by [userPrompt].
+func (g *LLMsTxtGenerator) pagePrompt(uri string) gollm.Prompt {
+	return gollm.Prompt{
+		System:      g.SystemPrompt(),
+		User:        g.UserPrompt(),
+		Suffix:      fmt.Sprintf(pageSuffixFmt, uri),
+		Title:       gollm.WordRange{Min: 3, Max: 4},
+		Description: gollm.WordRange{Min: 9, Max: 10},
+	}
 }
 
 // GenerateLLMsTXT generates both llms.txt and llms-full.txt files from a target URL.
 //
 // The process includes:
 //  1. Mapping the website to discover all available URLs
-//  2. Processing URLs in configurable batches with rate limiting
-//  3. Scraping content from each URL using Firecrawl
-//  4. Generating AI-powered titles and descriptions using OpenAI
-//  5. Building structured output files
+//  2. Filtering URLs by the include and exclude patterns
+//  3. Excluding URLs disallowed by the crawl policy
+//  4. Processing URLs in configurable batches with per-host politeness delays, checkpointing each completed URL to
+//     the journal so that an interrupted run can be resumed
+//  5. Scraping content from each URL using Firecrawl
+//  6. Generating AI-powered titles and descriptions using OpenAI, or with [GenerationOptions.Batch], in a single batch
+//     once all URLs are scraped
+//  7. Summarizing the site and grouping the pages into sections by URL path and, optionally, by topic
+//  8. Building structured output files
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
@@ -97,160 +178,417 @@ func (g *LLMsTxtGenerator) GenerateLLMsTXT(ctx context.Context, targetURL string
 	logger := slog.Default()
 	logger.InfoContext(ctx, "Generating llms.txt", "url", targetURL)
 
-	urls, err := g.firecrawlClient.MapWebsite(ctx, targetURL, g.options.MaxURLs, g.options.FirecrawlOptions)
-	if err != nil {
-		return nil, fmt.Errorf("map website: %w", err)
+	stats := gollm.NewStats(nil)
+	ctx = gollm.WithStats(ctx, stats)
+
+	if prev := g.options.Previous; prev != nil && g.previous == nil {
+		logger.InfoContext(ctx, "Not reusing the previous manifest generated with another model", "model", prev.Model)
 	}
 
-	if len(urls) == 0 {
-		return nil, fmt.Errorf("no URLs found for the website")
+	optionalFilter, err := NewURLFilter(g.options.OptionalPatterns, nil)
+	if err != nil {
+		return nil, fmt.Errorf("compile optional patterns: %w", err)
 	}
 
-	if len(urls) > g.options.MaxURLs {
-		urls = urls[:g.options.MaxURLs]
+	plan, err := g.options.Journal.resumed(targetURL, g.options.Model)
+	if err != nil {
+		return nil, err
 	}
+	if plan != nil {
+		logger.InfoContext(ctx, "Resuming interrupted run", "completed", g.options.Journal.Completed(), "total", len(plan.URLs))
+		if _, ok := g.firecrawlClient.(stateMapper); ok {
+			// the URLs are planned already, but ScrapeURL needs the state built by MapWebsite
+			if _, err := g.mapWebsite(ctx, targetURL); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		plan, err = g.planURLs(ctx, targetURL, logger)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.options.Journal.begin(plan); err != nil {
+			return nil, err
+		}
+	}
+	urls, excluded, filterReport := plan.URLs, plan.Excluded, plan.Filter
 
 	var allResults []ProcessedURL
-	var mu sync.Mutex
+	var failures []Failure
 
 	batchSize := g.options.BatchSize
 	for i := 0; i < len(urls); i += batchSize {
+		if g.budget.isExhausted() {
+			break
+		}
 		end := min(i+batchSize, len(urls))
 		batch := urls[i:end]
 
 		logger.InfoContext(ctx, "Processing batch", "batch", i/batchSize+1, "total_batches", (len(urls)+batchSize-1)/batchSize)
 
-		batchResults, err := g.processBatch(ctx, batch, i, logger)
+		batchResults, batchFailures, err := g.processBatch(ctx, batch, i, logger)
 		if err != nil {
-			logger.ErrorContext(ctx, "Batch processing failed", "batch", i/batchSize+1, "error", err)
+			return nil, err
 		}
 
-		mu.Lock()
 		allResults = append(allResults, batchResults...)
-		mu.Unlock()
+		failures = append(failures, batchFailures...)
+	}
 
-		if i+batchSize < len(urls) {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(g.options.BatchDelay):
-			}
+	var batchUsage gollm.Usage
+	if g.batch != nil {
+		batchFailures, usage, err := g.summarizeBatch(ctx, allResults, logger)
+		if err != nil {
+			return nil, err
 		}
+		failures = append(failures, batchFailures...)
+		batchUsage = usage
 	}
 
 	slices.SortFunc(allResults, func(url1, url2 ProcessedURL) int {
 		return cmp.Compare(url1.Index, url2.Index)
 	})
 
-	llmsTxt := g.buildLLMsTxt(targetURL, allResults)
-	llmsFullTxt := g.buildLLMsFullTxt(targetURL, allResults)
+	var reused int
+	for _, result := range allResults {
+		if result.Reused {
+			reused++
+		}
+	}
+	skipped := len(urls) - len(allResults)
+	for _, failure := range failures {
+		if failure.Stage == StageScrape {
+			skipped--
+		}
+	}
+	if skipped > 0 {
+		logger.WarnContext(ctx, "Budget exhausted, skipped the remaining URLs", "processed", len(allResults), "skipped", skipped, "total", len(urls))
+	}
+	if g.options.Previous != nil {
+		logger.InfoContext(ctx, "Reused unchanged pages from the previous manifest", "reused", reused, "total", len(allResults))
+	}
+
+	var site Site
+	if g.previous != nil && reused == len(allResults) && reused == len(g.previous) && g.options.Previous.Site.Name != "" {
+		// the site summary only depends on the pages, which are all unchanged
+		site = g.options.Previous.Site
+	} else {
+		site = g.summarizeSite(ctx, targetURL, allResults, logger)
+	}
+	sections := groupSections(allResults, cmp.Or(g.options.SectionDepth, defaultSectionDepth), g.isOptional(optionalFilter))
+	if g.options.SectionMode == SectionsByLLM {
+		if g.budget.isExhausted() {
+			logger.WarnContext(ctx, "Budget exhausted, keeping path sections")
+		} else {
+			sections = g.classifySections(ctx, allResults, sections, logger)
+		}
+	}
+	llmsTxt := renderLLMsTxt(site, sections, g.options.LinkMirrors)
+	llmsFullTxt := g.buildLLMsFullTxt(targetURL, sections)
+	var llmsCtxTxt, llmsCtxFullTxt string
+	if !g.options.NoCtx {
+		llmsCtxTxt = buildLLMsCtx(site, sections, false)
+		llmsCtxFullTxt = buildLLMsCtx(site, sections, true)
+	}
+
+	usage := stats.Usage()
+	if usage.CacheReadTokens > 0 || usage.CacheWriteTokens > 0 {
+		logger.InfoContext(ctx, "Prompt cache usage", "read_tokens", usage.CacheReadTokens, "write_tokens", usage.CacheWriteTokens, "input_tokens", usage.InputTokens)
+	}
+
+	var spent *Spending
+	if g.budget != nil {
+		s := g.budget.spending()
+		spent = &s
+	}
 
 	return &GenerationResult{
-		LLMsTxt:        llmsTxt,
-		LLMsFullTxt:    llmsFullTxt,
-		ProcessedCount: len(allResults),
-		TotalCount:     len(urls),
+		LLMsTxt:         llmsTxt,
+		LLMsFullTxt:     llmsFullTxt,
+		LLMsCtxTxt:      llmsCtxTxt,
+		LLMsCtxFullTxt:  llmsCtxFullTxt,
+		ProcessedCount:  len(allResults),
+		TotalCount:      len(urls),
+		Excluded:        excluded,
+		Filter:          filterReport,
+		Usage:           usage,
+		BatchUsage:      batchUsage,
+		Failures:        failures,
+		Site:            site,
+		Sections:        sections,
+		Reused:          reused,
+		Manifest:        newManifest(targetURL, g.options.Model, site, allResults, failures),
+		BudgetExhausted: g.budget.isExhausted(),
+		Skipped:         skipped,
+		Spent:           spent,
+	}, nil
+}
+
+// planURLs maps the website and returns the URLs to process, after the URL filter, the crawl policy and the maximum
+// number of URLs are applied.
+func (g *LLMsTxtGenerator) planURLs(ctx context.Context, targetURL string, logger *slog.Logger) (*journalPlan, error) {
+	filter, err := NewURLFilter(g.options.IncludePatterns, g.options.ExcludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("compile URL filter: %w", err)
+	}
+
+	urls, err := g.mapWebsite(ctx, targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no URLs found for the website")
+	}
+
+	var filterReport *FilterReport
+	if !filter.Empty() {
+		mapped := len(urls)
+		urls, filterReport = filter.Apply(urls)
+		logFilterReport(ctx, logger, filterReport, mapped, len(urls))
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("all %d URLs found for the website were filtered out by the include and exclude patterns", mapped)
+		}
+	}
+
+	urls, excluded := g.applyCrawlPolicy(ctx, urls, logger)
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("all %d URLs found for the website were excluded by the crawl policy", len(excluded))
+	}
+
+	if len(urls) > g.options.MaxURLs {
+		urls = urls[:g.options.MaxURLs]
+	}
+
+	return &journalPlan{
+		URL:      targetURL,
+		Model:    g.options.Model,
+		URLs:     urls,
+		Excluded: excluded,
+		Filter:   filterReport,
 	}, nil
 }
 
-func (g *LLMsTxtGenerator) processBatch(ctx context.Context, urls []string, startIndex int, logger *slog.Logger) ([]ProcessedURL, error) {
-	results := make([]ProcessedURL, 0, len(urls))
-	var mu sync.Mutex
-	eg, ctx := errgroup.WithContext(ctx)
+// mapWebsite maps the website up to the map limit, spending the credits of a Firecrawl request from the budget.
+func (g *LLMsTxtGenerator) mapWebsite(ctx context.Context, targetURL string) ([]string, error) {
+	credit := Spending{Credits: creditsOf(g.firecrawlClient)}
+	if !g.budget.reserve(credit) {
+		return nil, fmt.Errorf("map website: %w", ErrBudgetExhausted)
+	}
+	defer g.budget.settle(credit, credit)
+
+	urls, err := g.firecrawlClient.MapWebsite(ctx, targetURL, cmp.Or(g.options.MapLimit, g.options.MaxURLs), g.options.FirecrawlOptions)
+	if err != nil {
+		return nil, fmt.Errorf("map website: %w", err)
+	}
+
+	return urls, nil
+}
+
+// scrapeURL scrapes uri, spending the credits of a Firecrawl request from the budget.
+func (g *LLMsTxtGenerator) scrapeURL(ctx context.Context, uri string) (*ScrapedData, error) {
+	credit := Spending{Credits: creditsOf(g.firecrawlClient)}
+	if !g.budget.reserve(credit) {
+		return nil, ErrBudgetExhausted
+	}
+	defer g.budget.settle(credit, credit)
+
+	return g.firecrawlClient.ScrapeURL(ctx, uri, g.options.FirecrawlOptions)
+}
+
+func logFilterReport(ctx context.Context, logger *slog.Logger, report *FilterReport, mapped, kept int) {
+	logger.InfoContext(ctx, "Filtered URLs", "mapped", mapped, "kept", kept, "not_included", report.NotIncluded)
+	for _, stat := range report.Rules {
+		logger.InfoContext(ctx, "URL filter rule", "kind", stat.Kind, "pattern", stat.Pattern, "matched", stat.Matched, "filtered", stat.Filtered)
+	}
+}
+
+// applyCrawlPolicy splits urls into the URLs allowed by the crawl policy and the excluded ones.
+func (g *LLMsTxtGenerator) applyCrawlPolicy(ctx context.Context, urls []string, logger *slog.Logger) ([]string, []ExcludedURL) {
+	allowed := make([]string, 0, len(urls))
+	var excluded []ExcludedURL
+	for _, uri := range urls {
+		if ok, reason := g.options.CrawlPolicy.Allowed(ctx, uri); !ok {
+			logger.InfoContext(ctx, "Excluding URL by crawl policy", "url", uri, "reason", reason)
+			excluded = append(excluded, ExcludedURL{URL: uri, Reason: reason})
+			continue
+		}
+		allowed = append(allowed, uri)
+	}
+
+	return allowed, excluded
+}
+
+// processBatch processes urls concurrently. A URL failing to process does not affect the other URLs;
+// its failure is returned instead. The error is only returned when ctx is done.
+func (g *LLMsTxtGenerator) processBatch(ctx context.Context, urls []string, startIndex int, logger *slog.Logger) ([]ProcessedURL, []Failure, error) {
+	results := make([]*ProcessedURL, len(urls))
+	failures := make([]*Failure, len(urls))
+
+	// the group has no shared context, so a failing URL never cancels the others
+	var eg errgroup.Group
 	eg.SetLimit(g.options.MaxWorkers)
 
 	for i, url := range urls {
 		eg.Go(func() error {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
+			if err := ctx.Err(); err != nil {
+				return err
 			}
 
-			result, err := g.processURL(ctx, url, startIndex+i, logger)
+			if g.budget.isExhausted() {
+				return nil
+			}
+
+			if result, failure, ok := g.options.Journal.lookup(url); ok {
+				logger.DebugContext(ctx, "Skipping URL completed by the interrupted run", "url", url)
+				results[i], failures[i] = result, failure
+				return nil
+			}
+
+			result, failure, err := g.processURL(ctx, url, startIndex+i, logger)
 			if err != nil {
-				logger.ErrorContext(ctx, "Failed to process URL", "url", url, "error", err)
 				return err
 			}
+			if result == nil && failure == nil {
+				// skipped by the budget, and left out of the journal to be processed when the run is resumed
+				return nil
+			}
+			if failure != nil {
+				logger.WarnContext(ctx, "Failed to process URL", "url", url, "stage", failure.Stage, "attempts", failure.Attempts, "error", failure.Error)
+			}
+			results[i], failures[i] = result, failure
+			if result != nil && result.pending {
+				// checkpointed once the batch has summarized it
+				return nil
+			}
 
-			if result != nil {
-				mu.Lock()
-				results = append(results, *result)
-				mu.Unlock()
+			if err := g.options.Journal.record(result, failure); err != nil {
+				logger.WarnContext(ctx, "Failed to checkpoint URL", "url", url, "error", err)
 			}
 
 			return nil
 		})
 	}
-	if err := eg.Wait(); err != nil {
-		return nil, err
+	err := eg.Wait()
+
+	var batchResults []ProcessedURL
+	var batchFailures []Failure
+	for i := range urls {
+		if results[i] != nil {
+			batchResults = append(batchResults, *results[i])
+		}
+		if failures[i] != nil {
+			batchFailures = append(batchFailures, *failures[i])
+		}
 	}
 
-	return results, nil
+	return batchResults, batchFailures, err
 }
 
-func (g *LLMsTxtGenerator) processURL(ctx context.Context, uri string, index int, logger *slog.Logger) (*ProcessedURL, error) {
-	// Check context before expensive operations
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
+// processURL scrapes and summarizes uri.
+//
+// If uri fails to scrape, only the failure is returned. If it fails to summarize, the result falls back to the title
+// and description of the page metadata and is returned along with the failure. If the budget refuses to scrape or
+// summarize uri, nothing is returned. The error is only returned when ctx is done.
+//
+// With [GenerationOptions.Batch], the summary is reserved from the budget and left pending for summarizeBatch, with the
+// title and description of the page metadata until then.
+func (g *LLMsTxtGenerator) processURL(ctx context.Context, uri string, index int, logger *slog.Logger) (*ProcessedURL, *Failure, error) {
+	prev, hasPrev := g.previous[uri]
+	if hasPrev {
+		if entry, ok := g.sitemapEntry(uri); ok && !entry.LastModified.IsZero() && entry.LastModified.Equal(prev.SitemapLastModified) {
+			logger.DebugContext(ctx, "Reusing page with unchanged sitemap lastmod", "url", uri)
+			return g.reusePage(prev, index), nil, nil
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, g.options.Timeout)
+	// wait for the politeness delay outside of the URL processing timeout
+	if err := g.options.CrawlPolicy.Wait(ctx, uri); err != nil {
+		return nil, nil, err
+	}
+
+	// record the LLM requests of this URL separately to report the attempts of a failure
+	stats := gollm.NewStats(gollm.StatsFromContext(ctx))
+	urlCtx, cancel := context.WithTimeout(gollm.WithStats(ctx, stats), g.options.Timeout)
 	defer cancel()
 
-	scrapedData, err := g.firecrawlClient.ScrapeURL(ctx, uri, g.options.FirecrawlOptions)
-	if err != nil || scrapedData == nil || scrapedData.Markdown == "" {
-		return nil, fmt.Errorf("scrape URL %s: %w", uri, err)
+	scrapeCtx := urlCtx
+	if hasPrev {
+		scrapeCtx = withValidators(urlCtx, validators{etag: prev.ETag, lastModified: prev.LastModified})
 	}
-
-	// Check context again before OpenAI call
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
+	scrapedData, err := g.scrapeURL(scrapeCtx, uri)
+	if errors.Is(err, ErrBudgetExhausted) {
+		return nil, nil, nil
 	}
-
-	prompt := gollm.Prompt{
-		System: g.SystemPrompt(),
-		User:   g.UserPrompt(uri),
+	if hasPrev && errors.Is(err, ErrNotModified) {
+		logger.DebugContext(ctx, "Reusing page not modified since the previous run", "url", uri)
+		return g.reusePage(prev, index), nil, nil
+	}
+	if err == nil && (scrapedData == nil || scrapedData.Markdown == "") {
+		err = fmt.Errorf("no markdown content")
 	}
-	title, description, err := g.summarizer.SummarizeContent(ctx, prompt, scrapedData.Markdown)
 	if err != nil {
-		logger.WarnContext(ctx, "Failed to generate description, using defaults", "url", uri, "error", err)
-		title = "Page"
-		description = "No description available"
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+		return nil, &Failure{URL: uri, Stage: StageScrape, Error: err.Error(), Attempts: 1}, nil
 	}
 
-	return &ProcessedURL{
-		URL:         uri,
-		Title:       title,
-		Description: description,
-		Markdown:    scrapedData.Markdown,
-		Index:       index,
-	}, nil
-}
-
-func (g *LLMsTxtGenerator) buildLLMsTxt(targetURL string, results []ProcessedURL) string {
-	// Pre-calculate capacity to avoid reallocations
-	estimatedSize := len(targetURL) + 20 // header size
-	for _, result := range results {
-		estimatedSize += len(result.Title) + len(result.URL) + len(result.Description) + 10 // format overhead
+	result := &ProcessedURL{
+		URL:              uri,
+		Markdown:         scrapedData.Markdown,
+		Index:            index,
+		ETag:             scrapedData.Metadata[metadataETag],
+		HTTPLastModified: scrapedData.Metadata[metadataHTTPLastModified],
+	}
+	if lastmod, ok := scrapedData.Metadata[metadataLastModified]; ok {
+		result.LastModified, _ = time.Parse(time.RFC3339, lastmod)
+	}
+	if priority, ok := scrapedData.Metadata[metadataPriority]; ok {
+		result.Priority, _ = strconv.ParseFloat(priority, 64)
 	}
 
-	var sb strings.Builder
-	sb.Grow(estimatedSize)
-	sb.WriteString(fmt.Sprintf("# %s llms.txt\n\n", targetURL))
+	if hasPrev && prev.ContentHash == contentHash(result.Markdown) {
+		logger.DebugContext(ctx, "Reusing summary of page with unchanged content", "url", uri)
+		result.Title, result.Description, result.Reused = prev.Title, prev.Description, true
+		return result, nil, nil
+	}
 
-	for _, result := range results {
-		sb.WriteString(fmt.Sprintf("- [%s](%s): %s\n", result.Title, result.URL, result.Description))
+	prompt := g.pagePrompt(uri)
+	if g.batch != nil {
+		if !g.budget.reserve(g.estimateBatch(ctx, prompt, result.Markdown)) {
+			logger.DebugContext(ctx, "Skipping URL refused by the budget", "url", uri)
+			return nil, nil, nil
+		}
+		// the page metadata is the fallback if the batch fails to summarize the page
+		result.Title = cmp.Or(scrapedData.Metadata["title"], gollm.DefaultTitle)
+		result.Description = cmp.Or(scrapedData.Metadata["description"], gollm.DefaultDescription)
+		result.pending = true
+		return result, nil, nil
+	}
+	result.Title, result.Description, err = g.pageSummarizer.SummarizeContent(urlCtx, prompt, scrapedData.Markdown)
+	if errors.Is(err, ErrBudgetExhausted) {
+		logger.DebugContext(ctx, "Skipping URL refused by the budget", "url", uri)
+		return nil, nil, nil
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+		result.Title = cmp.Or(scrapedData.Metadata["title"], gollm.DefaultTitle)
+		result.Description = cmp.Or(scrapedData.Metadata["description"], gollm.DefaultDescription)
+		return result, &Failure{URL: uri, Stage: StageSummarize, Error: err.Error(), Attempts: max(stats.Usage().Requests, 1)}, nil
 	}
 
-	return sb.String()
+	return result, nil, nil
 }
 
-func (g *LLMsTxtGenerator) buildLLMsFullTxt(targetURL string, results []ProcessedURL) string {
+func (g *LLMsTxtGenerator) buildLLMsFullTxt(targetURL string, sections []Section) string {
+	var results []ProcessedURL
+	for _, section := range sections {
+		results = append(results, section.Pages...)
+	}
+
 	// Pre-calculate capacity to avoid reallocations
 	estimatedSize := len(targetURL) + 25 // header size
 	for i, result := range results {
@@ -0,0 +1,200 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+// fakeSummarizer is a [gollm.SummarizerClient] returning a fixed title and description.
+type fakeSummarizer struct{}
+
+func (fakeSummarizer) SummarizeContent(context.Context, gollm.Prompt, string) (title, description string, err error) {
+	return "Page Title", "A description of the page content.", nil
+}
+
+// pagesClient is a [FirecrawlClient] serving fixed pages, whose ScrapeURL fails for the URLs in errs.
+type pagesClient struct {
+	urls  []string
+	pages map[string]*ScrapedData
+	errs  map[string]error
+}
+
+func (c *pagesClient) MapWebsite(ctx context.Context, rawURL string, limit int, options FirecrawlOptions) ([]string, error) {
+	return c.urls, nil
+}
+
+func (c *pagesClient) ScrapeURL(ctx context.Context, rawURL string, options FirecrawlOptions) (*ScrapedData, error) {
+	if err := c.errs[rawURL]; err != nil {
+		return nil, err
+	}
+	page, ok := c.pages[rawURL]
+	if !ok {
+		return nil, fmt.Errorf("no page %s", rawURL)
+	}
+	return page, nil
+}
+
+// failingSummarizer is a [gollm.SummarizerClient] failing for the page prompts of the URLs in fail.
+type failingSummarizer struct {
+	fail map[string]bool
+}
+
+func (s failingSummarizer) SummarizeContent(ctx context.Context, prompt gollm.Prompt, content string) (title, description string, err error) {
+	for uri := range s.fail {
+		if prompt.Suffix == fmt.Sprintf(pageSuffixFmt, uri) {
+			return "", "", errors.New("summarize failed")
+		}
+	}
+	return "Page Title", "A description of the page content.", nil
+}
+
+func TestGenerateLLMsTXTToleratesFailures(t *testing.T) {
+	const (
+		good      = "https://example.com/good"
+		scrapeErr = "https://example.com/scrape-error"
+		summErr   = "https://example.com/summarize-error"
+	)
+	client := &pagesClient{
+		urls: []string{good, scrapeErr, summErr},
+		pages: map[string]*ScrapedData{
+			good: {URL: good, Markdown: "# Good\n\nA good page.\n"},
+			summErr: {
+				URL:      summErr,
+				Markdown: "# Unlucky\n\nA page failing to summarize.\n",
+				Metadata: map[string]string{"title": "Metadata Title", "description": "The metadata description."},
+			},
+		},
+		errs: map[string]error{scrapeErr: errors.New("scrape failed")},
+	}
+	g := NewLLMsTxtGenerator(client, failingSummarizer{fail: map[string]bool{summErr: true}}, GenerationOptions{
+		Model:      "test-model",
+		MaxURLs:    10,
+		BatchSize:  10,
+		MaxWorkers: 2,
+		Timeout:    time.Minute,
+	})
+
+	result, err := g.GenerateLLMsTXT(t.Context(), "https://example.com/")
+	if err != nil {
+		t.Fatalf("GenerateLLMsTXT() error = %v", err)
+	}
+	if result.ProcessedCount != 2 {
+		t.Errorf("GenerateLLMsTXT() processed %d URLs, want 2", result.ProcessedCount)
+	}
+
+	failures := slices.SortedFunc(slices.Values(result.Failures), func(a, b Failure) int { return strings.Compare(a.URL, b.URL) })
+	want := []Failure{
+		{URL: scrapeErr, Stage: StageScrape, Error: "scrape failed", Attempts: 1},
+		{URL: summErr, Stage: StageSummarize, Error: "summarize failed", Attempts: 1},
+	}
+	if len(failures) != len(want) {
+		t.Fatalf("GenerateLLMsTXT() failures = %+v, want %+v", failures, want)
+	}
+	for i, f := range failures {
+		w := want[i]
+		if f.URL != w.URL || f.Stage != w.Stage || !strings.Contains(f.Error, w.Error) || f.Attempts != w.Attempts {
+			t.Errorf("GenerateLLMsTXT() failures[%d] = %+v, want %+v", i, f, w)
+		}
+	}
+
+	for _, line := range []string{
+		"- [Page Title](" + good + "): A description of the page content.",
+		"- [Metadata Title](" + summErr + "): The metadata description.",
+	} {
+		if !strings.Contains(result.LLMsTxt, line+"\n") {
+			t.Errorf("GenerateLLMsTXT() llms.txt does not contain %q:\n%s", line, result.LLMsTxt)
+		}
+	}
+	if strings.Contains(result.LLMsTxt, scrapeErr) {
+		t.Errorf("GenerateLLMsTXT() llms.txt contains the URL failing to scrape:\n%s", result.LLMsTxt)
+	}
+}
+
+func TestGenerateLLMsTXTResumeLocal(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"index.md":       "# Home\n\nWelcome.\n",
+		"guide/intro.md": "# Intro\n\nGetting started.\n",
+		"guide/usage.md": "# Usage\n\nRunning it.\n",
+	})
+
+	const (
+		baseURL = "https://example.com/"
+		model   = "test-model"
+	)
+	urls := []string{baseURL, baseURL + "guide/intro", baseURL + "guide/usage"}
+
+	// interrupt a run right after its plan is recorded
+	journalPath := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := OpenJournal(journalPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.begin(&journalPlan{URL: root, Model: model, URLs: urls}); err != nil {
+		t.Fatal(err)
+	}
+	if err := journal.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	journal, err = OpenJournal(journalPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { journal.Close() })
+
+	client, err := NewLocalClient(root, baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewLLMsTxtGenerator(client, fakeSummarizer{}, GenerationOptions{
+		Model:      model,
+		MaxURLs:    10,
+		BatchSize:  10,
+		MaxWorkers: 2,
+		Journal:    journal,
+	})
+
+	result, err := g.GenerateLLMsTXT(context.Background(), root)
+	if err != nil {
+		t.Fatalf("GenerateLLMsTXT() error = %v", err)
+	}
+	if len(result.Failures) > 0 {
+		t.Fatalf("GenerateLLMsTXT() failures = %+v, want none", result.Failures)
+	}
+	if result.ProcessedCount != len(urls) {
+		t.Errorf("GenerateLLMsTXT() processed %d URLs, want %d", result.ProcessedCount, len(urls))
+	}
+	var got []string
+	for _, page := range result.Manifest.Pages {
+		got = append(got, page.URL)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, urls) {
+		t.Errorf("GenerateLLMsTXT() pages = %q, want %q", got, urls)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/go-json-experiment/json"
+)
+
+// journalVersion is the version of the journal format. Journals of other versions can't be resumed.
+const journalVersion = 1
+
+// Journal checkpoints a run to disk, so that an interrupted run can be resumed without processing the completed URLs
+// again. See [GenerationOptions.Journal].
+//
+// The journal is a JSON Lines file. The first line is the plan of the run: the target URL, the model and the URLs to
+// process, so that a resumed run processes the same URLs in the same order and produces the same output. Each
+// following line is a completed URL, with its result, its failure or both.
+//
+// A nil *Journal is valid and records nothing.
+type Journal struct {
+	path   string
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	file      *os.File
+	plan      *journalPlan
+	completed map[string]journalEntry
+}
+
+// journalLine is a line of the journal file; exactly one of its fields is set, except that Result and Failure are
+// both set for a URL which failed to summarize.
+type journalLine struct {
+	Plan    *journalPlan  `json:"plan,omitempty"`
+	Result  *ProcessedURL `json:"result,omitempty"`
+	Failure *Failure      `json:"failure,omitempty"`
+}
+
+// journalPlan is the plan of a run, recorded before any URL is processed.
+type journalPlan struct {
+	Version  int           `json:"version"`
+	URL      string        `json:"url"`
+	Model    string        `json:"model"`
+	URLs     []string      `json:"urls"`
+	Excluded []ExcludedURL `json:"excluded,omitempty"`
+	Filter   *FilterReport `json:"filter,omitempty"`
+}
+
+// journalEntry is a completed URL of the journal.
+type journalEntry struct {
+	result  *ProcessedURL
+	failure *Failure
+}
+
+// OpenJournal opens the journal at path. If resume is true, the completed URLs of the existing journal, if any, are
+// loaded and new URLs are appended to it. Otherwise the existing journal is discarded.
+func OpenJournal(path string, resume bool) (*Journal, error) {
+	j := &Journal{
+		path:      path,
+		logger:    slog.Default().WithGroup("journal"),
+		completed: make(map[string]journalEntry),
+	}
+
+	if !resume {
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			j.logger.Warn("Discarding the journal of an interrupted run, use --resume to continue it", "path", path)
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("create journal: %w", err)
+		}
+		j.file = f
+		return j, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	j.file = f
+
+	size, err := j.load()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	// drop a line truncated by the interruption, so that new lines are appended after the last complete one
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("truncate journal: %w", err)
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("seek journal: %w", err)
+	}
+
+	return j, nil
+}
+
+// load reads the plan and the completed URLs of the journal file and returns the size of its complete lines.
+func (j *Journal) load() (int64, error) {
+	r := bufio.NewReader(j.file)
+
+	var size int64
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				j.logger.Warn("Ignoring truncated journal line", "path", j.path, "line", n)
+			}
+			return size, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("read journal: %w", err)
+		}
+
+		var l journalLine
+		if err := json.Unmarshal(line, &l); err != nil {
+			return 0, fmt.Errorf("parse journal %s:%d: %w", j.path, n, err)
+		}
+		switch {
+		case l.Plan != nil:
+			if n != 1 || l.Plan.Version != journalVersion {
+				return 0, fmt.Errorf("parse journal %s:%d: unexpected plan of version %d", j.path, n, l.Plan.Version)
+			}
+			j.plan = l.Plan
+		case l.Result != nil:
+			j.completed[l.Result.URL] = journalEntry{result: l.Result, failure: l.Failure}
+		case l.Failure != nil:
+			j.completed[l.Failure.URL] = journalEntry{failure: l.Failure}
+		}
+		size += int64(len(line))
+	}
+}
+
+// resumed returns the plan of the journal if it was loaded from an interrupted run of targetURL with model.
+func (j *Journal) resumed(targetURL, model string) (*journalPlan, error) {
+	if j == nil || j.plan == nil {
+		return nil, nil
+	}
+	if j.plan.URL != targetURL || j.plan.Model != model {
+		return nil, fmt.Errorf("journal %s is of a run of %s with model %s, not of %s with model %s", j.path, j.plan.URL, j.plan.Model, targetURL, model)
+	}
+	return j.plan, nil
+}
+
+// begin records the plan of a new run.
+func (j *Journal) begin(plan *journalPlan) error {
+	if j == nil {
+		return nil
+	}
+	plan.Version = journalVersion
+	j.plan = plan
+	return j.append(journalLine{Plan: plan})
+}
+
+// lookup returns the result and failure of rawURL if it was completed by the interrupted run.
+func (j *Journal) lookup(rawURL string) (*ProcessedURL, *Failure, bool) {
+	if j == nil {
+		return nil, nil, false
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry, ok := j.completed[rawURL]
+	return entry.result, entry.failure, ok
+}
+
+// record appends a completed URL to the journal.
+func (j *Journal) record(result *ProcessedURL, failure *Failure) error {
+	if j == nil {
+		return nil
+	}
+	return j.append(journalLine{Result: result, Failure: failure})
+}
+
+// Completed returns the number of completed URLs loaded from the interrupted run.
+func (j *Journal) Completed() int {
+	if j == nil {
+		return 0
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return len(j.completed)
+}
+
+func (j *Journal) append(l journalLine) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return fmt.Errorf("marshal journal line: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// a single write per line keeps the lines whole when the run is interrupted
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	return nil
+}
+
+// Close closes the journal file, keeping it on disk to be resumed.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
+
+// Remove closes and removes the journal file once the run is complete.
+func (j *Journal) Remove() error {
+	if j == nil {
+		return nil
+	}
+	j.file.Close()
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove journal: %w", err)
+	}
+	return nil
+}
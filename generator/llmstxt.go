@@ -0,0 +1,104 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+	"github.com/zchee/llmstxt-generator/llmstxt"
+)
+
+// Site is the site-level information rendered at the top of llms.txt.
+type Site struct {
+	// Name is the name of the site or project, rendered as the H1.
+	Name string `json:"name"`
+	// Summary is a short summary of the site, rendered as the blockquote. It may be empty.
+	Summary string `json:"summary,omitempty"`
+}
+
+// summarizeSite asks the summarizer for the name and summary of the site from the titles and descriptions of results.
+// If the summarizer fails, the name falls back to the domain and the summary is left empty.
+func (g *LLMsTxtGenerator) summarizeSite(ctx context.Context, targetURL string, results []ProcessedURL, logger *slog.Logger) Site {
+	domain, err := ParseDomainFromURL(targetURL)
+	if err != nil || domain == "" {
+		domain = targetURL
+	}
+	site := Site{Name: domain}
+	if len(results) == 0 {
+		return site
+	}
+
+	var sb strings.Builder
+	for _, result := range results {
+		fmt.Fprintf(&sb, "- %s (%s): %s\n", result.Title, result.URL, result.Description)
+	}
+
+	prompt := gollm.Prompt{
+		System: g.SystemPrompt(),
+		User:   fmt.Sprintf(sitePromptFmt, targetURL),
+	}
+	name, summary, err := g.summarizer.SummarizeContent(ctx, prompt, sb.String())
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to summarize site, using the domain as the name", "url", targetURL, "error", err)
+		return site
+	}
+
+	if name != gollm.DefaultTitle {
+		site.Name = name
+	}
+	if summary != gollm.DefaultDescription {
+		site.Summary = summary
+	}
+
+	return site
+}
+
+// renderLLMsTxt renders llms.txt in the format of https://llmstxt.org/#format. If linkMirrors is true, pages are
+// linked to their Markdown mirror (see [MirrorURL]) instead of their URL.
+func renderLLMsTxt(site Site, sections []Section, linkMirrors bool) string {
+	doc := &llmstxt.Document{
+		Title:   site.Name,
+		Summary: site.Summary,
+	}
+	for _, section := range sections {
+		s := &llmstxt.Section{Name: section.Name}
+		for _, page := range section.Pages {
+			link := page.URL
+			if linkMirrors && page.Markdown != "" {
+				if mirror, err := MirrorURL(page.URL); err == nil {
+					link = mirror
+				}
+			}
+			s.Links = append(s.Links, &llmstxt.Link{
+				Title:       page.Title,
+				URL:         link,
+				Description: page.Description,
+			})
+		}
+		doc.Sections = append(doc.Sections, s)
+	}
+
+	return doc.String()
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
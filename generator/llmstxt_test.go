@@ -0,0 +1,104 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/zchee/llmstxt-generator/llmstxt"
+)
+
+func TestRenderLLMsTxt(t *testing.T) {
+	tests := map[string]struct {
+		site     Site
+		sections []Section
+		want     string
+	}{
+		"full": {
+			site: Site{Name: "Example", Summary: "Example is a tool\nfor generating examples."},
+			sections: []Section{
+				{Name: SectionPages, Pages: []ProcessedURL{
+					{URL: "https://example.com/", Title: "Home", Description: "The home page of Example."},
+				}},
+				{Name: "Guides", Pages: []ProcessedURL{
+					{URL: "https://example.com/guides/install", Title: "Install Guide", Description: "How to install Example."},
+					{URL: "https://example.com/guides/usage", Title: "Usage [CLI]", Description: ""},
+				}},
+				{Name: SectionOptional, Pages: []ProcessedURL{
+					{URL: "https://example.com/changelog", Title: "Changelog", Description: "The changes\nof each release."},
+				}},
+			},
+			want: `# Example
+
+> Example is a tool for generating examples.
+
+## Pages
+
+- [Home](https://example.com/): The home page of Example.
+
+## Guides
+
+- [Install Guide](https://example.com/guides/install): How to install Example.
+- [Usage \[CLI\]](https://example.com/guides/usage)
+
+## Optional
+
+- [Changelog](https://example.com/changelog): The changes of each release.
+`,
+		},
+		"no summary": {
+			site: Site{Name: "example.com"},
+			sections: []Section{
+				{Name: SectionPages, Pages: []ProcessedURL{
+					{URL: "https://example.com/a (b)", Title: "A", Description: "The page A."},
+				}},
+			},
+			want: `# example.com
+
+## Pages
+
+- [A](https://example.com/a%20%28b%29): The page A.
+`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := renderLLMsTxt(tt.site, tt.sections, false)
+			if got != tt.want {
+				t.Errorf("renderLLMsTxt() =\n%s\nwant:\n%s", got, tt.want)
+			}
+
+			doc, diags := llmstxt.Parse(got)
+			// a site without a summary only misses the recommended blockquote
+			if llmstxt.HasErrors(diags) || tt.site.Summary != "" && len(diags) > 0 {
+				t.Errorf("Parse(renderLLMsTxt()) diagnostics = %v", diags)
+			}
+			if doc.Title != tt.site.Name {
+				t.Errorf("Parse(renderLLMsTxt()) title = %q, want %q", doc.Title, tt.site.Name)
+			}
+			if len(doc.Sections) != len(tt.sections) {
+				t.Fatalf("Parse(renderLLMsTxt()) has %d sections, want %d", len(doc.Sections), len(tt.sections))
+			}
+			for i, section := range tt.sections {
+				if got := doc.Sections[i]; got.Name != section.Name || len(got.Links) != len(section.Pages) {
+					t.Errorf("Parse(renderLLMsTxt()) section %d = %q with %d links, want %q with %d links",
+						i, got.Name, len(got.Links), section.Name, len(section.Pages))
+				}
+			}
+		})
+	}
+}
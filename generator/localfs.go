@@ -0,0 +1,301 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// localExtensions are the file extensions read by the local directory source.
+var localExtensions = map[string]bool{
+	".md":       true,
+	".mdx":      true,
+	".markdown": true,
+	".html":     true,
+	".htm":      true,
+}
+
+// localIndexNames are the file names, without extension, which map to their directory URL.
+var localIndexNames = map[string]bool{
+	"index":  true,
+	"_index": true,
+	"readme": true,
+}
+
+// skippedDirs are the directory names never walked by the local directory source.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+type localClient struct {
+	fsys    fs.FS
+	root    string
+	baseURL *url.URL
+	logger  *slog.Logger
+
+	mu    sync.RWMutex
+	files map[string]string // public URL to file path relative to root
+}
+
+var (
+	_ FirecrawlClient = (*localClient)(nil)
+	_ stateMapper     = (*localClient)(nil)
+)
+
+// NewLocalClient creates a new [FirecrawlClient] which reads Markdown, MDX and HTML files from the root directory
+// instead of a website, such as a documentation repository or the output directory of a static site generator.
+//
+// File paths are mapped to public URLs under baseURL:
+//   - Markdown files drop their extension ("guide/intro.md" is "guide/intro")
+//   - HTML files keep their extension ("guide/intro.html" is "guide/intro.html")
+//   - index, _index and README files map to their directory ("guide/index.html" is "guide/")
+func NewLocalClient(root, baseURL string) (FirecrawlClient, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("stat source directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("source %s is not a directory", root)
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse base URL: %w", err)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+
+	return &localClient{
+		fsys:    os.DirFS(root),
+		root:    root,
+		baseURL: base,
+		logger:  slog.Default().WithGroup("local"),
+		files:   make(map[string]string),
+	}, nil
+}
+
+// MapWebsite walks the source directory and returns the public URLs of all supported files in lexical order.
+// The url argument is ignored in favor of the base URL.
+//
+// MapWebsite implements [FirecrawlClient].
+func (c *localClient) MapWebsite(ctx context.Context, _ string, limit int, _ FirecrawlOptions) ([]string, error) {
+	c.logger.InfoContext(ctx, "Mapping directory", "root", c.root, "base_url", c.baseURL.String(), "limit", limit)
+
+	var urls []string
+	files := make(map[string]string)
+	errLimit := errors.New("limit reached")
+
+	err := fs.WalkDir(c.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		base := d.Name()
+		if name != "." && (strings.HasPrefix(base, ".") || (d.IsDir() && skippedDirs[base])) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !localExtensions[strings.ToLower(path.Ext(name))] {
+			return nil
+		}
+
+		pageURL := c.publicURL(name)
+		if _, ok := files[pageURL]; ok {
+			c.logger.DebugContext(ctx, "Skipping file mapped to a duplicate URL", "file", name, "url", pageURL)
+			return nil
+		}
+		files[pageURL] = name
+		urls = append(urls, pageURL)
+
+		if limit > 0 && len(urls) >= limit {
+			return errLimit
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errLimit) {
+		return nil, fmt.Errorf("walk %s: %w", c.root, err)
+	}
+
+	c.mu.Lock()
+	c.files = files
+	c.mu.Unlock()
+
+	c.logger.InfoContext(ctx, "Found URLs", "count", len(urls))
+	return urls, nil
+}
+
+// ScrapeURL reads the file mapped to rawURL by MapWebsite and converts it to Markdown.
+//
+// ScrapeURL implements [FirecrawlClient].
+func (c *localClient) ScrapeURL(ctx context.Context, rawURL string, options FirecrawlOptions) (*ScrapedData, error) {
+	c.mu.RLock()
+	name, ok := c.files[rawURL]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no source file mapped to %s", rawURL)
+	}
+
+	c.logger.DebugContext(ctx, "Reading file", "url", rawURL, "file", name)
+
+	body, err := fs.ReadFile(c.fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+
+	metadata := make(map[string]string)
+	var markdown string
+	switch strings.ToLower(path.Ext(name)) {
+	case ".html", ".htm":
+		pageURL, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse URL: %w", err)
+		}
+		doc, err := parseHTMLDocument(string(body), pageURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		markdown = doc.Markdown(options.OnlyMainContent)
+		if title := doc.Title(); title != "" {
+			metadata["title"] = title
+		}
+		if description := doc.Description(); description != "" {
+			metadata["description"] = description
+		}
+
+	case ".mdx":
+		markdown = stripMDXStatements(parseFrontMatter(string(body), metadata))
+
+	default:
+		markdown = parseFrontMatter(string(body), metadata)
+	}
+
+	markdown = strings.TrimSpace(markdown)
+	if markdown == "" {
+		return nil, fmt.Errorf("no markdown content in %s", name)
+	}
+	if _, ok := metadata["title"]; !ok {
+		if title := firstHeading(markdown); title != "" {
+			metadata["title"] = title
+		}
+	}
+
+	return &ScrapedData{
+		URL:      rawURL,
+		Markdown: markdown,
+		Metadata: metadata,
+	}, nil
+}
+
+// needsMap implements [stateMapper]; ScrapeURL reads the source files of the URLs found by MapWebsite.
+func (c *localClient) needsMap() {}
+
+// publicURL maps the slash-separated file path relative to the root to its public URL.
+func (c *localClient) publicURL(name string) string {
+	dir, file := path.Split(name)
+	ext := path.Ext(file)
+	stem := strings.TrimSuffix(file, ext)
+
+	var rel string
+	switch {
+	case localIndexNames[strings.ToLower(stem)]:
+		rel = dir
+	case strings.EqualFold(ext, ".html") || strings.EqualFold(ext, ".htm"):
+		rel = name
+	default:
+		rel = dir + stem
+	}
+
+	if rel == "" {
+		return c.baseURL.String()
+	}
+	return c.baseURL.JoinPath(rel).String()
+}
+
+// parseFrontMatter strips the YAML ("---") or TOML ("+++") front matter from the Markdown source
+// and stores its title and description into metadata.
+//
+// Unterminated front matter is regarded as content, and leaves metadata unchanged.
+func parseFrontMatter(src string, metadata map[string]string) string {
+	var delim, sep string
+	switch {
+	case strings.HasPrefix(src, "---\n") || strings.HasPrefix(src, "---\r\n"):
+		delim, sep = "---", ":"
+	case strings.HasPrefix(src, "+++\n") || strings.HasPrefix(src, "+++\r\n"):
+		delim, sep = "+++", "="
+	default:
+		return src
+	}
+
+	// the keys are only stored once the front matter is known to be terminated
+	fields := make(map[string]string)
+
+	_, rest, _ := strings.Cut(src, "\n")
+	consumed := len(src) - len(rest)
+	for line := range strings.Lines(rest) {
+		consumed += len(line)
+		if strings.TrimSpace(line) == delim {
+			maps.Copy(metadata, fields)
+			return src[consumed:]
+		}
+
+		key, val, ok := strings.Cut(line, sep)
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.Trim(strings.TrimSpace(val), `"'`)
+		if (key == "title" || key == "description") && val != "" {
+			fields[key] = val
+		}
+	}
+
+	return src
+}
+
+var reMDXStatement = regexp.MustCompile(`(?m)^(?:import|export)\s.*$\n?`)
+
+// stripMDXStatements removes the top-level import and export statements of MDX sources.
+func stripMDXStatements(src string) string {
+	return reMDXStatement.ReplaceAllString(src, "")
+}
+
+func firstHeading(markdown string) string {
+	for line := range strings.Lines(markdown) {
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
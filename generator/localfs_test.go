@@ -0,0 +1,274 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeTree writes files, by slash-separated path, under root.
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, body := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLocalClientMapWebsite(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"index.md":                   "# Home",
+		"about.mdx":                  "# About",
+		"guide/README.md":            "# Guide",
+		"guide/index.md":             "# Guide index", // same URL as README.md, which comes first
+		"guide/intro.markdown":       "# Intro",
+		"guide/setup.HTML":           "<h1>Setup</h1>",
+		"blog/_index.md":             "# Blog",
+		"blog/2024/hello.htm":        "<h1>Hello</h1>",
+		"notes.txt":                  "not a page",
+		"logo.png":                   "not a page",
+		".hidden.md":                 "# Hidden",
+		".github/CONTRIBUTING.md":    "# Contributing",
+		"node_modules/pkg/README.md": "# Package",
+		"vendor/lib/doc.md":          "# Vendored",
+	})
+
+	tests := map[string]struct {
+		limit int
+		want  []string
+	}{
+		"all": {
+			want: []string{
+				"https://example.com/docs/about",
+				"https://example.com/docs/blog/2024/hello.htm",
+				"https://example.com/docs/blog/",
+				"https://example.com/docs/guide/",
+				"https://example.com/docs/guide/intro",
+				"https://example.com/docs/guide/setup.HTML",
+				"https://example.com/docs/",
+			},
+		},
+		"limit": {
+			limit: 3,
+			want: []string{
+				"https://example.com/docs/about",
+				"https://example.com/docs/blog/2024/hello.htm",
+				"https://example.com/docs/blog/",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewLocalClient(root, "https://example.com/docs")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.MapWebsite(t.Context(), "ignored", tt.limit, FirecrawlOptions{})
+			if err != nil {
+				t.Fatalf("MapWebsite() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("MapWebsite() =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+
+			// the first file mapped to a URL is scraped
+			if !slices.Contains(got, "https://example.com/docs/guide/") {
+				return
+			}
+			if page, err := c.ScrapeURL(t.Context(), "https://example.com/docs/guide/", FirecrawlOptions{}); err != nil || page.Markdown != "# Guide" {
+				t.Errorf("ScrapeURL(guide/) = %+v, %v, want the README", page, err)
+			}
+		})
+	}
+}
+
+func TestLocalClientPublicURL(t *testing.T) {
+	tests := map[string]struct {
+		baseURL string
+		name    string
+		want    string
+	}{
+		"root index":           {baseURL: "https://example.com", name: "index.md", want: "https://example.com/"},
+		"markdown":             {baseURL: "https://example.com", name: "guide/intro.md", want: "https://example.com/guide/intro"},
+		"mdx":                  {baseURL: "https://example.com", name: "guide/intro.mdx", want: "https://example.com/guide/intro"},
+		"html":                 {baseURL: "https://example.com", name: "guide/intro.html", want: "https://example.com/guide/intro.html"},
+		"directory index":      {baseURL: "https://example.com", name: "guide/index.md", want: "https://example.com/guide/"},
+		"html index":           {baseURL: "https://example.com", name: "guide/index.html", want: "https://example.com/guide/"},
+		"readme":               {baseURL: "https://example.com", name: "guide/README.md", want: "https://example.com/guide/"},
+		"hugo index":           {baseURL: "https://example.com", name: "posts/_index.md", want: "https://example.com/posts/"},
+		"base path":            {baseURL: "https://example.com/docs", name: "guide/intro.md", want: "https://example.com/docs/guide/intro"},
+		"base path with slash": {baseURL: "https://example.com/docs/", name: "index.md", want: "https://example.com/docs/"},
+		"escaped":              {baseURL: "https://example.com", name: "my guide/ü.md", want: "https://example.com/my%20guide/%C3%BC"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewLocalClient(t.TempDir(), tt.baseURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.(*localClient).publicURL(tt.name); got != tt.want {
+				t.Errorf("publicURL(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalClientScrapeURL(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"front-matter.md": "---\ntitle: Front Matter Title\ndescription: From the front matter.\n---\n# Heading Title\n\nBody.\n",
+		"heading.md":      "Intro line.\n\n# Heading Title\n\n## Section\n",
+		"component.mdx":   "import { Tabs } from './tabs'\nexport const meta = {}\n\n# MDX Title\n\n<Tabs />\n",
+		"page.html": `<html><head><title>HTML Title</title><meta name="description" content="From the meta tag."></head>` +
+			`<body><h1>Page Heading</h1><p>Body.</p></body></html>`,
+		"untitled.md": "Just text.\n",
+		"empty.md":    "---\ntitle: Empty\n---\n\n",
+	})
+
+	c, err := NewLocalClient(root, "https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.MapWebsite(t.Context(), "", 0, FirecrawlOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		url          string
+		wantMetadata map[string]string
+		wantMarkdown string
+		wantErr      string
+	}{
+		"front matter": {
+			url:          "https://example.com/front-matter",
+			wantMetadata: map[string]string{"title": "Front Matter Title", "description": "From the front matter."},
+			wantMarkdown: "# Heading Title\n\nBody.",
+		},
+		"heading": {
+			url:          "https://example.com/heading",
+			wantMetadata: map[string]string{"title": "Heading Title"},
+			wantMarkdown: "Intro line.\n\n# Heading Title\n\n## Section",
+		},
+		"mdx": {
+			url:          "https://example.com/component",
+			wantMetadata: map[string]string{"title": "MDX Title"},
+			wantMarkdown: "# MDX Title\n\n<Tabs />",
+		},
+		"html": {
+			url:          "https://example.com/page.html",
+			wantMetadata: map[string]string{"title": "HTML Title", "description": "From the meta tag."},
+			wantMarkdown: "# Page Heading\n\nBody.",
+		},
+		"no title": {
+			url:          "https://example.com/untitled",
+			wantMetadata: map[string]string{},
+			wantMarkdown: "Just text.",
+		},
+		"empty": {
+			url:     "https://example.com/empty",
+			wantErr: "no markdown content in empty.md",
+		},
+		"not mapped": {
+			url:     "https://example.com/missing",
+			wantErr: "no source file mapped to https://example.com/missing",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := c.ScrapeURL(t.Context(), tt.url, FirecrawlOptions{})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ScrapeURL() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ScrapeURL() error = %v", err)
+			}
+			if got.URL != tt.url {
+				t.Errorf("ScrapeURL() URL = %q, want %q", got.URL, tt.url)
+			}
+			if !maps.Equal(got.Metadata, tt.wantMetadata) {
+				t.Errorf("ScrapeURL() metadata = %q, want %q", got.Metadata, tt.wantMetadata)
+			}
+			if got.Markdown != tt.wantMarkdown {
+				t.Errorf("ScrapeURL() markdown = %q, want %q", got.Markdown, tt.wantMarkdown)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatter(t *testing.T) {
+	tests := map[string]struct {
+		src          string
+		want         string
+		wantMetadata map[string]string
+	}{
+		"yaml": {
+			src:          "---\ntitle: \"Getting Started\"\ndescription: 'How to start.'\ntags: [a, b]\n---\n# Heading\n",
+			want:         "# Heading\n",
+			wantMetadata: map[string]string{"title": "Getting Started", "description": "How to start."},
+		},
+		"toml": {
+			src:          "+++\ntitle = \"Guide\"\n+++\nBody\n",
+			want:         "Body\n",
+			wantMetadata: map[string]string{"title": "Guide"},
+		},
+		"crlf": {
+			src:          "---\r\nTitle: Guide\r\n---\r\nBody\r\n",
+			want:         "Body\r\n",
+			wantMetadata: map[string]string{"title": "Guide"},
+		},
+		"empty values": {
+			src:          "---\ntitle:\n---\nBody\n",
+			want:         "Body\n",
+			wantMetadata: map[string]string{"title": "Existing"},
+		},
+		"unterminated": {
+			src:          "---\ntitle: Guide\ndescription: Never closed\n# Heading\n",
+			want:         "---\ntitle: Guide\ndescription: Never closed\n# Heading\n",
+			wantMetadata: map[string]string{"title": "Existing"},
+		},
+		"no front matter": {
+			src:          "# Heading\n---\ntitle: Guide\n---\n",
+			want:         "# Heading\n---\ntitle: Guide\n---\n",
+			wantMetadata: map[string]string{"title": "Existing"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			metadata := map[string]string{"title": "Existing"}
+			if got := parseFrontMatter(tt.src, metadata); got != tt.want {
+				t.Errorf("parseFrontMatter() = %q, want %q", got, tt.want)
+			}
+			if !maps.Equal(metadata, tt.wantMetadata) {
+				t.Errorf("parseFrontMatter() metadata = %q, want %q", metadata, tt.wantMetadata)
+			}
+		})
+	}
+}
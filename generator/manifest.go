@@ -0,0 +1,229 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// manifestVersion is the version of the [Manifest] format. Manifests of other versions are not reused.
+const manifestVersion = 1
+
+// Metadata keys of [ScrapedData] set by the backends which fetch pages over HTTP.
+const (
+	metadataETag             = "etag"
+	metadataHTTPLastModified = "last-modified"
+)
+
+// ErrNotModified is returned by ScrapeURL of a [FirecrawlClient] when the page has not changed since the validators
+// of the previous run, which are passed in the context to backends supporting conditional requests.
+var ErrNotModified = errors.New("not modified")
+
+// Manifest records the pages of a run, so that the next run can reuse the unchanged pages instead of scraping and
+// summarizing them again. See [GenerationOptions.Previous].
+type Manifest struct {
+	Version int    `json:"version"`
+	URL     string `json:"url"`
+	// Model is the LLM which summarized the pages. The pages are not reused by a run with another model.
+	Model       string    `json:"model"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Site is reused when all pages of the next run are reused.
+	Site  Site            `json:"site"`
+	Pages []ManifestEntry `json:"pages"`
+}
+
+// ManifestEntry is a page of a [Manifest].
+//
+// A page is reused without scraping if its sitemap lastmod is unchanged, or if the backend reports that it is not
+// modified since ETag or LastModified. Otherwise it is scraped, and its summary is reused if the content hash is
+// unchanged.
+type ManifestEntry struct {
+	URL string `json:"url"`
+	// ContentHash is the SHA-256 of the Markdown of the page, as "sha256:<hex>".
+	ContentHash string `json:"content_hash"`
+	// ETag and LastModified are the HTTP validators of the page, if the backend reported them.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// SitemapLastModified is the sitemap lastmod of the page, if known.
+	SitemapLastModified time.Time `json:"sitemap_last_modified,omitzero"`
+	Title               string    `json:"title"`
+	Description         string    `json:"description"`
+	// Markdown is the content of the page, kept to render llms-full.txt when the page is reused without scraping.
+	Markdown string `json:"markdown"`
+}
+
+// ReadManifest reads the manifest written by [Manifest.WriteFile].
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	if m.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d of %s", m.Version, path)
+	}
+
+	return &m, nil
+}
+
+// WriteFile writes m to path, replacing the file atomically so that an interrupted write keeps the previous manifest.
+func (m *Manifest) WriteFile(path string) error {
+	data, err := json.Marshal(m, jsontext.WithIndent("  "))
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	return nil
+}
+
+// newManifest returns the manifest of results. Pages which failed to summarize are left out, so that the next run
+// summarizes them again instead of reusing the fallback title and description.
+func newManifest(targetURL, model string, site Site, results []ProcessedURL, failures []Failure) *Manifest {
+	failed := make(map[string]bool)
+	for _, failure := range failures {
+		failed[failure.URL] = true
+	}
+
+	m := &Manifest{
+		Version:     manifestVersion,
+		URL:         targetURL,
+		Model:       model,
+		GeneratedAt: time.Now().UTC(),
+		Site:        site,
+		Pages:       make([]ManifestEntry, 0, len(results)),
+	}
+	for _, result := range results {
+		if failed[result.URL] {
+			continue
+		}
+		m.Pages = append(m.Pages, ManifestEntry{
+			URL:                 result.URL,
+			ContentHash:         contentHash(result.Markdown),
+			ETag:                result.ETag,
+			LastModified:        result.HTTPLastModified,
+			SitemapLastModified: result.LastModified,
+			Title:               result.Title,
+			Description:         result.Description,
+			Markdown:            result.Markdown,
+		})
+	}
+
+	return m
+}
+
+// previousPages returns the pages of the previous manifest by URL, or nil if they can't be reused by this run.
+func (g *LLMsTxtGenerator) previousPages() map[string]ManifestEntry {
+	prev := g.options.Previous
+	if prev == nil || prev.Model != g.options.Model {
+		return nil
+	}
+
+	pages := make(map[string]ManifestEntry, len(prev.Pages))
+	for _, entry := range prev.Pages {
+		pages[entry.URL] = entry
+	}
+
+	return pages
+}
+
+// reusePage returns the result of a page rebuilt from its previous entry, with the current sitemap priority.
+func (g *LLMsTxtGenerator) reusePage(entry ManifestEntry, index int) *ProcessedURL {
+	result := &ProcessedURL{
+		URL:              entry.URL,
+		Title:            entry.Title,
+		Description:      entry.Description,
+		Markdown:         entry.Markdown,
+		Index:            index,
+		LastModified:     entry.SitemapLastModified,
+		ETag:             entry.ETag,
+		HTTPLastModified: entry.LastModified,
+		Reused:           true,
+	}
+	if sitemap, ok := g.sitemapEntry(entry.URL); ok {
+		result.LastModified = sitemap.LastModified
+		result.Priority = sitemap.Priority
+	}
+
+	return result
+}
+
+// sitemapEntry returns the sitemap entry of rawURL if the client discovered the URLs from sitemaps.
+func (g *LLMsTxtGenerator) sitemapEntry(rawURL string) (sitemapEntry, bool) {
+	lookup, ok := g.firecrawlClient.(sitemapLookup)
+	if !ok {
+		return sitemapEntry{}, false
+	}
+	return lookup.sitemapEntry(rawURL)
+}
+
+func contentHash(markdown string) string {
+	sum := sha256.Sum256([]byte(markdown))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// validators are the HTTP validators of the previous run of a page.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+type validatorsKey struct{}
+
+// withValidators returns a copy of ctx carrying the validators of the previous run of the page being scraped.
+func withValidators(ctx context.Context, v validators) context.Context {
+	if v == (validators{}) {
+		return ctx
+	}
+	return context.WithValue(ctx, validatorsKey{}, v)
+}
+
+// validatorsFromContext returns the validators of ctx, if any.
+func validatorsFromContext(ctx context.Context) (validators, bool) {
+	v, ok := ctx.Value(validatorsKey{}).(validators)
+	return v, ok
+}
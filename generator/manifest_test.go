@@ -0,0 +1,257 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+// manifestSite is a [FirecrawlClient] of a site discovered from its sitemap, which records the scraped URLs and the
+// validators they were scraped with.
+type manifestSite struct {
+	urls        []string
+	pages       map[string]string
+	lastmod     map[string]time.Time
+	notModified map[string]bool
+
+	mu         sync.Mutex
+	scraped    []string
+	validators map[string]validators
+}
+
+func (s *manifestSite) MapWebsite(ctx context.Context, rawURL string, limit int, options FirecrawlOptions) ([]string, error) {
+	return s.urls, nil
+}
+
+func (s *manifestSite) ScrapeURL(ctx context.Context, rawURL string, options FirecrawlOptions) (*ScrapedData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scraped = append(s.scraped, rawURL)
+	if v, ok := validatorsFromContext(ctx); ok {
+		s.validators[rawURL] = v
+	}
+	if s.notModified[rawURL] {
+		return nil, ErrNotModified
+	}
+	return &ScrapedData{URL: rawURL, Markdown: s.pages[rawURL]}, nil
+}
+
+func (s *manifestSite) sitemapEntry(rawURL string) (sitemapEntry, bool) {
+	lastmod, ok := s.lastmod[rawURL]
+	return sitemapEntry{URL: rawURL, LastModified: lastmod}, ok
+}
+
+// pageRecorder is a [gollm.SummarizerClient] recording the URLs of the summarized pages.
+type pageRecorder struct {
+	mu   sync.Mutex
+	urls []string
+}
+
+func (r *pageRecorder) SummarizeContent(ctx context.Context, prompt gollm.Prompt, content string) (title, description string, err error) {
+	if uri, ok := strings.CutPrefix(prompt.Suffix, "URL: "); ok {
+		r.mu.Lock()
+		r.urls = append(r.urls, uri)
+		r.mu.Unlock()
+	}
+	return "New Title", "A new description of the page.", nil
+}
+
+func TestGenerateLLMsTXTReusesManifest(t *testing.T) {
+	const (
+		lastmodURL     = "https://example.com/lastmod"
+		notModifiedURL = "https://example.com/not-modified"
+		sameContentURL = "https://example.com/same-content"
+		changedURL     = "https://example.com/changed"
+		model          = "test-model"
+	)
+	lastmod := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry := func(uri, markdown string) ManifestEntry {
+		return ManifestEntry{
+			URL:         uri,
+			ContentHash: contentHash(markdown),
+			ETag:        `"v1"`,
+			Title:       "Previous Title",
+			Description: "The previous description of the page.",
+			Markdown:    markdown,
+		}
+	}
+	previous := &Manifest{
+		Version: manifestVersion,
+		URL:     "https://example.com/",
+		Model:   model,
+		Site:    Site{Name: "Example"},
+		Pages: []ManifestEntry{
+			entry(lastmodURL, "# Lastmod"),
+			entry(notModifiedURL, "# Not Modified"),
+			entry(sameContentURL, "# Same Content"),
+			entry(changedURL, "# Old Content"),
+		},
+	}
+	previous.Pages[0].SitemapLastModified = lastmod
+
+	tests := map[string]struct {
+		model          string
+		wantScraped    []string
+		wantSummarized []string
+		wantReused     []string
+	}{
+		"same model": {
+			model:          model,
+			wantScraped:    []string{changedURL, notModifiedURL, sameContentURL},
+			wantSummarized: []string{changedURL},
+			wantReused:     []string{lastmodURL, notModifiedURL, sameContentURL},
+		},
+		"changed model": {
+			model:          "other-model",
+			wantScraped:    []string{changedURL, lastmodURL, notModifiedURL, sameContentURL},
+			wantSummarized: []string{changedURL, lastmodURL, notModifiedURL, sameContentURL},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			site := &manifestSite{
+				urls: []string{lastmodURL, notModifiedURL, sameContentURL, changedURL},
+				pages: map[string]string{
+					lastmodURL:     "# Lastmod",
+					notModifiedURL: "# Not Modified",
+					sameContentURL: "# Same Content",
+					changedURL:     "# New Content",
+				},
+				lastmod:     map[string]time.Time{lastmodURL: lastmod},
+				notModified: map[string]bool{notModifiedURL: tt.model == model},
+				validators:  make(map[string]validators),
+			}
+			summarizer := &pageRecorder{}
+			g := NewLLMsTxtGenerator(site, summarizer, GenerationOptions{
+				Model:      tt.model,
+				MaxURLs:    10,
+				BatchSize:  10,
+				MaxWorkers: 2,
+				Timeout:    time.Minute,
+				Previous:   previous,
+			})
+
+			result, err := g.GenerateLLMsTXT(t.Context(), "https://example.com/")
+			if err != nil {
+				t.Fatalf("GenerateLLMsTXT() error = %v", err)
+			}
+			if len(result.Failures) > 0 {
+				t.Fatalf("GenerateLLMsTXT() failures = %+v, want none", result.Failures)
+			}
+
+			slices.Sort(site.scraped)
+			if !slices.Equal(site.scraped, tt.wantScraped) {
+				t.Errorf("GenerateLLMsTXT() scraped %q, want %q", site.scraped, tt.wantScraped)
+			}
+			slices.Sort(summarizer.urls)
+			if !slices.Equal(summarizer.urls, tt.wantSummarized) {
+				t.Errorf("GenerateLLMsTXT() summarized %q, want %q", summarizer.urls, tt.wantSummarized)
+			}
+			if result.Reused != len(tt.wantReused) {
+				t.Errorf("GenerateLLMsTXT() reused %d pages, want %d", result.Reused, len(tt.wantReused))
+			}
+
+			// the previous validators are only sent with a reusable manifest
+			wantValidators := validators{}
+			if len(tt.wantReused) > 0 {
+				wantValidators = validators{etag: `"v1"`}
+			}
+			for _, uri := range tt.wantScraped {
+				if got := site.validators[uri]; got != wantValidators {
+					t.Errorf("ScrapeURL(%s) validators = %+v, want %+v", uri, got, wantValidators)
+				}
+			}
+
+			titles := make(map[string]string)
+			for _, page := range result.Manifest.Pages {
+				titles[page.URL] = page.Title
+			}
+			for _, uri := range site.urls {
+				want := "New Title"
+				if slices.Contains(tt.wantReused, uri) {
+					want = "Previous Title"
+				}
+				if titles[uri] != want {
+					t.Errorf("GenerateLLMsTXT() manifest title of %s = %q, want %q", uri, titles[uri], want)
+				}
+			}
+		})
+	}
+}
+
+func TestManifestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llms-manifest.json")
+	want := &Manifest{
+		Version:     manifestVersion,
+		URL:         "https://example.com/",
+		Model:       "test-model",
+		GeneratedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Site:        Site{Name: "Example", Summary: "An example site."},
+		Pages: []ManifestEntry{{
+			URL:                 "https://example.com/docs",
+			ContentHash:         contentHash("# Docs"),
+			ETag:                `"v1"`,
+			LastModified:        "Thu, 02 Jan 2025 03:04:05 GMT",
+			SitemapLastModified: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			Title:               "Docs",
+			Description:         "The documentation.",
+			Markdown:            "# Docs",
+		}},
+	}
+
+	if err := want.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	// a second write replaces the manifest without leaving temporary files
+	if err := want.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if matches, _ := filepath.Glob(path + ".*"); len(matches) > 0 {
+		t.Errorf("WriteFile() left temporary files %q", matches)
+	}
+
+	got, err := ReadManifest(path)
+	if err != nil {
+		t.Fatalf("ReadManifest() error = %v", err)
+	}
+	if got.Version != want.Version || got.URL != want.URL || got.Model != want.Model ||
+		!got.GeneratedAt.Equal(want.GeneratedAt) || got.Site != want.Site || !slices.EqualFunc(got.Pages, want.Pages, manifestEntryEqual) {
+		t.Errorf("ReadManifest() = %+v, want %+v", got, want)
+	}
+
+	want.Version = manifestVersion + 1
+	if err := want.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadManifest(path); err == nil {
+		t.Error("ReadManifest() of another version error = nil, want an error")
+	}
+}
+
+func manifestEntryEqual(a, b ManifestEntry) bool {
+	return a.SitemapLastModified.Equal(b.SitemapLastModified) &&
+		a.URL == b.URL && a.ContentHash == b.ContentHash && a.ETag == b.ETag && a.LastModified == b.LastModified &&
+		a.Title == b.Title && a.Description == b.Description && a.Markdown == b.Markdown
+}
@@ -0,0 +1,509 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlDocument is a parsed HTML page along with the metadata needed to convert it to Markdown.
+type htmlDocument struct {
+	root *html.Node
+	base *url.URL
+}
+
+// parseHTMLDocument parses the HTML page body fetched from pageURL.
+func parseHTMLDocument(body string, pageURL *url.URL) (*htmlDocument, error) {
+	root, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("parse HTML: %w", err)
+	}
+
+	base := pageURL
+	if n := findElement(root, func(n *html.Node) bool { return n.DataAtom == atom.Base }); n != nil {
+		if href := attr(n, "href"); href != "" {
+			if u, err := pageURL.Parse(href); err == nil {
+				base = u
+			}
+		}
+	}
+
+	return &htmlDocument{
+		root: root,
+		base: base,
+	}, nil
+}
+
+// Title returns the content of the <title> element.
+func (d *htmlDocument) Title() string {
+	n := findElement(d.root, func(n *html.Node) bool { return n.DataAtom == atom.Title })
+	if n == nil {
+		return ""
+	}
+	return collapseWhitespace(strings.TrimSpace(textContent(n)))
+}
+
+// Description returns the content of the description or og:description <meta> element.
+func (d *htmlDocument) Description() string {
+	var description string
+	findElement(d.root, func(n *html.Node) bool {
+		if n.DataAtom != atom.Meta {
+			return false
+		}
+		switch strings.ToLower(cmp.Or(attr(n, "name"), attr(n, "property"))) {
+		case "description":
+			description = attr(n, "content")
+			return true
+		case "og:description":
+			if description == "" {
+				description = attr(n, "content")
+			}
+		}
+		return false
+	})
+	return strings.TrimSpace(description)
+}
+
+// Links returns the absolute URLs of all <a href> elements without fragments, in document order.
+func (d *htmlDocument) Links() []string {
+	var links []string
+	seen := make(map[string]bool)
+	findElement(d.root, func(n *html.Node) bool {
+		if n.DataAtom != atom.A {
+			return false
+		}
+		href := strings.TrimSpace(attr(n, "href"))
+		if href == "" || strings.HasPrefix(href, "#") {
+			return false
+		}
+		u, err := d.base.Parse(href)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return false
+		}
+		u.Fragment = ""
+		u.RawFragment = ""
+		if link := u.String(); !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+		return false
+	})
+	return links
+}
+
+// Markdown converts the document body to Markdown.
+//
+// If onlyMainContent is true, the conversion starts from the <main>, [role=main] or <article> element when present,
+// and page chrome such as <nav>, <header>, <footer> and <aside> is skipped.
+func (d *htmlDocument) Markdown(onlyMainContent bool) string {
+	start := findElement(d.root, func(n *html.Node) bool { return n.DataAtom == atom.Body })
+	if onlyMainContent {
+		if n := findElement(d.root, func(n *html.Node) bool {
+			return n.DataAtom == atom.Main || attr(n, "role") == "main"
+		}); n != nil {
+			start = n
+		} else if n := findElement(d.root, func(n *html.Node) bool { return n.DataAtom == atom.Article }); n != nil {
+			start = n
+		}
+	}
+	if start == nil {
+		start = d.root
+	}
+
+	c := &markdownConverter{
+		base:       d.base,
+		skipChrome: onlyMainContent,
+	}
+	return c.renderBlock(start)
+}
+
+// markdownConverter renders an HTML node tree as Markdown.
+type markdownConverter struct {
+	base       *url.URL
+	skipChrome bool
+}
+
+func (c *markdownConverter) renderBlock(n *html.Node) string {
+	var buf markdownBuffer
+	c.renderChildren(&buf, n)
+	return buf.String()
+}
+
+func (c *markdownConverter) renderInline(n *html.Node) string {
+	return strings.Join(strings.Fields(c.renderBlock(n)), " ")
+}
+
+func (c *markdownConverter) renderChildren(buf *markdownBuffer, n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.render(buf, child)
+	}
+}
+
+func (c *markdownConverter) render(buf *markdownBuffer, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		buf.text(collapseWhitespace(n.Data))
+		return
+	case html.DocumentNode:
+		c.renderChildren(buf, n)
+		return
+	case html.ElementNode:
+		// handled below
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg, atom.Iframe, atom.Head,
+		atom.Button, atom.Select, atom.Input, atom.Textarea:
+		return
+
+	case atom.Nav, atom.Header, atom.Footer, atom.Aside:
+		if !c.skipChrome {
+			buf.block(c.renderBlock(n))
+		}
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		if text := c.renderInline(n); text != "" {
+			level := int(n.Data[1] - '0')
+			buf.block(strings.Repeat("#", level) + " " + text)
+		}
+
+	case atom.Pre:
+		code := strings.TrimRight(textContent(n), "\n")
+		if code != "" {
+			fence := codeFence(code)
+			buf.block(fence + codeLanguage(n) + "\n" + code + "\n" + fence)
+		}
+
+	case atom.Blockquote:
+		if inner := c.renderBlock(n); inner != "" {
+			buf.block(prefixLines(inner, "> ", "> "))
+		}
+
+	case atom.Ul, atom.Ol:
+		buf.block(c.renderList(n))
+
+	case atom.Table:
+		buf.block(c.renderTable(n))
+
+	case atom.Hr:
+		buf.block("---")
+
+	case atom.Br:
+		buf.newline()
+
+	case atom.A:
+		text := c.renderInline(n)
+		href := c.resolve(attr(n, "href"))
+		switch {
+		case text == "":
+			// nothing to do
+		case href == "" || strings.HasPrefix(href, "javascript:"):
+			buf.text(text)
+		default:
+			buf.raw(fmt.Sprintf("[%s](%s)", text, href))
+		}
+
+	case atom.Img:
+		if src := c.resolve(attr(n, "src")); src != "" {
+			buf.raw(fmt.Sprintf("![%s](%s)", strings.TrimSpace(attr(n, "alt")), src))
+		}
+
+	case atom.Strong, atom.B:
+		if text := c.renderInline(n); text != "" {
+			buf.raw("**" + text + "**")
+		}
+
+	case atom.Em, atom.I:
+		if text := c.renderInline(n); text != "" {
+			buf.raw("_" + text + "_")
+		}
+
+	case atom.Code, atom.Kbd, atom.Samp:
+		if text := textContent(n); text != "" {
+			buf.raw("`" + text + "`")
+		}
+
+	case atom.Dt:
+		if text := c.renderInline(n); text != "" {
+			buf.block("**" + text + "**")
+		}
+
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Figure, atom.Figcaption,
+		atom.Dl, atom.Dd, atom.Details, atom.Summary, atom.Address, atom.Form, atom.Fieldset:
+		buf.block(c.renderBlock(n))
+
+	default:
+		c.renderChildren(buf, n)
+	}
+}
+
+func (c *markdownConverter) renderList(n *html.Node) string {
+	ordered := n.DataAtom == atom.Ol
+	num := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		num = start
+	}
+
+	var items []string
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(num) + ". "
+			num++
+		}
+
+		// render tight lists: paragraphs inside a list item are kept on consecutive lines
+		body := strings.ReplaceAll(c.renderBlock(li), "\n\n", "\n")
+		items = append(items, prefixLines(body, marker, strings.Repeat(" ", len(marker))))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+func (c *markdownConverter) renderTable(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Tr:
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Th || cell.DataAtom == atom.Td) {
+						row = append(row, strings.ReplaceAll(c.renderInline(cell), "|", `\|`))
+					}
+				}
+				if len(row) > 0 {
+					rows = append(rows, row)
+				}
+			case atom.Table:
+				// nested tables are flattened into the outer table
+				walk(child)
+			default:
+				walk(child)
+			}
+		}
+	}
+	walk(n)
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+
+	var sb strings.Builder
+	for i, row := range rows {
+		for len(row) < cols {
+			row = append(row, "")
+		}
+		sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			sb.WriteString("|" + strings.Repeat(" --- |", cols) + "\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func (c *markdownConverter) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := c.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// markdownBuffer accumulates Markdown output and keeps block separators and whitespace normalized.
+//
+// The trailing spaces and newlines are held back in pending rather than written to sb, so that blocks and line breaks
+// trim them without rewriting the output.
+type markdownBuffer struct {
+	sb      strings.Builder
+	pending string
+}
+
+func (w *markdownBuffer) atLineStart() bool {
+	if w.pending != "" {
+		return true
+	}
+	// sb never ends with a space or a newline
+	return w.sb.Len() == 0
+}
+
+// write appends s, holding back its trailing spaces and newlines.
+func (w *markdownBuffer) write(s string) {
+	trimmed := strings.TrimRight(s, " \n")
+	if trimmed == "" {
+		w.pending += s
+		return
+	}
+	w.sb.WriteString(w.pending)
+	w.sb.WriteString(trimmed)
+	w.pending = s[len(trimmed):]
+}
+
+func (w *markdownBuffer) text(s string) {
+	if w.atLineStart() {
+		s = strings.TrimLeft(s, " ")
+	}
+	w.write(s)
+}
+
+func (w *markdownBuffer) raw(s string) {
+	w.write(s)
+}
+
+func (w *markdownBuffer) newline() {
+	w.pending = strings.TrimRight(w.pending, " ") + "\n"
+}
+
+func (w *markdownBuffer) block(s string) {
+	s = strings.Trim(s, "\n")
+	if strings.TrimSpace(s) == "" {
+		return
+	}
+	w.pending = ""
+	if w.sb.Len() > 0 {
+		w.pending = "\n\n"
+	}
+	w.write(s)
+	w.write("\n\n")
+}
+
+var reBlankLines = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)+`)
+
+func (w *markdownBuffer) String() string {
+	lines := strings.Split(w.sb.String()+w.pending, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	s := strings.Join(lines, "\n")
+	s = reBlankLines.ReplaceAllString(s, "\n\n")
+	return strings.Trim(s, "\n")
+}
+
+// findElement walks the tree rooted at n in depth-first order and returns the first element node matching fn.
+func findElement(n *html.Node, fn func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && fn(n) {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, fn); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		sb.WriteString(textContent(child))
+	}
+	return sb.String()
+}
+
+var reWhitespace = regexp.MustCompile(`\s+`)
+
+func collapseWhitespace(s string) string {
+	return reWhitespace.ReplaceAllString(s, " ")
+}
+
+// codeFence returns the fence of a code block of code: a run of backticks longer than any in code, and at least three,
+// so that the code can't close the block.
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for i := range len(code) {
+		if code[i] == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+func codeLanguage(pre *html.Node) string {
+	classes := attr(pre, "class")
+	for child := pre.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == atom.Code {
+			classes += " " + attr(child, "class")
+		}
+	}
+	for class := range strings.FieldsSeq(classes) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if lang, ok := strings.CutPrefix(class, prefix); ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
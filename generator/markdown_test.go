@@ -0,0 +1,165 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+func TestHTMLDocumentMarkdown(t *testing.T) {
+	tests := map[string]struct {
+		body            string
+		onlyMainContent bool
+		want            string
+	}{
+		"blocks": {
+			body: `<h1>Title</h1><p>Some <b>bold</b> and <em>em</em> text.</p><hr><p>After</p>`,
+			want: "# Title\n\nSome **bold** and _em_ text.\n\n---\n\nAfter",
+		},
+		"code blocks keep their lines": {
+			body: "<pre><code class=\"language-sh\"># fetch the sources\ngit clone repo\n\n\n# build\nmake\n</code></pre>",
+			want: "```sh\n# fetch the sources\ngit clone repo\n\n# build\nmake\n```",
+		},
+		"code blocks containing fences": {
+			body: "<pre>```go\nfmt.Println(\"`x`\")\n```</pre><pre>````\n``</pre>",
+			want: "````\n```go\nfmt.Println(\"`x`\")\n```\n````\n\n`````\n````\n``\n`````",
+		},
+		"lists and quotes": {
+			body: `<ul><li>one</li><li>two<ol><li>nested</li></ol></li></ul><blockquote><p>quoted</p><p>twice</p></blockquote>`,
+			want: "- one\n- two\n  1. nested\n\n> quoted\n>\n> twice",
+		},
+		"relative links": {
+			body: `<base href="/docs/"><p><a href="guide">Guide</a> <img src="/logo.png" alt="Logo"> <a href="javascript:void(0)">JS</a></p>`,
+			want: "[Guide](https://example.com/docs/guide) ![Logo](https://example.com/logo.png) JS",
+		},
+		"line breaks": {
+			body: `<p>one<br>two <br> three</p>`,
+			want: "one\ntwo\nthree",
+		},
+		"main content": {
+			body:            `<nav>Menu</nav><main><h2>Title</h2><p>Text</p></main><footer>Footer</footer>`,
+			onlyMainContent: true,
+			want:            "## Title\n\nText",
+		},
+		"scripts are skipped": {
+			body: `<script>alert(1)</script><style>p{}</style><p>Text</p>`,
+			want: "Text",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			doc, err := parseHTMLDocument(tt.body, mustParseURL(t, "https://example.com/"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := doc.Markdown(tt.onlyMainContent); got != tt.want {
+				t.Errorf("Markdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzHTMLDocumentMarkdown(f *testing.F) {
+	f.Add(`<html><head><title>Guide</title><meta name="description" content="How to start."></head><body><h1>Guide</h1><p>Text</p></body></html>`, 32)
+	f.Add("<main><h2>Install</h2><pre><code class=\"language-sh\"># fetch\ngit clone repo\n\n# build\nmake</code></pre><h2>Usage</h2><p>Run it.</p></main>", 24)
+	f.Add("<pre>```\n# not a heading\n```</pre><p>after</p>", 10)
+	f.Add(`<ul><li>one<ul><li>two<blockquote><pre>code</pre></blockquote></li></ul></li></ul>`, 8)
+	f.Add(`<table><tr><th>a|b</th><td>c<br>d</td></tr><tr><td colspan=2>e</td></tr></table>`, 16)
+	f.Add(`<base href="//other.example/"><a href="x#y">x</a><a href="mailto:a@b">m</a><img src=" ">`, 5)
+	f.Add("<p> 　text more&nbsp;</p>", 4)
+	f.Add("00000\u3000\x80", 4)
+
+	base := &url.URL{Scheme: "https", Host: "example.com", Path: "/docs/"}
+	f.Fuzz(func(t *testing.T, body string, maxLength int) {
+		doc, err := parseHTMLDocument(body, base)
+		if err != nil {
+			t.Skip()
+		}
+		_ = doc.Title()
+		_ = doc.Description()
+
+		seen := make(map[string]bool)
+		for _, link := range doc.Links() {
+			u, err := url.Parse(link)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Fragment != "" {
+				t.Errorf("Links() returned %q, want an absolute URL without fragment", link)
+			}
+			if seen[link] {
+				t.Errorf("Links() returned %q twice", link)
+			}
+			seen[link] = true
+		}
+
+		maxLength = max(maxLength%(1<<10), 0)
+		for _, onlyMainContent := range []bool{false, true} {
+			markdown := doc.Markdown(onlyMainContent)
+			if strings.HasPrefix(markdown, "\n") || strings.HasSuffix(markdown, "\n") || strings.Contains(markdown, "\n\n\n") {
+				t.Errorf("Markdown(%t) = %q, want no leading, trailing or repeated blank lines", onlyMainContent, markdown)
+			}
+			for line := range strings.Lines(markdown) {
+				if strings.TrimRight(line, "\n") != strings.TrimRight(line, " \t\n") {
+					t.Errorf("Markdown(%t) line %q has trailing white space", onlyMainContent, line)
+				}
+			}
+			checkMarkdownChunks(t, markdown, maxLength, gollm.SplitMarkdown(markdown, maxLength))
+		}
+	})
+}
+
+// checkMarkdownChunks reports the chunks of markdown returned by [gollm.SplitMarkdown] which exceed maxLength, and
+// whether the chunks lost or reordered any of the content. Only the white space around the chunks may be dropped.
+func checkMarkdownChunks(t *testing.T, markdown string, maxLength int, chunks []string) {
+	t.Helper()
+
+	if maxLength <= 0 || len(markdown) <= maxLength {
+		if len(chunks) != 1 || chunks[0] != markdown {
+			t.Fatalf("SplitMarkdown(%q, %d) = %q, want the Markdown as a single chunk", markdown, maxLength, chunks)
+		}
+		return
+	}
+
+	var offset int
+	for i, chunk := range chunks {
+		next := -1
+		for j := offset; j <= len(markdown); j++ {
+			k := strings.Index(markdown[j:], chunk)
+			if k < 0 {
+				break
+			}
+			if strings.TrimSpace(markdown[offset:j+k]) == "" {
+				next = j + k
+				break
+			}
+			j += k
+		}
+		if next < 0 {
+			t.Fatalf("chunk %d %q does not follow the previous chunk in %q", i, chunk, markdown)
+		}
+		offset = next + len(chunk)
+
+		if len(chunk) > maxLength {
+			t.Errorf("chunk %d has %d bytes, want at most %d", i, len(chunk), maxLength)
+		}
+	}
+	if rest := markdown[offset:]; strings.TrimSpace(rest) != "" {
+		t.Errorf("Markdown %q is missing from the chunks", rest)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// mirrorIndex is the file name of the Markdown mirror of a URL without a file name, such as "/docs/".
+const mirrorIndex = "index.html.md"
+
+// MirrorPath returns the slash-separated path of the Markdown mirror of rawURL, relative to the root of the site,
+// following the convention of https://llmstxt.org/#proposal: ".md" is appended to the URL path, or "index.html.md"
+// if the URL has no file name. For example, "/docs/intro" is mirrored at "docs/intro.md" and "/docs/" at
+// "docs/index.html.md". The query and fragment of rawURL are ignored.
+func MirrorPath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parse URL: %w", err)
+	}
+
+	// cleaning a rooted path removes any ".." which would escape the root
+	p := path.Clean("/" + u.Path)
+	if p == "/" || strings.HasSuffix(u.Path, "/") {
+		p = path.Join(p, mirrorIndex)
+	} else {
+		p += ".md"
+	}
+
+	return strings.TrimPrefix(p, "/"), nil
+}
+
+// MirrorURL returns the URL of the Markdown mirror of rawURL, served from the same host. See [MirrorPath].
+func MirrorURL(rawURL string) (string, error) {
+	p, err := MirrorPath(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parse URL: %w", err)
+	}
+	u.Path, u.RawPath = "/"+p, ""
+	u.RawQuery, u.Fragment = "", ""
+
+	return u.String(), nil
+}
+
+// WriteMarkdownMirror writes the Markdown of each page of sections to dir, at its [MirrorPath], so that the tree can
+// be deployed alongside the site. It returns the number of files written.
+//
+// Pages without Markdown are skipped. If several pages share a mirror path, such as URLs which only differ by their
+// query, the first one is kept.
+func WriteMarkdownMirror(dir string, sections []Section) (int, error) {
+	logger := slog.Default().WithGroup("mirror")
+
+	written := make(map[string]string) // mirror path to URL
+	for _, section := range sections {
+		for _, page := range section.Pages {
+			content := strings.TrimSpace(page.Markdown)
+			if content == "" {
+				continue
+			}
+
+			p, err := MirrorPath(page.URL)
+			if err != nil {
+				return len(written), fmt.Errorf("mirror %s: %w", page.URL, err)
+			}
+			if first, ok := written[p]; ok {
+				logger.Warn("Skipping page with the same mirror path as another page", "url", page.URL, "path", p, "kept", first)
+				continue
+			}
+
+			name := filepath.Join(dir, filepath.FromSlash(p))
+			if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+				return len(written), fmt.Errorf("create mirror directory: %w", err)
+			}
+			if err := os.WriteFile(name, []byte(content+"\n"), 0644); err != nil {
+				return len(written), fmt.Errorf("write mirror of %s: %w", page.URL, err)
+			}
+			written[p] = page.URL
+		}
+	}
+
+	return len(written), nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMirrorPath(t *testing.T) {
+	tests := map[string]struct {
+		url     string
+		want    string
+		wantURL string
+	}{
+		"page": {
+			url:     "https://example.com/docs/intro",
+			want:    "docs/intro.md",
+			wantURL: "https://example.com/docs/intro.md",
+		},
+		"file name": {
+			url:     "https://example.com/docs/intro.html",
+			want:    "docs/intro.html.md",
+			wantURL: "https://example.com/docs/intro.html.md",
+		},
+		"index page": {
+			url:     "https://example.com/docs/",
+			want:    "docs/index.html.md",
+			wantURL: "https://example.com/docs/index.html.md",
+		},
+		"root": {
+			url:     "https://example.com",
+			want:    "index.html.md",
+			wantURL: "https://example.com/index.html.md",
+		},
+		"query and fragment": {
+			url:     "https://example.com/search?q=go&page=2#results",
+			want:    "search.md",
+			wantURL: "https://example.com/search.md",
+		},
+		"escaped path": {
+			url:     "https://example.com/docs/a%20b",
+			want:    "docs/a b.md",
+			wantURL: "https://example.com/docs/a%20b.md",
+		},
+		"dot dot": {
+			url:     "https://example.com/docs/../../../etc/passwd",
+			want:    "etc/passwd.md",
+			wantURL: "https://example.com/etc/passwd.md",
+		},
+		"escaped dot dot": {
+			url:     "https://example.com/%2e%2e/%2e%2e/secret",
+			want:    "secret.md",
+			wantURL: "https://example.com/secret.md",
+		},
+		"dot dot to root": {
+			url:     "https://example.com/docs/..",
+			want:    "index.html.md",
+			wantURL: "https://example.com/index.html.md",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := MirrorPath(tt.url)
+			if err != nil {
+				t.Fatalf("MirrorPath(%q) error = %v", tt.url, err)
+			}
+			if got != tt.want {
+				t.Errorf("MirrorPath(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+
+			gotURL, err := MirrorURL(tt.url)
+			if err != nil {
+				t.Fatalf("MirrorURL(%q) error = %v", tt.url, err)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("MirrorURL(%q) = %q, want %q", tt.url, gotURL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdownMirror(t *testing.T) {
+	dir := t.TempDir()
+	sections := []Section{
+		{Name: SectionPages, Pages: []ProcessedURL{
+			{URL: "https://example.com/", Markdown: "# Home\n\nWelcome.\n\n"},
+			{URL: "https://example.com/docs/intro", Markdown: "# Intro"},
+			{URL: "https://example.com/docs/empty", Markdown: " \n"},
+		}},
+		{Name: SectionOptional, Pages: []ProcessedURL{
+			{URL: "https://example.com/docs/intro?lang=fr", Markdown: "# Introduction"},
+			{URL: "https://example.com/../../outside", Markdown: "# Outside"},
+		}},
+	}
+
+	n, err := WriteMarkdownMirror(dir, sections)
+	if err != nil {
+		t.Fatalf("WriteMarkdownMirror() error = %v", err)
+	}
+	want := map[string]string{
+		"index.html.md": "# Home\n\nWelcome.\n",
+		"docs/intro.md": "# Intro\n",
+		"outside.md":    "# Outside\n",
+	}
+	if n != len(want) {
+		t.Errorf("WriteMarkdownMirror() = %d, want %d", n, len(want))
+	}
+
+	got := make(map[string]string)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		got[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p, content := range want {
+		if got[p] != content {
+			t.Errorf("WriteMarkdownMirror() wrote %s = %q, want %q", p, got[p], content)
+		}
+	}
+	for p := range got {
+		if _, ok := want[p]; !ok {
+			t.Errorf("WriteMarkdownMirror() wrote unexpected file %s", p)
+		}
+	}
+}
+
+func TestRenderLLMsTxtLinkMirrors(t *testing.T) {
+	sections := []Section{
+		{Name: SectionPages, Pages: []ProcessedURL{
+			{URL: "https://example.com/docs/", Title: "Docs", Markdown: "# Docs"},
+			{URL: "https://example.com/external", Title: "External"},
+		}},
+	}
+
+	got := renderLLMsTxt(Site{Name: "Example"}, sections, true)
+	for _, line := range []string{
+		"- [Docs](https://example.com/docs/index.html.md)",
+		// pages without Markdown have no mirror
+		"- [External](https://example.com/external)",
+	} {
+		if !strings.Contains(got, line+"\n") {
+			t.Errorf("renderLLMsTxt() does not contain %q:\n%s", line, got)
+		}
+	}
+}
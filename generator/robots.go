@@ -0,0 +1,331 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reasons reported in [ExcludedURL.Reason].
+const (
+	ReasonRobotsDisallowed  = "disallowed by robots.txt"
+	ReasonRobotsUnavailable = "robots.txt unavailable"
+)
+
+// robotsFetchTimeout is the timeout for fetching a single robots.txt.
+const robotsFetchTimeout = 30 * time.Second
+
+// CrawlPolicy decides which URLs may be fetched on behalf of the generator and how fast.
+//
+// It enforces the Allow, Disallow and Crawl-delay rules of each host's robots.txt for the configured user agent,
+// and spaces requests to the same host by at least the politeness delay.
+//
+// A nil *CrawlPolicy allows every URL without delay.
+type CrawlPolicy struct {
+	fetcher       *fetcher
+	agent         string
+	respectRobots bool
+	delay         time.Duration
+	logger        *slog.Logger
+
+	mu     sync.Mutex
+	robots map[string]*robotsEntry
+	next   map[string]time.Time
+}
+
+// NewCrawlPolicy creates a new [CrawlPolicy] for userAgent.
+//
+// If respectRobots is false, robots.txt is never fetched and only the politeness delay is applied.
+func NewCrawlPolicy(userAgent string, respectRobots bool, delay time.Duration) *CrawlPolicy {
+	f := newFetcher(userAgent)
+
+	// robots.txt groups are matched against the product token of the User-Agent
+	agent, _, _ := strings.Cut(f.userAgent, "/")
+	agent, _, _ = strings.Cut(agent, " ")
+
+	return &CrawlPolicy{
+		fetcher:       f,
+		agent:         strings.ToLower(agent),
+		respectRobots: respectRobots,
+		delay:         delay,
+		logger:        slog.Default().WithGroup("policy"),
+		robots:        make(map[string]*robotsEntry),
+		next:          make(map[string]time.Time),
+	}
+}
+
+// Allowed reports whether rawURL may be fetched. If not, reason describes why.
+func (p *CrawlPolicy) Allowed(ctx context.Context, rawURL string) (ok bool, reason string) {
+	if p == nil || !p.respectRobots {
+		return true, ""
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return true, ""
+	}
+
+	group, err := p.robotsGroup(ctx, u)
+	if err != nil {
+		return false, ReasonRobotsUnavailable
+	}
+	if !group.allowed(u) {
+		return false, ReasonRobotsDisallowed
+	}
+
+	return true, ""
+}
+
+// Wait blocks until a request to the host of rawURL is allowed by the politeness delay and the robots.txt Crawl-delay.
+func (p *CrawlPolicy) Wait(ctx context.Context, rawURL string) error {
+	if p == nil {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+
+	delay := p.delay
+	if p.respectRobots {
+		if group, err := p.robotsGroup(ctx, u); err == nil {
+			delay = max(delay, group.crawlDelay)
+		}
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	at := p.next[u.Host]
+	if at.Before(now) {
+		at = now
+	}
+	p.next[u.Host] = at.Add(delay)
+	p.mu.Unlock()
+
+	if wait := time.Until(at); wait > 0 {
+		p.logger.DebugContext(ctx, "Waiting for politeness delay", "host", u.Host, "wait", wait)
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil
+}
+
+// robotsEntry is the robots.txt of a single origin, fetched at most once.
+type robotsEntry struct {
+	once  sync.Once
+	group *robotsGroup
+	err   error
+}
+
+// errRobotsUnavailable is returned when robots.txt can't be fetched due to a server or network error,
+// in which case the whole origin must be treated as disallowed.
+//
+// See https://www.rfc-editor.org/rfc/rfc9309.html#section-2.3.1.4.
+var errRobotsUnavailable = errors.New("robots.txt unavailable")
+
+func (p *CrawlPolicy) robotsGroup(ctx context.Context, u *url.URL) (*robotsGroup, error) {
+	origin := &url.URL{Scheme: u.Scheme, Host: u.Host}
+
+	p.mu.Lock()
+	entry, ok := p.robots[origin.String()]
+	if !ok {
+		entry = &robotsEntry{}
+		p.robots[origin.String()] = entry
+	}
+	p.mu.Unlock()
+
+	entry.once.Do(func() {
+		// the result is shared by all callers, so it must not depend on the cancellation of the first caller
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), robotsFetchTimeout)
+		defer cancel()
+
+		robotsURL := origin.JoinPath("robots.txt").String()
+		page, err := p.fetcher.fetch(ctx, robotsURL, "text/plain")
+		if err != nil {
+			var statusErr *httpStatusError
+			if errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusBadRequest && statusErr.StatusCode < http.StatusInternalServerError {
+				// a missing robots.txt allows everything
+				entry.group = &robotsGroup{}
+				return
+			}
+			p.logger.WarnContext(ctx, "Failed to fetch robots.txt, disallowing the origin", "url", robotsURL, "error", err)
+			entry.err = errRobotsUnavailable
+			return
+		}
+
+		entry.group = parseRobots(page.body, p.agent)
+		p.logger.DebugContext(ctx, "Loaded robots.txt", "url", robotsURL, "rules", len(entry.group.rules), "crawl_delay", entry.group.crawlDelay)
+	})
+
+	return entry.group, entry.err
+}
+
+// robotsGroup is the set of robots.txt rules which apply to a single user agent.
+type robotsGroup struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// allowed reports whether u is allowed by the most specific (longest) matching rule, preferring Allow on ties.
+func (g *robotsGroup) allowed(u *url.URL) bool {
+	target := u.EscapedPath()
+	if target == "" {
+		target = "/"
+	}
+	if target == "/robots.txt" {
+		return true
+	}
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+
+	allow, matched := true, -1
+	for _, rule := range g.rules {
+		if !rule.re.MatchString(target) {
+			continue
+		}
+		if n := len(rule.pattern); n > matched || (n == matched && rule.allow) {
+			allow, matched = rule.allow, n
+		}
+	}
+
+	return allow
+}
+
+// parseRobots parses the robots.txt body and returns the rules of the groups naming agent, or the rules of the "*"
+// group if no group does.
+//
+// Agent names are matched exactly and case-insensitively, as specified by
+// https://www.rfc-editor.org/rfc/rfc9309.html#section-2.2.1.
+func parseRobots(body, agent string) *robotsGroup {
+	type group struct {
+		agents []string
+		robotsGroup
+	}
+
+	var groups []*group
+	var current *group
+	inAgents := false
+
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(val))
+			inAgents = true
+			continue
+
+		case "allow", "disallow":
+			if current != nil && val != "" {
+				current.rules = append(current.rules, newRobotsRule(key == "allow", val))
+			}
+
+		case "crawl-delay":
+			if current != nil {
+				if secs, err := strconv.ParseFloat(val, 64); err == nil && secs >= 0 {
+					current.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+		inAgents = false
+	}
+
+	// all groups naming the agent are merged
+	best := "*"
+	for _, g := range groups {
+		if slices.Contains(g.agents, agent) {
+			best = agent
+			break
+		}
+	}
+
+	result := &robotsGroup{}
+	for _, g := range groups {
+		for _, name := range g.agents {
+			if name != best {
+				continue
+			}
+			result.rules = append(result.rules, g.rules...)
+			result.crawlDelay = max(result.crawlDelay, g.crawlDelay)
+			break
+		}
+	}
+
+	return result
+}
+
+// newRobotsRule compiles a robots.txt path pattern, where "*" matches any sequence of characters and a trailing "$"
+// anchors the end of the path.
+func newRobotsRule(allow bool, pattern string) robotsRule {
+	expr := pattern
+	anchored := strings.HasSuffix(expr, "$")
+	expr = strings.TrimSuffix(expr, "$")
+
+	parts := strings.Split(expr, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr = "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return robotsRule{
+		allow:   allow,
+		pattern: pattern,
+		re:      regexp.MustCompile(expr),
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRobots(t *testing.T) {
+	const robots = `# comment
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Disallow: /search?
+Crawl-delay: 2
+
+User-agent: otherbot
+Disallow: /
+
+User-agent: llmstxt-generator
+User-agent: anotherbot
+Disallow: /drafts # inline comment
+Allow: /drafts/published
+Crawl-delay: 0.5
+
+user-agent: LLMSTXT-GENERATOR
+disallow: /tmp
+`
+
+	tests := map[string]struct {
+		agent          string
+		path           string
+		want           bool
+		wantCrawlDelay time.Duration
+	}{
+		"default group allows": {
+			agent: "somebot", path: "/docs", want: true, wantCrawlDelay: 2 * time.Second,
+		},
+		"default group disallows": {
+			agent: "somebot", path: "/private/page", want: false, wantCrawlDelay: 2 * time.Second,
+		},
+		"longest rule wins": {
+			agent: "somebot", path: "/private/public/page", want: true, wantCrawlDelay: 2 * time.Second,
+		},
+		"wildcard and anchor": {
+			agent: "somebot", path: "/files/manual.pdf", want: false, wantCrawlDelay: 2 * time.Second,
+		},
+		"anchor does not match a longer path": {
+			agent: "somebot", path: "/files/manual.pdf.html", want: true, wantCrawlDelay: 2 * time.Second,
+		},
+		"query": {
+			agent: "somebot", path: "/search?q=go", want: false, wantCrawlDelay: 2 * time.Second,
+		},
+		"robots.txt is always allowed": {
+			agent: "otherbot", path: "/robots.txt", want: true,
+		},
+		"agent group": {
+			agent: "otherbot", path: "/docs", want: false,
+		},
+		"agent group overrides default group": {
+			agent: "llmstxt-generator", path: "/private/page", want: true, wantCrawlDelay: 500 * time.Millisecond,
+		},
+		"agent group disallows": {
+			agent: "llmstxt-generator", path: "/drafts/wip", want: false, wantCrawlDelay: 500 * time.Millisecond,
+		},
+		"agent group allows": {
+			agent: "llmstxt-generator", path: "/drafts/published/page", want: true, wantCrawlDelay: 500 * time.Millisecond,
+		},
+		"agent groups are merged case-insensitively": {
+			agent: "llmstxt-generator", path: "/tmp/file", want: false, wantCrawlDelay: 500 * time.Millisecond,
+		},
+		"agent names are matched exactly": {
+			agent: "llmstxt", path: "/private/page", want: false, wantCrawlDelay: 2 * time.Second,
+		},
+		"agent names are not prefixes": {
+			agent: "llmstxt-generator-beta", path: "/drafts/wip", want: true, wantCrawlDelay: 2 * time.Second,
+		},
+		"group of several agents": {
+			agent: "anotherbot", path: "/drafts/wip", want: false, wantCrawlDelay: 500 * time.Millisecond,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			group := parseRobots(robots, tt.agent)
+			if got := group.allowed(mustParseURL(t, "https://example.com"+tt.path)); got != tt.want {
+				t.Errorf("allowed(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+			if group.crawlDelay != tt.wantCrawlDelay {
+				t.Errorf("crawlDelay = %v, want %v", group.crawlDelay, tt.wantCrawlDelay)
+			}
+		})
+	}
+}
+
+func TestParseRobotsAllowTie(t *testing.T) {
+	group := parseRobots("User-agent: *\nDisallow: /page\nAllow: /page\n", "somebot")
+	if !group.allowed(mustParseURL(t, "https://example.com/page")) {
+		t.Error("allowed() = false, want Allow to win a tie with Disallow")
+	}
+}
+
+func TestCrawlPolicyAllowed(t *testing.T) {
+	tests := map[string]struct {
+		robots        testPage
+		respectRobots bool
+		path          string
+		want          bool
+		wantReason    string
+	}{
+		"allowed": {
+			robots:        testPage{contentType: "text/plain", body: "User-agent: *\nDisallow: /private\n"},
+			respectRobots: true,
+			path:          "/docs",
+			want:          true,
+		},
+		"disallowed": {
+			robots:        testPage{contentType: "text/plain", body: "User-agent: *\nDisallow: /private\n"},
+			respectRobots: true,
+			path:          "/private",
+			wantReason:    ReasonRobotsDisallowed,
+		},
+		"disallowed for the user agent": {
+			robots:        testPage{contentType: "text/plain", body: "User-agent: llmstxt-generator\nDisallow: /\n"},
+			respectRobots: true,
+			path:          "/docs",
+			wantReason:    ReasonRobotsDisallowed,
+		},
+		"missing robots.txt": {
+			robots:        testPage{status: http.StatusNotFound},
+			respectRobots: true,
+			path:          "/private",
+			want:          true,
+		},
+		"unavailable robots.txt": {
+			robots:        testPage{status: http.StatusServiceUnavailable},
+			respectRobots: true,
+			path:          "/docs",
+			wantReason:    ReasonRobotsUnavailable,
+		},
+		"robots ignored": {
+			robots: testPage{contentType: "text/plain", body: "User-agent: *\nDisallow: /\n"},
+			path:   "/docs",
+			want:   true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			site := newTestSite(t, map[string]testPage{"/robots.txt": tt.robots})
+
+			p := NewCrawlPolicy("", tt.respectRobots, 0)
+			got, reason := p.Allowed(t.Context(), site.URL+tt.path)
+			if got != tt.want || reason != tt.wantReason {
+				t.Errorf("Allowed() = %v, %q, want %v, %q", got, reason, tt.want, tt.wantReason)
+			}
+			if !tt.respectRobots && site.hit("/robots.txt") {
+				t.Error("Allowed() fetched robots.txt, want no fetch when robots.txt is ignored")
+			}
+		})
+	}
+}
+
+func TestCrawlPolicyFetchesRobotsOnce(t *testing.T) {
+	site := newTestSite(t, map[string]testPage{
+		"/robots.txt": {contentType: "text/plain", body: "User-agent: *\nDisallow: /private\n"},
+	})
+
+	p := NewCrawlPolicy("", true, 0)
+	for _, path := range []string{"/a", "/b", "/private"} {
+		p.Allowed(t.Context(), site.URL+path)
+	}
+
+	if n := site.count("/robots.txt"); n != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1", n)
+	}
+}
+
+func TestCrawlPolicyWait(t *testing.T) {
+	const delay = 50 * time.Millisecond
+
+	tests := map[string]struct {
+		robots        string
+		respectRobots bool
+		delay         time.Duration
+		wantMin       time.Duration
+		wantMax       time.Duration
+	}{
+		"no delay": {
+			wantMax: delay,
+		},
+		"politeness delay": {
+			delay:   delay,
+			wantMin: 2 * delay,
+		},
+		"crawl-delay": {
+			robots:        "User-agent: *\nCrawl-delay: 0.05\n",
+			respectRobots: true,
+			wantMin:       2 * delay,
+		},
+		"crawl-delay ignored": {
+			robots:  "User-agent: *\nCrawl-delay: 10\n",
+			wantMax: delay,
+		},
+		"crawl-delay longer than the politeness delay": {
+			robots:        "User-agent: *\nCrawl-delay: 0.1\n",
+			respectRobots: true,
+			delay:         delay,
+			wantMin:       4 * delay,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			site := newTestSite(t, map[string]testPage{
+				"/robots.txt": {contentType: "text/plain", body: tt.robots},
+			})
+			p := NewCrawlPolicy("", tt.respectRobots, tt.delay)
+			// load robots.txt before measuring
+			p.Allowed(t.Context(), site.URL+"/")
+
+			start := time.Now()
+			for range 3 {
+				if err := p.Wait(t.Context(), site.URL+"/page"); err != nil {
+					t.Fatalf("Wait() error = %v", err)
+				}
+			}
+			elapsed := time.Since(start)
+
+			if elapsed < tt.wantMin {
+				t.Errorf("3 Wait() took %v, want at least %v", elapsed, tt.wantMin)
+			}
+			if tt.wantMax > 0 && elapsed > tt.wantMax {
+				t.Errorf("3 Wait() took %v, want at most %v", elapsed, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestCrawlPolicyWaitCanceled(t *testing.T) {
+	p := NewCrawlPolicy("", false, time.Hour)
+	if err := p.Wait(t.Context(), "https://example.com/a"); err != nil {
+		t.Fatalf("first Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Wait(ctx, "https://example.com/b"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestNilCrawlPolicy(t *testing.T) {
+	var p *CrawlPolicy
+	if ok, reason := p.Allowed(t.Context(), "https://example.com/"); !ok {
+		t.Errorf("Allowed() = %v, %q, want true", ok, reason)
+	}
+	if err := p.Wait(t.Context(), "https://example.com/"); err != nil {
+		t.Errorf("Wait() error = %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/zchee/llmstxt-generator/gollm"
+)
+
+// Section grouping modes of [GenerationOptions.SectionMode].
+const (
+	// SectionsByPath groups pages into sections by their URL path prefix.
+	SectionsByPath = "path"
+	// SectionsByLLM groups pages by their URL path prefix first, then asks the summarizer to assign each page
+	// a category of the taxonomy.
+	SectionsByLLM = "llm"
+)
+
+// Names of the H2 sections which are not derived from URLs or the taxonomy.
+const (
+	// SectionPages is the section of the pages which don't belong to any other section.
+	SectionPages = "Pages"
+	// SectionOptional is the section of the secondary pages, which may be skipped when a shorter context is needed.
+	SectionOptional = "Optional"
+)
+
+const (
+	// defaultSectionDepth is the maximum number of path segments of a section prefix if [GenerationOptions.SectionDepth] is zero.
+	defaultSectionDepth = 2
+
+	// minSectionSize is the minimum number of pages under a path prefix to form a section.
+	minSectionSize = 2
+
+	// maxTaxonomySize is the maximum number of categories proposed by the summarizer.
+	maxTaxonomySize = 10
+
+	// maxClassifyContentLength is the maximum length of the page content sent to classify a page.
+	maxClassifyContentLength = 2000
+)
+
+// Section is an H2 section of llms.txt.
+type Section struct {
+	Name  string         `json:"name"`
+	Pages []ProcessedURL `json:"pages"`
+}
+
+// groupSections sets the section of each of results by its URL path and returns the sections.
+//
+// A page belongs to the deepest path prefix of up to depth segments which is shared by at least two pages, including
+// the page itself. For example, "/tutorials/python/1" and "/tutorials/python/2" are grouped into "Tutorials / Python".
+// Pages without such a prefix belong to [SectionPages] and pages matched by isOptional to [SectionOptional].
+//
+// [SectionPages] comes first, followed by the path sections in the natural order of their prefixes.
+func groupSections(results []ProcessedURL, depth int, isOptional func(ProcessedURL) bool) []Section {
+	segments := make([][]string, len(results))
+	counts := make(map[string]int)
+	for i, result := range results {
+		segments[i] = pathSegments(result.URL)
+		for d := 1; d <= min(depth, len(segments[i])); d++ {
+			counts[strings.Join(segments[i][:d], "/")]++
+		}
+	}
+
+	prefixes := make(map[string]string) // section name to path prefix
+	for i := range results {
+		result := &results[i]
+		if isOptional(*result) {
+			result.Section = SectionOptional
+			continue
+		}
+
+		result.Section = SectionPages
+		for d := min(depth, len(segments[i])); d >= 1; d-- {
+			prefix := strings.Join(segments[i][:d], "/")
+			if counts[prefix] >= minSectionSize {
+				result.Section = sectionName(segments[i][:d])
+				prefixes[result.Section] = prefix
+				break
+			}
+		}
+	}
+
+	order := slices.SortedFunc(maps.Keys(prefixes), func(a, b string) int {
+		return naturalCompare(prefixes[a], prefixes[b])
+	})
+
+	return buildSections(results, append([]string{SectionPages}, order...))
+}
+
+// buildSections groups results by their section, in order of the section names followed by the sections not in order
+// by their first page. [SectionOptional] is always the last section.
+//
+// Pages within a section are sorted by the natural order of their URL paths, so that numbered pages such as
+// tutorial steps keep their sequence and index pages precede their children.
+func buildSections(results []ProcessedURL, order []string) []Section {
+	var sections []Section
+	index := make(map[string]int)
+	add := func(name string) {
+		if _, ok := index[name]; !ok && name != SectionOptional {
+			index[name] = len(sections)
+			sections = append(sections, Section{Name: name})
+		}
+	}
+	for _, name := range order {
+		add(name)
+	}
+	for _, result := range results {
+		add(cmp.Or(result.Section, SectionPages))
+	}
+	index[SectionOptional] = len(sections)
+	sections = append(sections, Section{Name: SectionOptional})
+
+	for _, result := range results {
+		i := index[cmp.Or(result.Section, SectionPages)]
+		sections[i].Pages = append(sections[i].Pages, result)
+	}
+	for _, section := range sections {
+		slices.SortStableFunc(section.Pages, func(a, b ProcessedURL) int {
+			return naturalCompare(urlPath(a.URL), urlPath(b.URL))
+		})
+	}
+
+	return slices.DeleteFunc(sections, func(section Section) bool {
+		return len(section.Pages) == 0
+	})
+}
+
+// isOptional reports whether the page belongs to [SectionOptional] by its sitemap priority or the optional patterns.
+func (g *LLMsTxtGenerator) isOptional(filter *URLFilter) func(ProcessedURL) bool {
+	return func(result ProcessedURL) bool {
+		if result.Priority > 0 && result.Priority < g.options.OptionalPriority {
+			return true
+		}
+		if filter.Empty() {
+			return false
+		}
+		kept, _ := filter.Apply([]string{result.URL})
+		return len(kept) > 0
+	}
+}
+
+// classifySections asks the summarizer to assign each page of results, except the optional ones, a category of the
+// taxonomy and regroups results by category in the order of the taxonomy.
+//
+// If [GenerationOptions.Taxonomy] is empty, the summarizer proposes the taxonomy from the pages and their path
+// sections first. Pages which fail to classify keep their path section.
+func (g *LLMsTxtGenerator) classifySections(ctx context.Context, results []ProcessedURL, sections []Section, logger *slog.Logger) []Section {
+	taxonomy := g.options.Taxonomy
+	if len(taxonomy) == 0 {
+		var err error
+		taxonomy, err = g.proposeTaxonomy(ctx, sections)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to propose taxonomy, keeping path sections", "error", err)
+			return sections
+		}
+		logger.InfoContext(ctx, "Proposed taxonomy", "categories", taxonomy)
+	}
+
+	var eg errgroup.Group
+	eg.SetLimit(g.options.MaxWorkers)
+	for i := range results {
+		if results[i].Section == SectionOptional {
+			continue
+		}
+		eg.Go(func() error {
+			category, err := g.classifyPage(ctx, results[i], taxonomy)
+			if err != nil {
+				logger.WarnContext(ctx, "Failed to classify page, keeping path section", "url", results[i].URL, "section", results[i].Section, "error", err)
+				return nil
+			}
+			results[i].Section = category
+			return nil
+		})
+	}
+	eg.Wait()
+
+	return buildSections(results, taxonomy)
+}
+
+// proposeTaxonomy asks the summarizer for the categories of the pages in sections.
+func (g *LLMsTxtGenerator) proposeTaxonomy(ctx context.Context, sections []Section) ([]string, error) {
+	var sb strings.Builder
+	for _, section := range sections {
+		if section.Name == SectionOptional {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:\n", section.Name)
+		for _, page := range section.Pages {
+			fmt.Fprintf(&sb, "- %s (%s): %s\n", page.Title, page.URL, page.Description)
+		}
+	}
+
+	prompt := gollm.Prompt{
+		System: g.SystemPrompt(),
+		User:   fmt.Sprintf(taxonomyPromptFmt, maxTaxonomySize),
+	}
+	// the summarizer returns the categories separated by "|" in the title
+	categories, _, err := g.summarizer.SummarizeContent(ctx, prompt, sb.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var taxonomy []string
+	for category := range strings.SplitSeq(categories, "|") {
+		category = singleLine(category)
+		if category == "" || strings.EqualFold(category, SectionOptional) || slices.Contains(taxonomy, category) {
+			continue
+		}
+		taxonomy = append(taxonomy, category)
+	}
+	if len(taxonomy) < 2 {
+		return nil, fmt.Errorf("too few categories proposed: %q", categories)
+	}
+
+	return taxonomy[:min(len(taxonomy), maxTaxonomySize)], nil
+}
+
+// classifyPage asks the summarizer for the category of the taxonomy which page belongs to.
+func (g *LLMsTxtGenerator) classifyPage(ctx context.Context, page ProcessedURL, taxonomy []string) (string, error) {
+	content := page.Markdown
+	if len(content) > maxClassifyContentLength {
+		content = content[:maxClassifyContentLength]
+	}
+
+	prompt := gollm.Prompt{
+		System: g.SystemPrompt(),
+		User:   fmt.Sprintf(classifyPromptFmt, "- "+strings.Join(taxonomy, "\n- ")),
+		Suffix: fmt.Sprintf(classifySuffixFmt, page.URL, page.Title, page.Description),
+	}
+	category, _, err := g.summarizer.SummarizeContent(ctx, prompt, content)
+	if err != nil {
+		return "", err
+	}
+
+	category = singleLine(category)
+	for _, c := range taxonomy {
+		if strings.EqualFold(c, category) {
+			return c, nil
+		}
+	}
+
+	return "", fmt.Errorf("category %q is not in the taxonomy", category)
+}
+
+// pathSegments returns the non-empty path segments of rawURL.
+func pathSegments(rawURL string) []string {
+	return strings.FieldsFunc(urlPath(rawURL), func(r rune) bool {
+		return r == '/'
+	})
+}
+
+func urlPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Path
+}
+
+// sectionName converts path segments such as ["tutorials", "getting-started"] to a section name such as
+// "Tutorials / Getting Started".
+func sectionName(segments []string) string {
+	names := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		segment, _ = url.PathUnescape(segment)
+		words := strings.FieldsFunc(segment, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.' || r == ' '
+		})
+		for i, w := range words {
+			r, size := utf8.DecodeRuneInString(w)
+			words[i] = string(unicode.ToUpper(r)) + w[size:]
+		}
+		if name := strings.Join(words, " "); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return cmp.Or(strings.Join(names, " / "), SectionPages)
+}
+
+// naturalCompare compares a and b as strings, except that runs of digits are compared by their numeric value,
+// so "step-2" sorts before "step-10".
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		var chunkA, chunkB string
+		chunkA, a = nextNaturalChunk(a)
+		chunkB, b = nextNaturalChunk(b)
+
+		var c int
+		if isASCIIDigit(chunkA[0]) && isASCIIDigit(chunkB[0]) {
+			numA, numB := strings.TrimLeft(chunkA, "0"), strings.TrimLeft(chunkB, "0")
+			c = cmp.Or(cmp.Compare(len(numA), len(numB)), strings.Compare(numA, numB))
+		} else {
+			c = strings.Compare(chunkA, chunkB)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(a), len(b))
+}
+
+// nextNaturalChunk splits s into its leading run of digits or non-digits and the rest.
+func nextNaturalChunk(s string) (chunk, rest string) {
+	digit := isASCIIDigit(s[0])
+	i := 1
+	for i < len(s) && isASCIIDigit(s[i]) == digit {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGroupSections(t *testing.T) {
+	const base = "https://example.com"
+	tests := map[string]struct {
+		paths    []string
+		depth    int
+		optional []string
+		want     map[string][]string // section name to page paths
+		order    []string
+	}{
+		"path prefixes": {
+			paths: []string{
+				"/tutorials/python/10", "/docs/usage", "/", "/tutorials/python/2", "/about",
+				"/tutorials/go/1", "/docs/intro", "/tutorials/python/1",
+			},
+			depth: 2,
+			want: map[string][]string{
+				SectionPages:         {"/", "/about"},
+				"Docs":               {"/docs/intro", "/docs/usage"},
+				"Tutorials":          {"/tutorials/go/1"},
+				"Tutorials / Python": {"/tutorials/python/1", "/tutorials/python/2", "/tutorials/python/10"},
+			},
+			order: []string{SectionPages, "Docs", "Tutorials", "Tutorials / Python"},
+		},
+		"single page prefixes fall back to pages": {
+			paths: []string{"/docs/intro", "/blog/hello-world", "/api/v1/users"},
+			depth: 2,
+			want: map[string][]string{
+				SectionPages: {"/api/v1/users", "/blog/hello-world", "/docs/intro"},
+			},
+			order: []string{SectionPages},
+		},
+		"depth": {
+			paths: []string{"/tutorials/python/1", "/tutorials/python/2", "/getting_started/install.html", "/getting_started/usage.html"},
+			depth: 1,
+			want: map[string][]string{
+				"Getting Started": {"/getting_started/install.html", "/getting_started/usage.html"},
+				"Tutorials":       {"/tutorials/python/1", "/tutorials/python/2"},
+			},
+			order: []string{"Getting Started", "Tutorials"},
+		},
+		"optional": {
+			paths:    []string{"/changelog", "/docs/intro", "/docs/usage", "/docs/legacy", "/"},
+			depth:    2,
+			optional: []string{"/changelog", "/docs/legacy"},
+			want: map[string][]string{
+				SectionPages:    {"/"},
+				"Docs":          {"/docs/intro", "/docs/usage"},
+				SectionOptional: {"/changelog", "/docs/legacy"},
+			},
+			order: []string{SectionPages, "Docs", SectionOptional},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			isOptional := func(result ProcessedURL) bool {
+				return slices.Contains(tt.optional, strings.TrimPrefix(result.URL, base))
+			}
+
+			var want []Section
+			for _, name := range tt.order {
+				section := Section{Name: name}
+				for _, p := range tt.want[name] {
+					section.Pages = append(section.Pages, ProcessedURL{URL: base + p, Section: name})
+				}
+				want = append(want, section)
+			}
+
+			// the sections don't depend on the order of the pages
+			reversed := slices.Clone(tt.paths)
+			slices.Reverse(reversed)
+			for _, paths := range [][]string{tt.paths, reversed} {
+				var results []ProcessedURL
+				for _, p := range paths {
+					results = append(results, ProcessedURL{URL: base + p})
+				}
+
+				got := groupSections(results, tt.depth, isOptional)
+				if !sectionsEqual(got, want) {
+					t.Errorf("groupSections(%q) =\n%s\nwant:\n%s", paths, sprintSections(got), sprintSections(want))
+				}
+				for _, result := range results {
+					if !slices.Contains(tt.want[result.Section], strings.TrimPrefix(result.URL, base)) {
+						t.Errorf("groupSections() set the section of %s to %q", result.URL, result.Section)
+					}
+				}
+			}
+		})
+	}
+}
+
+func sectionsEqual(a, b []Section) bool {
+	return slices.EqualFunc(a, b, func(a, b Section) bool {
+		return a.Name == b.Name && slices.EqualFunc(a.Pages, b.Pages, func(a, b ProcessedURL) bool {
+			return a.URL == b.URL && a.Section == b.Section
+		})
+	})
+}
+
+func sprintSections(sections []Section) string {
+	var sb strings.Builder
+	for _, section := range sections {
+		sb.WriteString(section.Name)
+		sb.WriteString(":")
+		for _, page := range section.Pages {
+			sb.WriteString(" ")
+			sb.WriteString(page.URL)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
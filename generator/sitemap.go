@@ -0,0 +1,354 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metadata keys of [ScrapedData.Metadata] populated from sitemap entries.
+const (
+	metadataLastModified = "lastmod"
+	metadataPriority     = "priority"
+)
+
+const (
+	// defaultSitemapPriority is the priority of a sitemap URL entry without <priority>, as defined by the sitemaps protocol.
+	defaultSitemapPriority = 0.5
+
+	// maxSitemapDepth is the maximum nesting level of sitemap index files.
+	maxSitemapDepth = 3
+
+	// maxSitemapFiles is the maximum number of sitemap files fetched for a single website.
+	maxSitemapFiles = 100
+)
+
+// sitemapEntry is a page URL discovered from a sitemap.
+type sitemapEntry struct {
+	URL          string
+	LastModified time.Time
+	Priority     float64
+}
+
+type sitemapClient struct {
+	FirecrawlClient
+
+	fetcher *fetcher
+	logger  *slog.Logger
+
+	mu      sync.RWMutex
+	entries map[string]sitemapEntry
+}
+
+var _ FirecrawlClient = (*sitemapClient)(nil)
+
+// NewSitemapClient wraps client so that MapWebsite discovers URLs from the website's sitemaps instead of
+// calling client, while ScrapeURL is delegated to client.
+//
+// Sitemaps are discovered from the Sitemap directives of robots.txt, falling back to /sitemap.xml and
+// /sitemap_index.xml. Sitemap index files and gzip-compressed sitemaps are followed. If the website has no
+// usable sitemap, MapWebsite falls back to client.
+func NewSitemapClient(client FirecrawlClient, userAgent string) FirecrawlClient {
+	return &sitemapClient{
+		FirecrawlClient: client,
+		fetcher:         newFetcher(userAgent),
+		logger:          slog.Default().WithGroup("sitemap"),
+		entries:         make(map[string]sitemapEntry),
+	}
+}
+
+// MapWebsite returns the URLs listed in the website's sitemaps, ordered by descending priority and then by the most recent lastmod.
+//
+// MapWebsite implements [FirecrawlClient].
+func (s *sitemapClient) MapWebsite(ctx context.Context, rawURL string, limit int, options FirecrawlOptions) ([]string, error) {
+	s.logger.InfoContext(ctx, "Discovering sitemaps", "url", rawURL, "limit", limit)
+
+	start, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse URL: %w", err)
+	}
+
+	entries, err := s.discover(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+
+	entries = slices.DeleteFunc(entries, func(e sitemapEntry) bool {
+		u, err := url.Parse(e.URL)
+		return err != nil || !sameSite(start, u, options.IncludeSubdomains)
+	})
+	if len(entries) == 0 {
+		s.logger.InfoContext(ctx, "No sitemap entries found, falling back to map", "url", rawURL)
+		return s.FirecrawlClient.MapWebsite(ctx, rawURL, limit, options)
+	}
+
+	slices.SortStableFunc(entries, func(a, b sitemapEntry) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return b.LastModified.Compare(a.LastModified)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	urls := make([]string, len(entries))
+	s.mu.Lock()
+	for i, e := range entries {
+		urls[i] = e.URL
+		s.entries[e.URL] = e
+	}
+	s.mu.Unlock()
+
+	s.logger.InfoContext(ctx, "Found URLs", "count", len(urls))
+	return urls, nil
+}
+
+// ScrapeURL delegates to the wrapped client and adds the lastmod and priority of the sitemap entry to the metadata.
+//
+// ScrapeURL implements [FirecrawlClient].
+func (s *sitemapClient) ScrapeURL(ctx context.Context, rawURL string, options FirecrawlOptions) (*ScrapedData, error) {
+	data, err := s.FirecrawlClient.ScrapeURL(ctx, rawURL, options)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	entry, ok := s.entries[rawURL]
+	s.mu.RUnlock()
+	if !ok {
+		return data, nil
+	}
+
+	if data.Metadata == nil {
+		data.Metadata = make(map[string]string)
+	}
+	if !entry.LastModified.IsZero() {
+		data.Metadata[metadataLastModified] = entry.LastModified.Format(time.RFC3339)
+	}
+	data.Metadata[metadataPriority] = strconv.FormatFloat(entry.Priority, 'f', -1, 64)
+
+	return data, nil
+}
+
+// sitemapLookup is implemented by the clients which discover the URLs from sitemaps.
+type sitemapLookup interface {
+	sitemapEntry(rawURL string) (sitemapEntry, bool)
+}
+
+var (
+	_ sitemapLookup = (*sitemapClient)(nil)
+	_ stateMapper   = (*sitemapClient)(nil)
+	_ creditCoster  = (*sitemapClient)(nil)
+)
+
+// credits implements [creditCoster] with the credits of the wrapped client, which scrapes the pages and maps the
+// websites without sitemaps.
+func (s *sitemapClient) credits() int {
+	return creditsOf(s.FirecrawlClient)
+}
+
+// needsMap implements [stateMapper]; ScrapeURL adds the lastmod and priority of the sitemap entries found by MapWebsite.
+func (s *sitemapClient) needsMap() {}
+
+// sitemapEntry returns the entry of rawURL found by MapWebsite, if any.
+func (s *sitemapClient) sitemapEntry(rawURL string) (sitemapEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[rawURL]
+	return entry, ok
+}
+
+// discover returns all page entries of the sitemaps of the website at site, in document order without duplicates.
+func (s *sitemapClient) discover(ctx context.Context, site *url.URL) ([]sitemapEntry, error) {
+	origin := &url.URL{Scheme: site.Scheme, Host: site.Host}
+
+	sitemaps, err := s.robotsSitemaps(ctx, origin)
+	if err != nil {
+		return nil, err
+	}
+	if len(sitemaps) == 0 {
+		sitemaps = []string{
+			origin.JoinPath("sitemap.xml").String(),
+			origin.JoinPath("sitemap_index.xml").String(),
+		}
+	}
+
+	var entries []sitemapEntry
+	seenEntries := make(map[string]bool)
+	seenSitemaps := make(map[string]bool)
+
+	var walk func(sitemapURL string, depth int) error
+	walk = func(sitemapURL string, depth int) error {
+		if seenSitemaps[sitemapURL] || len(seenSitemaps) >= maxSitemapFiles {
+			return nil
+		}
+		seenSitemaps[sitemapURL] = true
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := s.fetcher.fetch(ctx, sitemapURL, "application/xml,text/xml;q=0.9,*/*;q=0.8")
+		if err != nil {
+			s.logger.DebugContext(ctx, "Skipping unreachable sitemap", "url", sitemapURL, "error", err)
+			return nil
+		}
+
+		sm, err := parseSitemap(page.body)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to parse sitemap", "url", sitemapURL, "error", err)
+			return nil
+		}
+		s.logger.DebugContext(ctx, "Parsed sitemap", "url", sitemapURL, "urls", len(sm.URLs), "sitemaps", len(sm.Sitemaps))
+
+		for _, u := range sm.URLs {
+			loc := strings.TrimSpace(u.Loc)
+			if loc == "" || seenEntries[loc] {
+				continue
+			}
+			seenEntries[loc] = true
+
+			entry := sitemapEntry{
+				URL:          loc,
+				LastModified: parseLastModified(u.LastMod),
+				Priority:     defaultSitemapPriority,
+			}
+			if p, err := strconv.ParseFloat(strings.TrimSpace(u.Priority), 64); err == nil && p >= 0 && p <= 1 {
+				entry.Priority = p
+			}
+			entries = append(entries, entry)
+		}
+
+		if depth >= maxSitemapDepth {
+			return nil
+		}
+		for _, child := range sm.Sitemaps {
+			if loc := strings.TrimSpace(child.Loc); loc != "" {
+				if err := walk(loc, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	for _, sitemapURL := range sitemaps {
+		if err := walk(sitemapURL, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// robotsSitemaps returns the Sitemap directives of the robots.txt at origin.
+func (s *sitemapClient) robotsSitemaps(ctx context.Context, origin *url.URL) ([]string, error) {
+	page, err := s.fetcher.fetch(ctx, origin.JoinPath("robots.txt").String(), "text/plain")
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		s.logger.DebugContext(ctx, "No robots.txt", "url", origin.String(), "error", err)
+		return nil, nil
+	}
+
+	var sitemaps []string
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(strings.NewReader(page.body))
+	for sc.Scan() {
+		key, val, ok := strings.Cut(sc.Text(), ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "sitemap") {
+			continue
+		}
+		val, _, _ = strings.Cut(val, "#")
+		val = strings.TrimSpace(val)
+		if u, err := origin.Parse(val); err == nil && val != "" && !seen[u.String()] {
+			seen[u.String()] = true
+			sitemaps = append(sitemaps, u.String())
+		}
+	}
+
+	return sitemaps, nil
+}
+
+// sitemapXML is either a <urlset> or a <sitemapindex> document of the sitemaps protocol.
+//
+// See https://www.sitemaps.org/protocol.html.
+type sitemapXML struct {
+	XMLName  xml.Name
+	URLs     []sitemapURL `xml:"url"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+type sitemapURL struct {
+	Loc      string `xml:"loc"`
+	LastMod  string `xml:"lastmod"`
+	Priority string `xml:"priority"`
+}
+
+func parseSitemap(body string) (*sitemapXML, error) {
+	var sm sitemapXML
+	if err := xml.Unmarshal([]byte(body), &sm); err != nil {
+		return nil, fmt.Errorf("unmarshal sitemap: %w", err)
+	}
+
+	switch sm.XMLName.Local {
+	case "urlset", "sitemapindex":
+		return &sm, nil
+	default:
+		return nil, fmt.Errorf("unexpected sitemap root element <%s>", sm.XMLName.Local)
+	}
+}
+
+// lastModifiedLayouts are the W3C Datetime formats allowed in <lastmod>.
+var lastModifiedLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	time.DateOnly,
+	"2006-01",
+	"2006",
+}
+
+func parseLastModified(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range lastModifiedLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package generator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeFirecrawlClient is a [FirecrawlClient] returning fixed URLs and pages.
+type fakeFirecrawlClient struct {
+	urls   []string
+	mapped bool
+}
+
+func (c *fakeFirecrawlClient) MapWebsite(ctx context.Context, rawURL string, limit int, options FirecrawlOptions) ([]string, error) {
+	c.mapped = true
+	return c.urls, nil
+}
+
+func (c *fakeFirecrawlClient) ScrapeURL(ctx context.Context, rawURL string, options FirecrawlOptions) (*ScrapedData, error) {
+	return &ScrapedData{URL: rawURL, Markdown: "# Page"}, nil
+}
+
+func urlset(entries ...string) testPage {
+	return testPage{
+		contentType: "application/xml",
+		body:        `<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + strings.Join(entries, "") + `</urlset>`,
+	}
+}
+
+func sitemapIndex(locs ...string) testPage {
+	var sb strings.Builder
+	for _, loc := range locs {
+		fmt.Fprintf(&sb, "<sitemap><loc>%s</loc></sitemap>", loc)
+	}
+	return testPage{
+		contentType: "application/xml",
+		body:        `<?xml version="1.0" encoding="UTF-8"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` + sb.String() + `</sitemapindex>`,
+	}
+}
+
+func TestSitemapClientMapWebsite(t *testing.T) {
+	tests := map[string]struct {
+		pages      map[string]testPage
+		limit      int
+		want       []string
+		wantMapped bool
+		wantNotHit []string
+	}{
+		"robots.txt sitemap": {
+			pages: map[string]testPage{
+				"/robots.txt": {contentType: "text/plain", body: "User-agent: *\nSitemap: /docs-sitemap.xml # docs\n"},
+				"/docs-sitemap.xml": urlset(
+					"<url><loc>$SERVER/a</loc></url>",
+					"<url><loc> $SERVER/b </loc></url>",
+					"<url><loc>$SERVER/a</loc></url>",
+					"<url><loc>https://other.example/c</loc></url>",
+				),
+			},
+			want:       []string{"/a", "/b"},
+			wantNotHit: []string{"/sitemap.xml"},
+		},
+		"default locations": {
+			pages: map[string]testPage{
+				"/sitemap.xml":       urlset("<url><loc>$SERVER/a</loc></url>"),
+				"/sitemap_index.xml": sitemapIndex("$SERVER/more.xml"),
+				"/more.xml":          urlset("<url><loc>$SERVER/b</loc></url>"),
+			},
+			want: []string{"/a", "/b"},
+		},
+		"index recursion": {
+			pages: map[string]testPage{
+				"/sitemap.xml":  sitemapIndex("$SERVER/pages.xml", "$SERVER/posts.xml.gz", "$SERVER/missing.xml", "$SERVER/broken.xml"),
+				"/pages.xml":    urlset("<url><loc>$SERVER/a</loc></url>"),
+				"/posts.xml.gz": {gzip: true, body: urlset("<url><loc>$SERVER/b</loc></url>").body},
+				"/broken.xml":   {contentType: "application/xml", body: "<html></html>"},
+			},
+			want: []string{"/a", "/b"},
+		},
+		"index recursion depth": {
+			pages: map[string]testPage{
+				"/sitemap.xml": sitemapIndex("$SERVER/1.xml"),
+				"/1.xml":       sitemapIndex("$SERVER/2.xml"),
+				"/2.xml":       sitemapIndex("$SERVER/3.xml"),
+				// the entries of the deepest sitemap are kept, but its children are not followed
+				"/3.xml": {
+					contentType: "application/xml",
+					body:        `<sitemapindex><url><loc>$SERVER/deep</loc></url><sitemap><loc>$SERVER/4.xml</loc></sitemap></sitemapindex>`,
+				},
+				"/4.xml": urlset("<url><loc>$SERVER/too-deep</loc></url>"),
+			},
+			want:       []string{"/deep"},
+			wantNotHit: []string{"/4.xml"},
+		},
+		"index cycle": {
+			pages: map[string]testPage{
+				"/sitemap.xml": sitemapIndex("$SERVER/1.xml"),
+				"/1.xml":       sitemapIndex("$SERVER/sitemap.xml", "$SERVER/2.xml"),
+				"/2.xml":       urlset("<url><loc>$SERVER/a</loc></url>"),
+			},
+			want: []string{"/a"},
+		},
+		"priority and lastmod order": {
+			pages: map[string]testPage{
+				"/sitemap.xml": urlset(
+					"<url><loc>$SERVER/old</loc><lastmod>2020-01-01</lastmod></url>",
+					"<url><loc>$SERVER/low</loc><priority>0.1</priority></url>",
+					"<url><loc>$SERVER/new</loc><lastmod>2024-06-01T10:00:00Z</lastmod></url>",
+					"<url><loc>$SERVER/high</loc><priority>1.0</priority></url>",
+					"<url><loc>$SERVER/invalid</loc><priority>2</priority></url>",
+				),
+			},
+			want: []string{"/high", "/new", "/old", "/invalid", "/low"},
+		},
+		"limit": {
+			pages: map[string]testPage{
+				"/sitemap.xml": urlset(
+					"<url><loc>$SERVER/a</loc><priority>0.2</priority></url>",
+					"<url><loc>$SERVER/b</loc><priority>0.9</priority></url>",
+					"<url><loc>$SERVER/c</loc><priority>0.5</priority></url>",
+				),
+			},
+			limit: 2,
+			want:  []string{"/b", "/c"},
+		},
+		"no sitemap": {
+			wantMapped: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pages := make(map[string]testPage)
+			site := newTestSite(t, pages)
+			for path, page := range tt.pages {
+				page.body = strings.ReplaceAll(page.body, "$SERVER", site.URL)
+				pages[path] = page
+			}
+
+			fallback := &fakeFirecrawlClient{urls: []string{site.URL + "/mapped"}}
+			s := NewSitemapClient(fallback, "")
+			got, err := s.MapWebsite(t.Context(), site.URL+"/", tt.limit, FirecrawlOptions{})
+			if err != nil {
+				t.Fatalf("MapWebsite() error = %v", err)
+			}
+
+			want := fallback.urls
+			if !tt.wantMapped {
+				want = nil
+				for _, p := range tt.want {
+					want = append(want, site.URL+p)
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("MapWebsite() = %q, want %q", got, want)
+			}
+			if fallback.mapped != tt.wantMapped {
+				t.Errorf("MapWebsite() fell back to the client = %v, want %v", fallback.mapped, tt.wantMapped)
+			}
+			for _, p := range tt.wantNotHit {
+				if site.hit(p) {
+					t.Errorf("MapWebsite() fetched %s", p)
+				}
+			}
+		})
+	}
+}
+
+func TestSitemapClientScrapeURL(t *testing.T) {
+	pages := make(map[string]testPage)
+	site := newTestSite(t, pages)
+	pages["/sitemap.xml"] = urlset(
+		"<url><loc>"+site.URL+"/a</loc><lastmod>2024-06-01</lastmod><priority>0.8</priority></url>",
+		"<url><loc>"+site.URL+"/b</loc></url>",
+	)
+
+	s := NewSitemapClient(&fakeFirecrawlClient{}, "")
+	if _, err := s.MapWebsite(t.Context(), site.URL+"/", 0, FirecrawlOptions{}); err != nil {
+		t.Fatalf("MapWebsite() error = %v", err)
+	}
+
+	tests := map[string]struct {
+		url  string
+		want map[string]string
+	}{
+		"lastmod and priority": {
+			url:  site.URL + "/a",
+			want: map[string]string{metadataLastModified: "2024-06-01T00:00:00Z", metadataPriority: "0.8"},
+		},
+		"default priority": {
+			url:  site.URL + "/b",
+			want: map[string]string{metadataPriority: "0.5"},
+		},
+		"not in the sitemap": {
+			url:  site.URL + "/c",
+			want: map[string]string{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.ScrapeURL(t.Context(), tt.url, FirecrawlOptions{})
+			if err != nil {
+				t.Fatalf("ScrapeURL() error = %v", err)
+			}
+			if len(got.Metadata) != len(tt.want) {
+				t.Errorf("ScrapeURL().Metadata = %q, want %q", got.Metadata, tt.want)
+			}
+			for k, v := range tt.want {
+				if got.Metadata[k] != v {
+					t.Errorf("ScrapeURL().Metadata[%q] = %q, want %q", k, got.Metadata[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLastModified(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		want time.Time
+	}{
+		"empty":       {in: "", want: time.Time{}},
+		"year":        {in: "2024", want: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"month":       {in: "2024-06", want: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		"date":        {in: " 2024-06-15 ", want: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)},
+		"minutes":     {in: "2024-06-15T10:30Z", want: time.Date(2024, 6, 15, 10, 30, 0, 0, time.UTC)},
+		"seconds":     {in: "2024-06-15T10:30:45+09:00", want: time.Date(2024, 6, 15, 1, 30, 45, 0, time.UTC)},
+		"nanoseconds": {in: "2024-06-15T10:30:45.5Z", want: time.Date(2024, 6, 15, 10, 30, 45, 5e8, time.UTC)},
+		"invalid":     {in: "yesterday", want: time.Time{}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := parseLastModified(tt.in); !got.Equal(tt.want) {
+				t.Errorf("parseLastModified(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -35,13 +35,78 @@ type ProcessedURL struct {
 	Description string `json:"description"`
 	Markdown    string `json:"markdown"`
 	Index       int    `json:"index"`
+	// LastModified is the sitemap lastmod of the page, if known.
+	LastModified time.Time `json:"last_modified,omitzero"`
+	// Priority is the sitemap priority of the page, if known.
+	Priority float64 `json:"priority,omitempty"`
+	// Section is the name of the llms.txt section of the page.
+	Section string `json:"section,omitempty"`
+	// ETag and HTTPLastModified are the HTTP validators of the page, if the backend reported them.
+	ETag             string `json:"etag,omitempty"`
+	HTTPLastModified string `json:"http_last_modified,omitempty"`
+	// Reused reports whether the title and description were reused from the previous manifest.
+	Reused bool `json:"reused,omitempty"`
+
+	// pending reports whether the page awaits its summary from the batch of [GenerationOptions.Batch].
+	pending bool
 }
 
 type GenerationResult struct {
-	LLMsTxt        string `json:"llms_txt"`
-	LLMsFullTxt    string `json:"llms_full_txt"`
+	LLMsTxt     string `json:"llms_txt"`
+	LLMsFullTxt string `json:"llms_full_txt"`
+	// LLMsCtxTxt and LLMsCtxFullTxt are the context files of llms.txt with the content of the pages inlined, without
+	// and with the "Optional" section. They are empty if [GenerationOptions.NoCtx] is set.
+	LLMsCtxTxt     string `json:"llms_ctx_txt,omitempty"`
+	LLMsCtxFullTxt string `json:"llms_ctx_full_txt,omitempty"`
 	ProcessedCount int    `json:"processed_count"`
 	TotalCount     int    `json:"total_count"`
+	// Excluded lists the mapped URLs which were skipped by the crawl policy.
+	Excluded []ExcludedURL `json:"excluded,omitempty"`
+	// Filter reports the URLs removed by the include and exclude patterns, if any.
+	Filter *FilterReport `json:"filter,omitempty"`
+	// Usage is the statistics of the LLM requests, including retries and the tokens of the prompt cache.
+	Usage gollm.Usage `json:"usage"`
+	// BatchUsage is the part of Usage sent through [GenerationOptions.Batch], billed at [gollm.Price.Batch].
+	BatchUsage gollm.Usage `json:"batch_usage,omitzero"`
+	// Failures lists the URLs which failed to scrape or summarize.
+	Failures []Failure `json:"failures,omitempty"`
+	// Site is the name and summary of the site rendered at the top of llms.txt.
+	Site Site `json:"site"`
+	// Sections are the H2 sections of llms.txt.
+	Sections []Section `json:"sections,omitempty"`
+	// Reused is the number of pages reused from [GenerationOptions.Previous].
+	Reused int `json:"reused"`
+	// Manifest records the pages of this run to be reused by the next run.
+	Manifest *Manifest `json:"-"`
+	// BudgetExhausted reports whether the run stopped early because of [GenerationOptions.Budget].
+	BudgetExhausted bool `json:"budget_exhausted,omitempty"`
+	// Skipped is the number of URLs left unprocessed because the budget was exhausted.
+	Skipped int `json:"skipped,omitempty"`
+	// Spent is the spending counted against [GenerationOptions.Budget], or nil without a budget.
+	Spent *Spending `json:"spent,omitempty"`
+}
+
+// Stages of a [Failure].
+const (
+	StageScrape    = "scrape"
+	StageSummarize = "summarize"
+)
+
+// Failure is a URL which failed to process.
+//
+// A URL failing at [StageSummarize] is still listed in the outputs with the title and description of the page metadata.
+type Failure struct {
+	URL   string `json:"url"`
+	Stage string `json:"stage"`
+	Error string `json:"error"`
+	// Attempts is the number of requests made for the failing stage, including retries.
+	Attempts int `json:"attempts"`
+}
+
+// ExcludedURL is a mapped URL which was not processed, along with the reason.
+type ExcludedURL struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
 }
 
 type FirecrawlOptions struct {
@@ -52,18 +117,62 @@ type FirecrawlOptions struct {
 	IgnoreSitemap     bool
 }
 
+// CrawlerOptions contains the configuration for the native crawler backend created by [NewCrawlerClient].
+type CrawlerOptions struct {
+	// UserAgent is the User-Agent header sent with every request.
+	UserAgent string
+	// MaxDepth is the maximum number of links followed from the start URL when mapping a website. Zero means unlimited.
+	MaxDepth int
+}
+
 type GenerationOptions struct {
-	Model            string
-	MaxURLs          int
-	OutputDir        string
-	NoFullText       bool
+	Model      string
+	MaxURLs    int
+	OutputDir  string
+	NoFullText bool
+	// NoCtx disables the llms-ctx.txt and llms-ctx-full.txt context files.
+	NoCtx            bool
 	Verbose          bool
 	BatchSize        int
 	MaxWorkers       int
-	BatchDelay       time.Duration
 	Timeout          time.Duration
 	MaxContentLength int
+	// Summarize is the strategy for pages longer than MaxContentLength, either [SummarizeChunked] or
+	// [SummarizeTruncate]. Empty means [SummarizeChunked].
+	Summarize string
+	// MaxChunks is the maximum number of chunks of a page summarized by [SummarizeChunked]. Zero means
+	// [gollm.DefaultMaxChunks].
+	MaxChunks        int
 	FirecrawlOptions FirecrawlOptions
+	// CrawlPolicy is consulted before scraping each URL. It may be nil.
+	CrawlPolicy *CrawlPolicy
+	// MapLimit is the maximum number of URLs requested from MapWebsite, before filtering. Zero means MaxURLs.
+	MapLimit int
+	// IncludePatterns and ExcludePatterns are the URL path patterns applied after mapping. See [URLFilter].
+	IncludePatterns []string
+	ExcludePatterns []string
+	// OptionalPatterns are the URL path patterns of the pages listed under the "Optional" section of llms.txt.
+	OptionalPatterns []string
+	// OptionalPriority lists the pages with a sitemap priority below it under the "Optional" section. Zero disables it.
+	OptionalPriority float64
+	// SectionMode is either [SectionsByPath] or [SectionsByLLM]. Empty means [SectionsByPath].
+	SectionMode string
+	// SectionDepth is the maximum number of path segments of a section prefix. Zero means 2.
+	SectionDepth int
+	// Taxonomy are the category names assigned by [SectionsByLLM]. If empty, the summarizer proposes them.
+	Taxonomy []string
+	// LinkMirrors links the pages of llms.txt to their Markdown mirror instead of their URL. See [MirrorURL].
+	LinkMirrors bool
+	// Journal checkpoints the completed URLs, and resumes the interrupted run it was loaded from. It may be nil.
+	Journal *Journal
+	// Budget is the hard spending limit of the run. It may be nil.
+	Budget *Budget
+	// Batch summarizes the pages through the batch API of the provider, after all pages are scraped, instead of the
+	// summarizer. The site summary and the sections are still generated by the summarizer. It may be nil.
+	Batch gollm.BatchClient
+	// Previous is the manifest of the previous run, whose unchanged pages are reused instead of being scraped and
+	// summarized again. It may be nil.
+	Previous *Manifest
 }
 
 type FirecrawlClient interface {
@@ -71,10 +180,43 @@ type FirecrawlClient interface {
 	ScrapeURL(ctx context.Context, url string, options FirecrawlOptions) (*ScrapedData, error)
 }
 
+// creditCoster is implemented by the [FirecrawlClient] implementations backed by a paid API.
+type creditCoster interface {
+	// credits returns the number of credits spent by each MapWebsite and ScrapeURL request.
+	credits() int
+}
+
+// creditsOf returns the number of credits spent by each request of client, which is zero unless client implements
+// [creditCoster].
+func creditsOf(client FirecrawlClient) int {
+	if c, ok := client.(creditCoster); ok {
+		return c.credits()
+	}
+	return 0
+}
+
+// stateMapper is implemented by the [FirecrawlClient] implementations whose ScrapeURL relies on the state built by
+// MapWebsite, such as the sitemap entries or the source files of the URLs. They are mapped again when an interrupted
+// run is resumed, even though its URLs are planned already.
+type stateMapper interface {
+	needsMap()
+}
+
 type LLMsTxtGenerator struct {
 	firecrawlClient FirecrawlClient
 	summarizer      gollm.SummarizerClient
-	options         GenerationOptions
+	// pageSummarizer summarizes the pages with the [GenerationOptions.Summarize] strategy.
+	pageSummarizer gollm.SummarizerClient
+	// batch summarizes the pages with [GenerationOptions.Batch] and the [GenerationOptions.Summarize] strategy.
+	// It is nil unless [GenerationOptions.Batch] is set.
+	batch gollm.BatchClient
+	// batchEstimator estimates the usage of the requests of batch to reserve them from the budget.
+	batchEstimator gollm.SummarizerClient
+	// budget enforces [GenerationOptions.Budget]. It may be nil.
+	budget  *accountant
+	options GenerationOptions
+	// previous are the pages of [GenerationOptions.Previous] by URL.
+	previous map[string]ManifestEntry
 }
 
 type MapResponse struct {
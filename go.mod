@@ -9,6 +9,7 @@ require (
 	github.com/mendableai/firecrawl-go/v2 v2.4.0
 	github.com/openai/openai-go/v2 v2.7.1
 	github.com/spf13/cobra v1.10.1
+	golang.org/x/net v0.44.0
 	golang.org/x/sync v0.17.0
 )
 
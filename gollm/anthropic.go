@@ -17,48 +17,127 @@
 package gollm
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 
 	anthropic "github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/go-json-experiment/json"
-	"github.com/kaptinlin/jsonrepair"
 )
 
 // Anthropic models from https://github.com/anthropics/anthropic-sdk-go/blob/v1.9.1/message.go#L1859-L1872.
-// "claude-3-7-sonnet-latest"
-// "claude-3-7-sonnet-20250219"
-// "claude-3-5-haiku-latest"
-// "claude-3-5-haiku-20241022"
-// "claude-sonnet-4-20250514"
-// "claude-sonnet-4-0"
-// "claude-4-sonnet-20250514"
-// "claude-3-5-sonnet-latest"
-// "claude-3-5-sonnet-20241022"
-// "claude-3-5-sonnet-20240620"
-// "claude-opus-4-0"
-// "claude-opus-4-20250514"
-// "claude-4-opus-20250514"
-// "claude-opus-4-1-20250805"
+var anthropicModels = []string{
+	"claude-3-7-sonnet-latest",
+	"claude-3-7-sonnet-20250219",
+	"claude-3-5-haiku-latest",
+	"claude-3-5-haiku-20241022",
+	"claude-sonnet-4-20250514",
+	"claude-sonnet-4-0",
+	"claude-4-sonnet-20250514",
+	"claude-3-5-sonnet-latest",
+	"claude-3-5-sonnet-20241022",
+	"claude-3-5-sonnet-20240620",
+	"claude-opus-4-0",
+	"claude-opus-4-20250514",
+	"claude-4-opus-20250514",
+	"claude-opus-4-1-20250805",
+}
+
+// anthropicMaxTokens is the maximum number of tokens of a response, which is a short title and description.
+const anthropicMaxTokens = 1024
+
+// anthropicBetas are the beta features enabled for the requests of the Anthropic clients.
+var anthropicBetas = []anthropic.AnthropicBeta{
+	anthropic.AnthropicBetaMessageBatches2024_09_24,
+	anthropic.AnthropicBetaPromptCaching2024_07_31,
+	anthropic.AnthropicBetaPDFs2024_09_25,
+	anthropic.AnthropicBetaTokenCounting2024_11_01,
+	anthropic.AnthropicBetaFilesAPI2025_04_14,
+	anthropic.AnthropicBetaMCPClient2025_04_04,
+	anthropic.AnthropicBetaInterleavedThinking2025_05_14,
+	anthropic.AnthropicBetaCodeExecution2025_05_22,
+	anthropic.AnthropicBetaExtendedCacheTTL2025_04_11,
+	anthropic.AnthropicBetaContext1m2025_08_07,
+}
+
+// Anthropic prompt cache TTLs, see [AnthropicConfig.CacheTTL].
+const (
+	AnthropicCacheTTLOff = "off"
+	AnthropicCacheTTL5m  = "5m"
+	AnthropicCacheTTL1h  = "1h"
+)
+
+// Minimum number of tokens of a prefix cached by Anthropic, see
+// https://docs.anthropic.com/en/docs/build-with-claude/prompt-caching#cache-limitations.
+const (
+	anthropicMinCacheTokens      = 1024
+	anthropicMinCacheTokensHaiku = 2048
+)
+
+// anthropicToolChoiceTokens is the number of tokens of the system prompt added by Anthropic to the requests forcing the
+// use of a tool, see https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/overview#pricing.
+const anthropicToolChoiceTokens = 313
 
 // AnthropicConfig contains the configuration for the Anthropic client.
 type AnthropicConfig struct {
 	Config
+
+	// CacheTTL is the lifetime of the prompt cache entries of the system prompt and the static user prompt:
+	// [AnthropicCacheTTL5m], [AnthropicCacheTTL1h] or [AnthropicCacheTTLOff]. Empty is [AnthropicCacheTTL5m].
+	// The prompts whose static prefix is shorter than the minimum of the model are sent without cache breakpoints.
+	//
+	// An entry of 1 hour costs twice the input price to write instead of 1.25 times, and outlives the pauses of a
+	// run, such as the polls of a batch.
+	CacheTTL string
 }
 
 type anthropicClient struct {
 	client           anthropic.Client
 	model            string
 	maxContentLength int
+	cacheTTL         string
 	logger           *slog.Logger
+
+	// shortPrefixOnce logs once that the static prefix of the prompts is too short to be cached.
+	shortPrefixOnce sync.Once
 }
 
 var _ SummarizerClient = (*anthropicClient)(nil)
 
+func init() {
+	Register(Provider{
+		Name:        ProviderAnthropic,
+		Description: "Anthropic Claude API",
+		Prefixes:    []string{"claude-"},
+		Models:      anthropicModels,
+		Prices:      anthropicPrices,
+		Endpoint: func(*Options) string {
+			return os.Getenv("ANTHROPIC_BASE_URL")
+		},
+		New: func(model string, opts *Options) (SummarizerClient, error) {
+			if model == "" {
+				return nil, fmt.Errorf("model name is required")
+			}
+			c := NewAnthropicClient(cmp.Or(opts.APIKey, opts.Anthropic.APIKey), model, opts.MaxContentLength, option.WithMaxRetries(0))
+			c.cacheTTL = opts.Anthropic.CacheTTL
+			return c, nil
+		},
+		NewBatch: func(model string, opts *Options) (BatchClient, error) {
+			if model == "" {
+				return nil, fmt.Errorf("model name is required")
+			}
+			c := NewAnthropicBatchClient(cmp.Or(opts.APIKey, opts.Anthropic.APIKey), model, opts.MaxContentLength, opts.Batch)
+			c.cacheTTL = opts.Anthropic.CacheTTL
+			return c, nil
+		},
+	})
+}
+
 // NewAnthropicClient creates a new instance of [SummarizerClient] given the API key, model, maximum content length and request options.
 func NewAnthropicClient(apiKey, model string, maxContentLength int, opts ...option.RequestOption) *anthropicClient {
 	cOpts := []option.RequestOption{
@@ -73,6 +152,9 @@ func NewAnthropicClient(apiKey, model string, maxContentLength int, opts ...opti
 	if val, ok := os.LookupEnv("ANTHROPIC_AUTH_TOKEN"); ok {
 		cOpts = append(cOpts, option.WithAuthToken(val))
 	}
+	if apiKey != "" {
+		cOpts = append(cOpts, option.WithAPIKey(apiKey))
+	}
 	// [option.RequestOption] are last win
 	cOpts = append(cOpts, opts...)
 
@@ -100,93 +182,231 @@ func (c *anthropicClient) SummarizeContent(ctx context.Context, prompt Prompt, c
 		slog.String("model", c.model),
 		slog.Group("prompt",
 			slog.String("system", prompt.System),
-			slog.String("user", prompt.User),
+			slog.String("user", prompt.userText()),
 		),
 	)
+	c.checkCachePrefix(ctx, prompt)
+
+	stream := c.client.Beta.Messages.NewStreaming(ctx, c.messageParams(prompt, content))
+
+	// the events only carry deltas, so the message is accumulated before reading its content and usage
+	var message anthropic.BetaMessage
+	for stream.Next() {
+		if err := message.Accumulate(stream.Current()); err != nil {
+			c.logger.ErrorContext(ctx, "Failed to accumulate message stream", slog.Any("error", err))
+			return "", "", fmt.Errorf("accumulate message stream: %w", err)
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		c.logger.ErrorContext(ctx, "Failed to get message with stream", slog.Any("error", err))
+		return "", "", fmt.Errorf("get message with stream: %w", parseStreamError(err))
+	}
+	StatsFromContext(ctx).add(c.usage(ctx, &message.Usage))
+
+	return c.parseMessage(ctx, &message)
+}
+
+// streamErrorPrefix prefixes the payload of the error events of a stream in the errors of [ssestream.Stream].
+const streamErrorPrefix = "received error while streaming: "
+
+// streamError is an error event received in the middle of a message stream, after the response status.
+type streamError struct {
+	// Type is the type of the error, such as overloaded_error.
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// Error implements [error].
+func (e *streamError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+// parseStreamError returns the [*streamError] of the error event of a stream carried by err, or err if it carries
+// none.
+func parseStreamError(err error) error {
+	data, ok := strings.CutPrefix(err.Error(), streamErrorPrefix)
+	if !ok {
+		return err
+	}
+	var event struct {
+		Error streamError `json:"error"`
+	}
+	if json.Unmarshal([]byte(data), &event, json.DiscardUnknownMembers(true)) != nil || event.Error.Type == "" {
+		return err
+	}
+	return &event.Error
+}
+
+// usage returns the [Usage] of the tokens of u, logging its prompt cache usage.
+func (c *anthropicClient) usage(ctx context.Context, u *anthropic.BetaUsage) Usage {
+	if u.CacheReadInputTokens > 0 || u.CacheCreationInputTokens > 0 {
+		c.logger.DebugContext(ctx, "Prompt cache usage",
+			slog.Int64("read_tokens", u.CacheReadInputTokens),
+			slog.Int64("write_tokens", u.CacheCreationInputTokens),
+		)
+	}
 
+	return Usage{
+		InputTokens:        int(u.InputTokens),
+		OutputTokens:       int(u.OutputTokens),
+		CacheReadTokens:    int(u.CacheReadInputTokens),
+		CacheWriteTokens:   int(u.CacheCreationInputTokens),
+		CacheWrite1hTokens: int(u.CacheCreation.Ephemeral1hInputTokens),
+	}
+}
+
+// cacheControl returns the cache_control of the prompt cache breakpoints, or a zero value if the cache is off.
+func (c *anthropicClient) cacheControl() anthropic.BetaCacheControlEphemeralParam {
+	switch c.cacheTTL {
+	case AnthropicCacheTTLOff:
+		return anthropic.BetaCacheControlEphemeralParam{}
+	case AnthropicCacheTTL1h:
+		return anthropic.BetaCacheControlEphemeralParam{TTL: anthropic.BetaCacheControlEphemeralTTLTTL1h}
+	default:
+		return anthropic.BetaCacheControlEphemeralParam{TTL: anthropic.BetaCacheControlEphemeralTTLTTL5m}
+	}
+}
+
+// minCacheTokens returns the minimum number of tokens of a prefix cached for the model.
+func (c *anthropicClient) minCacheTokens() int {
+	if strings.Contains(c.model, "haiku") {
+		return anthropicMinCacheTokensHaiku
+	}
+	return anthropicMinCacheTokens
+}
+
+// summaryToolTokens is the estimated number of tokens of the prompt prefix added by the forced "summary" tool: its
+// definition and the tool use system prompt of Anthropic.
+var summaryToolTokens = sync.OnceValue(func() int {
+	data, err := json.Marshal(summaryTool())
+	if err != nil {
+		panic(fmt.Sprintf("marshal summary tool: %v", err))
+	}
+	return anthropicToolChoiceTokens + EstimateTokens(string(data))
+})
+
+// prefixTokens returns the estimated number of tokens of the static prefix of prompt: the tool, the system prompt and
+// the user prompt.
+func prefixTokens(prompt Prompt) int {
+	return summaryToolTokens() + EstimateTokens(prompt.System) + EstimateTokens(prompt.User)
+}
+
+// cachesPrefix reports whether the static prefix of prompt is sent with cache breakpoints, which requires the cache to
+// be on and the prefix to reach the minimum of the model. Anthropic bills shorter prefixes at the regular input price.
+func (c *anthropicClient) cachesPrefix(prompt Prompt) bool {
+	return c.cacheTTL != AnthropicCacheTTLOff && prefixTokens(prompt) >= c.minCacheTokens()
+}
+
+// checkCachePrefix logs once if the static prefix of prompt is estimated to be shorter than the minimum of the model,
+// in which case it is sent without cache breakpoints.
+func (c *anthropicClient) checkCachePrefix(ctx context.Context, prompt Prompt) {
+	if c.cacheTTL == AnthropicCacheTTLOff || c.cachesPrefix(prompt) {
+		return
+	}
+	tokens := prefixTokens(prompt)
+	c.shortPrefixOnce.Do(func() {
+		c.logger.InfoContext(ctx, "Prompt prefix is too short to be cached, sending no cache breakpoints",
+			slog.String("model", c.model),
+			slog.Int("estimated_tokens", tokens),
+			slog.Int("min_tokens", c.minCacheTokens()),
+		)
+	})
+}
+
+// messageParams returns the parameters of the message summarizing content with prompt, truncated to the maximum content
+// length.
+//
+// The tools, the system prompt and the user prompt are sent first, as a static prefix ending with cache breakpoints if
+// it is long enough to be cached, so that the requests sharing them are billed at the price of cache reads. The suffix
+// of the prompt and the content follow.
+func (c *anthropicClient) messageParams(prompt Prompt, content string) anthropic.BetaMessageNewParams {
 	if c.maxContentLength > 0 && len(content) > c.maxContentLength {
 		content = content[:c.maxContentLength]
 	}
 
+	var cacheControl anthropic.BetaCacheControlEphemeralParam
+	if c.cachesPrefix(prompt) {
+		cacheControl = c.cacheControl()
+	}
+	blocks := []anthropic.BetaContentBlockParamUnion{
+		{OfText: &anthropic.BetaTextBlockParam{Text: prompt.User, CacheControl: cacheControl}},
+	}
+	if prompt.Suffix != "" {
+		blocks = append(blocks, anthropic.NewBetaTextBlock(prompt.Suffix))
+	}
+	blocks = append(blocks, anthropic.NewBetaTextBlock(fmt.Sprintf("Page content:\n%s", content)))
+
 	params := anthropic.BetaMessageNewParams{
 		Model: anthropic.Model(c.model),
 		System: []anthropic.BetaTextBlockParam{
 			{
 				Text: prompt.System,
+				// the first breakpoint is shared by all the prompts of a run, which differ in their user prompt
+				CacheControl: cacheControl,
 			},
 		},
 		Messages: []anthropic.BetaMessageParam{
 			{
-				Content: []anthropic.BetaContentBlockParamUnion{
-					anthropic.NewBetaTextBlock(prompt.User),
-					anthropic.NewBetaTextBlock(fmt.Sprintf("Page content:\n%s", content)),
-				},
-				Role: anthropic.BetaMessageParamRoleUser,
+				Content: blocks,
+				Role:    anthropic.BetaMessageParamRoleUser,
 			},
 		},
-		Betas: []anthropic.AnthropicBeta{
-			anthropic.AnthropicBetaMessageBatches2024_09_24,
-			anthropic.AnthropicBetaPromptCaching2024_07_31,
-			anthropic.AnthropicBetaPDFs2024_09_25,
-			anthropic.AnthropicBetaTokenCounting2024_11_01,
-			anthropic.AnthropicBetaFilesAPI2025_04_14,
-			anthropic.AnthropicBetaMCPClient2025_04_04,
-			anthropic.AnthropicBetaInterleavedThinking2025_05_14,
-			anthropic.AnthropicBetaCodeExecution2025_05_22,
-			anthropic.AnthropicBetaExtendedCacheTTL2025_04_11,
-			anthropic.AnthropicBetaContext1m2025_08_07,
-		},
+		// the response is forced into the input of the tool, which is incompatible with extended thinking
+		Tools:      []anthropic.BetaToolUnionParam{summaryTool()},
+		ToolChoice: anthropic.BetaToolChoiceParamOfTool(descriptionSchemaName),
+		MaxTokens:  anthropicMaxTokens,
+		Betas:      anthropicBetas,
 	}
 
-	switch {
-	case strings.HasPrefix(c.model, "claude-opus-"):
-		params.MaxTokens = 32000
-		params.Thinking = anthropic.BetaThinkingConfigParamOfEnabled(25600)
+	return params
+}
 
-	case strings.HasPrefix(c.model, "claude-sonnet-"):
-		params.MaxTokens = 320000
-		params.Thinking = anthropic.BetaThinkingConfigParamOfEnabled(256000)
+// summaryTool returns the tool whose input is the title and description of the response.
+func summaryTool() anthropic.BetaToolUnionParam {
+	return anthropic.BetaToolUnionParam{
+		OfTool: &anthropic.BetaToolParam{
+			Name:        descriptionSchemaName,
+			Description: anthropic.String("Submit the title and description requested by the instructions."),
+			InputSchema: anthropic.BetaToolInputSchemaParam{
+				Properties: descriptionSchema["properties"],
+				Required:   []string{"title", "description"},
+			},
+		},
 	}
+}
 
-	stream := c.client.Beta.Messages.NewStreaming(ctx, params)
-
-	for stream.Next() {
-		data := stream.Current()
-		for _, content := range data.Message.Content {
-			if content.Text == "" {
-				c.logger.ErrorContext(ctx, "Empty content returned from OpenAI")
-				return "", "", fmt.Errorf("empty content returned for")
-			}
-
-			data, err := jsonrepair.JSONRepair(content.Text)
-			if err != nil {
-				c.logger.ErrorContext(ctx, "Repair JSON payload dailed", slog.Any("error", err))
-			}
-
+// parseMessage parses the title and description of the input of the tool called by message, or of its text blocks if
+// the tool was not called.
+func (c *anthropicClient) parseMessage(ctx context.Context, message *anthropic.BetaMessage) (title, description string, err error) {
+	for _, block := range message.Content {
+		if block.Type == "tool_use" && block.Name == descriptionSchemaName {
 			var result DescriptionRequest
-			jsonOpts := json.JoinOptions(
-				json.DiscardUnknownMembers(true), // strictly parsing
-			)
-			if err := json.UnmarshalRead(strings.NewReader(data), &result, jsonOpts); err != nil {
-				c.logger.ErrorContext(ctx, "Failed to parse JSON response", slog.String("content", data), slog.Any("error", err))
-				return "", "", fmt.Errorf("parse JSON response: %w", err)
+			if err := json.Unmarshal(block.Input, &result, json.DiscardUnknownMembers(true)); err != nil {
+				c.logger.ErrorContext(ctx, "Failed to parse tool input", slog.String("input", string(block.Input)), slog.Any("error", err))
+				return "", "", fmt.Errorf("parse tool input: %w", err)
 			}
+			return result.titleOrDefault(), result.descriptionOrDefault(), nil
+		}
+	}
 
-			title = result.Title
-			if title == "" {
-				title = "Page"
-			}
-			description = result.Description
-			if description == "" {
-				description = "No description available"
-			}
+	var text strings.Builder
+	for _, block := range message.Content {
+		// skip the thinking blocks
+		if block.Type == "text" {
+			text.WriteString(block.Text)
 		}
 	}
+	if text.Len() == 0 {
+		c.logger.ErrorContext(ctx, "Empty content returned from Anthropic")
+		return "", "", fmt.Errorf("empty content returned")
+	}
 
-	if stream.Err() != nil {
-		c.logger.ErrorContext(ctx, "Failed to get message with stream", slog.Any("error", stream.Err()))
-		return "", "", fmt.Errorf("get message with stream: %w", stream.Err())
+	result, err := parseDescription(text.String())
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Failed to parse JSON response", slog.String("content", text.String()), slog.Any("error", err))
+		return "", "", err
 	}
 
-	return title, description, nil
+	return result.titleOrDefault(), result.descriptionOrDefault(), nil
 }
@@ -0,0 +1,128 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	anthropic "github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+)
+
+type anthropicBatchClient struct {
+	*anthropicClient
+	pollInterval time.Duration
+}
+
+var _ BatchClient = (*anthropicBatchClient)(nil)
+
+// NewAnthropicBatchClient creates a new instance of [BatchClient] using the Anthropic Message Batches API, given the API
+// key, model, maximum content length, batch options and request options.
+func NewAnthropicBatchClient(apiKey, model string, maxContentLength int, batchOpts BatchOptions, opts ...option.RequestOption) *anthropicBatchClient {
+	return &anthropicBatchClient{
+		anthropicClient: NewAnthropicClient(apiKey, model, maxContentLength, opts...),
+		pollInterval:    batchOpts.PollInterval,
+	}
+}
+
+// SummarizeBatch submits reqs as a Message Batch, polls it until it ends and maps its results back to reqs by their
+// custom ID.
+//
+// SummarizeBatch implements [BatchClient].
+func (c *anthropicBatchClient) SummarizeBatch(ctx context.Context, reqs []BatchRequest) ([]BatchResult, error) {
+	params := anthropic.BetaMessageBatchNewParams{
+		Requests: make([]anthropic.BetaMessageBatchNewParamsRequest, len(reqs)),
+		Betas:    anthropicBetas,
+	}
+	for i, req := range reqs {
+		c.checkCachePrefix(ctx, req.Prompt)
+		p := c.messageParams(req.Prompt, req.Content)
+		params.Requests[i] = anthropic.BetaMessageBatchNewParamsRequest{
+			CustomID: batchCustomID(i),
+			Params: anthropic.BetaMessageBatchNewParamsRequestParams{
+				Model:      p.Model,
+				MaxTokens:  p.MaxTokens,
+				System:     p.System,
+				Messages:   p.Messages,
+				Tools:      p.Tools,
+				ToolChoice: p.ToolChoice,
+			},
+		}
+	}
+
+	batch, err := c.client.Beta.Messages.Batches.New(ctx, params)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Failed to create message batch", slog.Any("error", err))
+		return nil, fmt.Errorf("create message batch: %w", err)
+	}
+	c.logger.InfoContext(ctx, "Created message batch", slog.String("id", batch.ID), slog.Int("requests", len(reqs)))
+
+	poll := func(ctx context.Context) (bool, error) {
+		b, err := c.client.Beta.Messages.Batches.Get(ctx, batch.ID, anthropic.BetaMessageBatchGetParams{})
+		if err != nil {
+			return false, fmt.Errorf("get message batch %s: %w", batch.ID, err)
+		}
+		c.logger.DebugContext(ctx, "Polled message batch",
+			slog.String("id", b.ID),
+			slog.String("status", string(b.ProcessingStatus)),
+			slog.Int64("processing", b.RequestCounts.Processing),
+		)
+		return b.ProcessingStatus == anthropic.BetaMessageBatchProcessingStatusEnded, nil
+	}
+	cancel := func(ctx context.Context) error {
+		_, err := c.client.Beta.Messages.Batches.Cancel(ctx, batch.ID, anthropic.BetaMessageBatchCancelParams{})
+		return err
+	}
+	if err := pollBatch(ctx, c.pollInterval, c.logger, poll, cancel); err != nil {
+		return nil, err
+	}
+
+	results := newBatchResults(len(reqs))
+	stream := c.client.Beta.Messages.Batches.ResultsStreaming(ctx, batch.ID, anthropic.BetaMessageBatchResultsParams{})
+	defer stream.Close()
+	for stream.Next() {
+		resp := stream.Current()
+		i, ok := parseBatchCustomID(resp.CustomID, len(reqs))
+		if !ok {
+			c.logger.WarnContext(ctx, "Ignoring result of unknown request", slog.String("custom_id", resp.CustomID))
+			continue
+		}
+
+		result := &results[i]
+		switch resp.Result.Type {
+		case "succeeded":
+			message := resp.Result.Message
+			result.Usage = recordBatchUsage(ctx, c.usage(ctx, &message.Usage))
+			result.Title, result.Description, result.Err = c.parseMessage(ctx, &message)
+		case "errored":
+			result.Usage = recordBatchUsage(ctx, Usage{})
+			result.Err = fmt.Errorf("request errored: %s: %s", resp.Result.Error.Error.Type, resp.Result.Error.Error.Message)
+		default:
+			// canceled or expired before the request was processed
+			result.Err = fmt.Errorf("request %s", resp.Result.Type)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		c.logger.ErrorContext(ctx, "Failed to get message batch results", slog.Any("error", err))
+		return nil, fmt.Errorf("get message batch %s results: %w", batch.ID, err)
+	}
+
+	return results, nil
+}
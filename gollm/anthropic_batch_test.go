@@ -0,0 +1,240 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/go-json-experiment/json"
+)
+
+// anthropicBatchServer is a fake of the Message Batches endpoints of the Anthropic API.
+type anthropicBatchServer struct {
+	*httptest.Server
+
+	// pollsUntilEnded is the number of polls answered with the in_progress status before the batch ends.
+	pollsUntilEnded int
+	// results is the JSONL body of the results of the batch.
+	results string
+	// onPoll is called on each poll, if not nil.
+	onPoll func(polls int)
+
+	mu       sync.Mutex
+	requests []string // the custom IDs of the created batch
+	polls    int
+	canceled bool
+}
+
+func newAnthropicBatchServer(t *testing.T, s *anthropicBatchServer) *anthropicBatchServer {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/messages/batches", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Requests []struct {
+				CustomID string `json:"custom_id"`
+			} `json:"requests"`
+		}
+		if err := json.UnmarshalRead(r.Body, &body, json.DiscardUnknownMembers(true)); err != nil {
+			t.Errorf("unmarshal batch: %v", err)
+		}
+		s.mu.Lock()
+		for _, req := range body.Requests {
+			s.requests = append(s.requests, req.CustomID)
+		}
+		s.mu.Unlock()
+		writeJSON(w, s.batch("in_progress"))
+	})
+	mux.HandleFunc("GET /v1/messages/batches/msgbatch-1", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.polls++
+		polls, canceled := s.polls, s.canceled
+		s.mu.Unlock()
+		if s.onPoll != nil {
+			s.onPoll(polls)
+		}
+
+		status := "in_progress"
+		switch {
+		case canceled:
+			status = "canceling"
+		case polls > s.pollsUntilEnded:
+			status = "ended"
+		}
+		writeJSON(w, s.batch(status))
+	})
+	mux.HandleFunc("POST /v1/messages/batches/msgbatch-1/cancel", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.canceled = true
+		s.mu.Unlock()
+		writeJSON(w, s.batch("canceling"))
+	})
+	mux.HandleFunc("GET /v1/messages/batches/msgbatch-1/results", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-jsonl")
+		io.WriteString(w, s.results)
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// batch returns the message batch object with status.
+func (s *anthropicBatchServer) batch(status string) string {
+	return fmt.Sprintf(`{"id":"msgbatch-1","type":"message_batch","processing_status":%q,"request_counts":{"processing":0,"succeeded":0,"errored":0,"canceled":0,"expired":0},"created_at":"2025-01-01T00:00:00Z","expires_at":"2025-01-02T00:00:00Z"}`, status)
+}
+
+// anthropicSucceeded returns a result line of a batch whose message calls the summary tool with title and description.
+func anthropicSucceeded(id, title, description string) string {
+	return fmt.Sprintf(`{"custom_id":%q,"result":{"type":"succeeded","message":{"id":"msg-1","type":"message","role":"assistant","model":"claude-test","content":[{"type":"tool_use","id":"toolu-1","name":%q,"input":{"title":%q,"description":%q}}],"stop_reason":"tool_use","usage":{"input_tokens":100,"output_tokens":20,"cache_read_input_tokens":50,"cache_creation_input_tokens":0}}}}`, id, descriptionSchemaName, title, description)
+}
+
+func TestAnthropicBatchClientSummarizeBatch(t *testing.T) {
+	reqs := []BatchRequest{
+		{Prompt: Prompt{System: "system", User: "user", Suffix: "URL: https://example.com/0"}, Content: "zero"},
+		{Prompt: Prompt{System: "system", User: "user", Suffix: "URL: https://example.com/1"}, Content: "one"},
+		{Prompt: Prompt{System: "system", User: "user", Suffix: "URL: https://example.com/2"}, Content: "two"},
+	}
+
+	type want struct {
+		title string
+		err   string
+	}
+	tests := map[string]struct {
+		server *anthropicBatchServer
+		want   []want
+	}{
+		"out of order": {
+			server: &anthropicBatchServer{
+				pollsUntilEnded: 2,
+				results: strings.Join([]string{
+					anthropicSucceeded("request-1", "One", "The middle page."),
+					anthropicSucceeded("request-2", "Two", "The last page."),
+					anthropicSucceeded("request-0", "Zero", "The first page."),
+				}, "\n") + "\n",
+			},
+			want: []want{{title: "Zero"}, {title: "One"}, {title: "Two"}},
+		},
+		"missing results": {
+			server: &anthropicBatchServer{
+				results: strings.Join([]string{
+					anthropicSucceeded("request-0", "Zero", "The first page."),
+					anthropicSucceeded("request-9", "Unknown", "Not a request of the batch."),
+				}, "\n"),
+			},
+			want: []want{{title: "Zero"}, {err: errNoBatchResult.Error()}, {err: errNoBatchResult.Error()}},
+		},
+		"request errors": {
+			server: &anthropicBatchServer{
+				results: strings.Join([]string{
+					`{"custom_id":"request-0","result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long"}}}}`,
+					`{"custom_id":"request-1","result":{"type":"expired"}}`,
+					`{"custom_id":"request-2","result":{"type":"canceled"}}`,
+				}, "\n"),
+			},
+			want: []want{
+				{err: "request errored: invalid_request_error: prompt is too long"},
+				{err: "request expired"},
+				{err: "request canceled"},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newAnthropicBatchServer(t, tt.server)
+			client := NewAnthropicBatchClient("test-key", "claude-test", 0, BatchOptions{PollInterval: time.Millisecond},
+				option.WithBaseURL(srv.URL), option.WithMaxRetries(0))
+
+			stats := NewStats(nil)
+			results, err := client.SummarizeBatch(WithStats(context.Background(), stats), reqs)
+			if err != nil {
+				t.Fatalf("SummarizeBatch() error = %v", err)
+			}
+
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if got, want := strings.Join(srv.requests, ","), "request-0,request-1,request-2"; got != want {
+				t.Errorf("batch custom IDs = %s, want %s", got, want)
+			}
+
+			if len(results) != len(reqs) {
+				t.Fatalf("got %d results, want %d", len(results), len(reqs))
+			}
+			var usage Usage
+			for i, w := range tt.want {
+				r := results[i]
+				if w.err != "" {
+					if r.Err == nil || r.Err.Error() != w.err {
+						t.Errorf("result %d error = %v, want %s", i, r.Err, w.err)
+					}
+					if w.err == errNoBatchResult.Error() && !errors.Is(r.Err, errNoBatchResult) {
+						t.Errorf("result %d error = %v, want %v", i, r.Err, errNoBatchResult)
+					}
+				} else {
+					if r.Err != nil || r.Title != w.title {
+						t.Errorf("result %d = (%q, %v), want %q", i, r.Title, r.Err, w.title)
+					}
+					if r.Usage.InputTokens != 100 || r.Usage.OutputTokens != 20 || r.Usage.CacheReadTokens != 50 {
+						t.Errorf("result %d usage = %+v, want 100 input, 20 output and 50 cache read tokens", i, r.Usage)
+					}
+				}
+				usage.Add(r.Usage)
+			}
+			if got := stats.Usage(); got != usage {
+				t.Errorf("recorded usage = %+v, want the usage of the results %+v", got, usage)
+			}
+		})
+	}
+}
+
+func TestAnthropicBatchClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := newAnthropicBatchServer(t, &anthropicBatchServer{
+		pollsUntilEnded: 1000,
+		onPoll: func(polls int) {
+			if polls == 2 {
+				cancel()
+			}
+		},
+	})
+	client := NewAnthropicBatchClient("test-key", "claude-test", 0, BatchOptions{PollInterval: time.Millisecond},
+		option.WithBaseURL(srv.URL), option.WithMaxRetries(0))
+
+	_, err := client.SummarizeBatch(ctx, []BatchRequest{{Prompt: Prompt{System: "system", User: "user"}, Content: "content"}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("SummarizeBatch() error = %v, want %v", err, context.Canceled)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if !srv.canceled {
+		t.Error("the message batch was not canceled")
+	}
+}
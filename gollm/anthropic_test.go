@@ -0,0 +1,217 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	anthropic "github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+func TestAnthropicClientMessageParams(t *testing.T) {
+	long := strings.Repeat("s", anthropicMinCacheTokens*bytesPerToken)
+
+	tests := map[string]struct {
+		system   string
+		cacheTTL string
+		// want is the cache_control of the system prompt and the user prompt, or empty if none.
+		want string
+	}{
+		"default":      {system: long, cacheTTL: "", want: `{"ttl":"5m","type":"ephemeral"}`},
+		"5m":           {system: long, cacheTTL: AnthropicCacheTTL5m, want: `{"ttl":"5m","type":"ephemeral"}`},
+		"1h":           {system: long, cacheTTL: AnthropicCacheTTL1h, want: `{"ttl":"1h","type":"ephemeral"}`},
+		"off":          {system: long, cacheTTL: AnthropicCacheTTLOff, want: ""},
+		"short prefix": {system: "system", cacheTTL: AnthropicCacheTTL1h, want: ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := NewAnthropicClient("test-key", "claude-test", 0)
+			c.cacheTTL = tt.cacheTTL
+			prompt := Prompt{System: tt.system, User: "user", Suffix: "URL: https://example.com"}
+			params := c.messageParams(prompt, "content")
+
+			if len(params.System) != 1 {
+				t.Fatalf("got %d system blocks, want 1", len(params.System))
+			}
+			if got := cacheControlOf(t, params.System[0]); got != tt.want {
+				t.Errorf("cache_control of the system prompt = %s, want %s", got, tt.want)
+			}
+
+			if len(params.Messages) != 1 {
+				t.Fatalf("got %d messages, want 1", len(params.Messages))
+			}
+			blocks := params.Messages[0].Content
+			wantTexts := []string{"user", "URL: https://example.com", "Page content:\ncontent"}
+			if len(blocks) != len(wantTexts) {
+				t.Fatalf("got %d user blocks, want %d", len(blocks), len(wantTexts))
+			}
+			for i, block := range blocks {
+				if block.OfText == nil || block.OfText.Text != wantTexts[i] {
+					t.Fatalf("user block %d = %+v, want the text %q", i, block, wantTexts[i])
+				}
+				// the breakpoint ends the static prefix, so the suffix and the content are not cached
+				want := ""
+				if i == 0 {
+					want = tt.want
+				}
+				if got := cacheControlOf(t, block); got != want {
+					t.Errorf("cache_control of user block %d = %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+// cacheControlOf returns the JSON of the cache_control of block, or empty if it has none.
+func cacheControlOf(t *testing.T, block any) string {
+	t.Helper()
+
+	data, err := json.Marshal(block)
+	if err != nil {
+		t.Fatalf("marshal block: %v", err)
+	}
+	var fields struct {
+		CacheControl jsontext.Value `json:"cache_control"`
+	}
+	if err := json.Unmarshal(data, &fields, json.DiscardUnknownMembers(true)); err != nil {
+		t.Fatalf("unmarshal block: %v", err)
+	}
+	return string(fields.CacheControl)
+}
+
+func TestAnthropicClientCheckCachePrefix(t *testing.T) {
+	if tokens := summaryToolTokens(); tokens <= anthropicToolChoiceTokens {
+		t.Fatalf("summaryToolTokens() = %d, want more than the tool use system prompt", tokens)
+	}
+
+	short := Prompt{System: "system", User: "user"}
+	long := Prompt{System: strings.Repeat("s", 1024*bytesPerToken), User: "user"}
+	// the prefix only reaches the minimum with the tokens of the tool
+	withTool := Prompt{System: strings.Repeat("s", (anthropicMinCacheTokens-summaryToolTokens())*bytesPerToken), User: "user"}
+
+	tests := map[string]struct {
+		model    string
+		cacheTTL string
+		prompts  []Prompt
+		wantLogs int
+	}{
+		"short prefix is logged once": {model: "claude-test", prompts: []Prompt{short, short}, wantLogs: 1},
+		"long prefix":                 {model: "claude-test", prompts: []Prompt{long}, wantLogs: 0},
+		"long prefix with the tool":   {model: "claude-test", prompts: []Prompt{withTool}, wantLogs: 0},
+		"long prefix below the minimum of haiku": {
+			model:    "claude-3-5-haiku-latest",
+			prompts:  []Prompt{long},
+			wantLogs: 1,
+		},
+		"cache off": {model: "claude-test", cacheTTL: AnthropicCacheTTLOff, prompts: []Prompt{short}, wantLogs: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := NewAnthropicClient("test-key", tt.model, 0)
+			c.cacheTTL = tt.cacheTTL
+			c.logger = slog.New(slog.NewTextHandler(&buf, nil))
+
+			for _, prompt := range tt.prompts {
+				c.checkCachePrefix(context.Background(), prompt)
+
+				// the breakpoints are only sent with a prefix long enough to be cached
+				params := c.messageParams(prompt, "content")
+				cached := cacheControlOf(t, params.System[0]) != ""
+				if want := tt.cacheTTL != AnthropicCacheTTLOff && tt.wantLogs == 0; cached != want {
+					t.Errorf("messageParams() sent cache breakpoints = %t, want %t", cached, want)
+				}
+			}
+			if got := strings.Count(buf.String(), "Prompt prefix is too short to be cached"); got != tt.wantLogs {
+				t.Errorf("logged %d times, want %d:\n%s", got, tt.wantLogs, buf.String())
+			}
+		})
+	}
+}
+
+func TestAnthropicClientParseMessage(t *testing.T) {
+	text := func(s string) string {
+		return fmt.Sprintf(`[{"type":"thinking","thinking":"...","signature":"sig"},{"type":"text","text":%q}]`, s)
+	}
+
+	tests := map[string]struct {
+		content         string
+		wantTitle       string
+		wantDescription string
+		wantErr         string
+	}{
+		"tool input": {
+			content:         `[{"type":"tool_use","id":"toolu-1","name":"summary","input":{"title":"Guide","description":"How to start."}}]`,
+			wantTitle:       "Guide",
+			wantDescription: "How to start.",
+		},
+		"text": {
+			content:         text(`{"title":"Guide","description":"How to start."}`),
+			wantTitle:       "Guide",
+			wantDescription: "How to start.",
+		},
+		"repaired text": {
+			content:         text("```json\n{\"title\":\"Guide\",\"description\":\"How to start.\",}\n```"),
+			wantTitle:       "Guide",
+			wantDescription: "How to start.",
+		},
+		"missing fields": {
+			content:         text(`{}`),
+			wantTitle:       DefaultTitle,
+			wantDescription: DefaultDescription,
+		},
+		"not JSON": {
+			content: text(`["Guide"]`),
+			wantErr: "parse JSON response",
+		},
+		"empty": {
+			content: `[]`,
+			wantErr: "empty content returned",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var message anthropic.BetaMessage
+			if err := message.UnmarshalJSON([]byte(`{"id":"msg-1","type":"message","role":"assistant","model":"claude-test","content":` + tt.content + `}`)); err != nil {
+				t.Fatalf("unmarshal message: %v", err)
+			}
+
+			c := NewAnthropicClient("test-key", "claude-test", 0)
+			title, description, err := c.parseMessage(context.Background(), &message)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseMessage() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMessage() error = %v", err)
+			}
+			if title != tt.wantTitle || description != tt.wantDescription {
+				t.Errorf("parseMessage() = (%q, %q), want (%q, %q)", title, description, tt.wantTitle, tt.wantDescription)
+			}
+		})
+	}
+}
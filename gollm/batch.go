@@ -0,0 +1,177 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBatchPollInterval is the default interval between the polls of the status of a batch.
+const DefaultBatchPollInterval = 30 * time.Second
+
+// batchCancelTimeout is the timeout of canceling a batch after the context of [BatchClient.SummarizeBatch] is done.
+const batchCancelTimeout = 10 * time.Second
+
+// batchCustomIDPrefix prefixes the index of a request in the custom ID of a batch request.
+const batchCustomIDPrefix = "request-"
+
+// errNoBatchResult is the error of a request missing from the results of a batch.
+var errNoBatchResult = errors.New("no result returned by the batch")
+
+// BatchOptions configures the [BatchClient] created by [NewBatch].
+type BatchOptions struct {
+	// PollInterval is the interval between the polls of the status of a batch. Zero means [DefaultBatchPollInterval].
+	PollInterval time.Duration
+}
+
+// BatchRequest is a request of a batch: the prompt and the content to summarize, like the arguments of
+// [SummarizerClient.SummarizeContent].
+type BatchRequest struct {
+	Prompt  Prompt
+	Content string
+}
+
+// BatchResult is the result of a [BatchRequest].
+type BatchResult struct {
+	Title       string
+	Description string
+	// Usage is the usage of the request, which is also recorded in the [Stats] of the context.
+	Usage Usage
+	// Err is the error of the request, if it failed.
+	Err error
+}
+
+// BatchClient summarizes many contents at once through the asynchronous batch API of a provider, which trades
+// latency for a lower price. See [Price.Batch].
+type BatchClient interface {
+	// SummarizeBatch submits reqs as a batch, waits until the batch is complete and returns the result of each request,
+	// in the order of reqs. A failing request only sets the Err of its result; the error is only returned when the
+	// batch itself fails or ctx is done.
+	SummarizeBatch(ctx context.Context, reqs []BatchRequest) ([]BatchResult, error)
+}
+
+// BatchFactory creates a [BatchClient] for the model name without the provider name.
+type BatchFactory func(model string, opts *Options) (BatchClient, error)
+
+// SupportsBatch reports whether the provider of model resolved by [Resolve] has a batch API.
+func SupportsBatch(model string, opts *Options) bool {
+	p, _, err := Resolve(model, opts)
+	return err == nil && p.NewBatch != nil
+}
+
+// NewBatch creates the [BatchClient] for model resolved by [Resolve], wrapped by [NewValidateBatchClient] and, if
+// [Options.Cache] is set, [NewCacheBatchClient].
+//
+// The requests of a batch are not retried by [NewRetryClient]; the SDK of the provider retries the requests
+// creating and polling the batch instead.
+func NewBatch(model string, opts *Options) (BatchClient, error) {
+	p, name, err := Resolve(model, opts)
+	if err != nil {
+		return nil, err
+	}
+	if p.NewBatch == nil {
+		return nil, fmt.Errorf("provider %s has no batch API", p.Name)
+	}
+
+	client, err := p.NewBatch(name, opts)
+	if err != nil {
+		return nil, fmt.Errorf("create %s batch client: %w", p.Name, err)
+	}
+
+	if opts.MaxReasks > 0 {
+		client = NewValidateBatchClient(client, opts.MaxReasks)
+	}
+	if opts.Cache != nil {
+		// the same key as [New], so that the responses are shared by both modes
+		client = NewCacheBatchClient(client, opts.Cache, p.Name+"/"+name, p.endpoint(opts), opts.MaxContentLength)
+	}
+
+	return client, nil
+}
+
+// batchCustomID returns the custom ID of the request at index i of a batch.
+func batchCustomID(i int) string {
+	return batchCustomIDPrefix + strconv.Itoa(i)
+}
+
+// parseBatchCustomID returns the index of the request of id in a batch of n requests.
+func parseBatchCustomID(id string, n int) (int, bool) {
+	s, ok := strings.CutPrefix(id, batchCustomIDPrefix)
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 || i >= n {
+		return 0, false
+	}
+	return i, true
+}
+
+// newBatchResults returns the results of a batch of n requests, each failing with [errNoBatchResult] until its result
+// is read.
+func newBatchResults(n int) []BatchResult {
+	results := make([]BatchResult, n)
+	for i := range results {
+		results[i].Err = errNoBatchResult
+	}
+	return results
+}
+
+// recordBatchUsage records a request of a batch and the tokens of u into the [Stats] of ctx, and returns its usage.
+func recordBatchUsage(ctx context.Context, u Usage) Usage {
+	u.Requests = 1
+	StatsFromContext(ctx).add(u)
+	return u
+}
+
+// pollBatch calls poll every interval until it reports that the batch is done or fails.
+//
+// If ctx is done first, cancel is called with a fresh context, so that the requests left in the batch are not billed.
+func pollBatch(ctx context.Context, interval time.Duration, logger *slog.Logger, poll func(ctx context.Context) (bool, error), cancel func(ctx context.Context) error) error {
+	if interval <= 0 {
+		interval = DefaultBatchPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := poll(ctx)
+		if ctx.Err() == nil {
+			if err != nil || done {
+				return err
+			}
+			select {
+			case <-ticker.C:
+				continue
+			case <-ctx.Done():
+			}
+		}
+
+		cancelCtx, stop := context.WithTimeout(context.WithoutCancel(ctx), batchCancelTimeout)
+		if err := cancel(cancelCtx); err != nil {
+			logger.WarnContext(ctx, "Failed to cancel batch", slog.Any("error", err))
+		}
+		stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestParseBatchCustomID(t *testing.T) {
+	tests := map[string]struct {
+		id     string
+		n      int
+		want   int
+		wantOK bool
+	}{
+		"first":          {id: "request-0", n: 3, want: 0, wantOK: true},
+		"last":           {id: "request-2", n: 3, want: 2, wantOK: true},
+		"round trip":     {id: batchCustomID(41), n: 42, want: 41, wantOK: true},
+		"out of range":   {id: "request-3", n: 3},
+		"negative":       {id: "request--1", n: 3},
+		"unknown prefix": {id: "req-1", n: 3},
+		"not a number":   {id: "request-one", n: 3},
+		"empty":          {id: "", n: 3},
+		"empty batch":    {id: "request-0", n: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := parseBatchCustomID(tt.id, tt.n)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseBatchCustomID(%q, %d) = (%d, %t), want (%d, %t)", tt.id, tt.n, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPollBatch(t *testing.T) {
+	errPoll := errors.New("poll failed")
+
+	tests := map[string]struct {
+		// doneAfter is the number of polls until the batch is done; zero never ends it
+		doneAfter int
+		pollErr   error
+		// cancelAfter cancels the context after this number of polls, if not zero
+		cancelAfter int
+		cancelErr   error
+		wantPolls   int
+		wantCancel  bool
+		wantErr     error
+	}{
+		"done at once": {
+			doneAfter: 1,
+			wantPolls: 1,
+		},
+		"done after polls": {
+			doneAfter: 3,
+			wantPolls: 3,
+		},
+		"poll error": {
+			pollErr:   errPoll,
+			wantPolls: 1,
+			wantErr:   errPoll,
+		},
+		"canceled": {
+			cancelAfter: 2,
+			wantPolls:   2,
+			wantCancel:  true,
+			wantErr:     context.Canceled,
+		},
+		"canceled during poll": {
+			pollErr:     errPoll,
+			cancelAfter: 1,
+			wantPolls:   1,
+			wantCancel:  true,
+			wantErr:     context.Canceled,
+		},
+		"cancel fails": {
+			cancelAfter: 1,
+			cancelErr:   errors.New("cancel failed"),
+			wantPolls:   1,
+			wantCancel:  true,
+			wantErr:     context.Canceled,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancelCtx := context.WithCancel(context.Background())
+			defer cancelCtx()
+
+			var polls int
+			poll := func(ctx context.Context) (bool, error) {
+				polls++
+				if polls == tt.cancelAfter {
+					cancelCtx()
+				}
+				return polls == tt.doneAfter, tt.pollErr
+			}
+			var canceled bool
+			cancel := func(ctx context.Context) error {
+				canceled = true
+				if err := ctx.Err(); err != nil {
+					t.Errorf("cancel called with a done context: %v", err)
+				}
+				if _, ok := ctx.Deadline(); !ok {
+					t.Error("cancel called without a deadline")
+				}
+				return tt.cancelErr
+			}
+
+			err := pollBatch(ctx, time.Millisecond, slog.Default(), poll, cancel)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("pollBatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if polls != tt.wantPolls {
+				t.Errorf("got %d polls, want %d", polls, tt.wantPolls)
+			}
+			if canceled != tt.wantCancel {
+				t.Errorf("canceled = %t, want %t", canceled, tt.wantCancel)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+// cacheKeyVersion is mixed into every cache key, so that changing the key derivation invalidates the old entries.
+const cacheKeyVersion = "v3"
+
+// CacheEntry is a response stored in a [Cache].
+type CacheEntry struct {
+	Key         string    `json:"key"`
+	Model       string    `json:"model"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Cache is a content-addressed store of LLM responses on disk.
+//
+// Each entry is a JSON file named by its key, in a subdirectory named by the first two characters of the key.
+// Entries older than the TTL are treated as missing. Cache is safe for concurrent use, including by several processes.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// DefaultCacheDir returns the directory of the cache in the user cache directory, such as
+// ~/.cache/llmstxt-generator/llm on Linux.
+func DefaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "llmstxt-generator", "llm")
+}
+
+// OpenCache opens the cache in dir, creating the directory if needed. Entries expire after ttl; zero means never.
+func OpenCache(dir string, ttl time.Duration) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+	return &Cache{dir: dir, ttl: ttl}, nil
+}
+
+// Dir returns the directory of c.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Expired reports whether e is older than the TTL of c.
+func (c *Cache) Expired(e *CacheEntry) bool {
+	return c.ttl > 0 && time.Since(e.CreatedAt) > c.ttl
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// Get returns the entry of key, unless it is missing, unreadable or expired.
+func (c *Cache) Get(key string) (*CacheEntry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var e CacheEntry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key || c.Expired(&e) {
+		return nil, false
+	}
+
+	return &e, true
+}
+
+// Put stores e, replacing the entry of the same key.
+func (c *Cache) Put(e *CacheEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal cache entry: %w", err)
+	}
+
+	name := c.path(e.Key)
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	// write to a temporary file first, so that concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(name), e.Key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// Walk calls fn for each entry of c, including the expired ones, along with the size of its file.
+// Files which are not valid entries are skipped.
+func (c *Cache) Walk(fn func(e *CacheEntry, size int64) error) error {
+	return filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var e CacheEntry
+		if err := json.Unmarshal(data, &e); err != nil || e.Key == "" {
+			return nil
+		}
+
+		return fn(&e, int64(len(data)))
+	})
+}
+
+// Prune removes the entries created before cutoff and returns the number of entries removed.
+func (c *Cache) Prune(cutoff time.Time) (int, error) {
+	var removed int
+	err := c.Walk(func(e *CacheEntry, _ int64) error {
+		if !e.CreatedAt.Before(cutoff) {
+			return nil
+		}
+		if err := os.Remove(c.path(e.Key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("prune cache: %w", err)
+	}
+
+	return removed, nil
+}
+
+// PruneExpired removes the entries older than the TTL of c and returns the number of entries removed.
+func (c *Cache) PruneExpired() (int, error) {
+	if c.ttl <= 0 {
+		return 0, nil
+	}
+	return c.Prune(time.Now().Add(-c.ttl))
+}
+
+// Clear removes all entries of c.
+func (c *Cache) Clear() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("clear cache: %w", err)
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(c.dir, entry.Name())); err != nil {
+			return fmt.Errorf("clear cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CacheKey returns the key of the response of model served at endpoint to prompt and content, where content is
+// truncated to maxContentLength like the clients do, so that contents differing only past the limit share a key.
+//
+// The endpoint is the base URL of the provider, see [Provider.Endpoint], so that the same model name served by
+// different OpenAI-compatible endpoints or local servers never shares a response.
+func CacheKey(model, endpoint string, maxContentLength int, prompt Prompt, content string) string {
+	if maxContentLength > 0 && len(content) > maxContentLength {
+		content = content[:maxContentLength]
+	}
+
+	h := sha256.New()
+	for _, s := range []string{
+		cacheKeyVersion, model, endpoint, strconv.Itoa(maxContentLength),
+		prompt.System, prompt.User, prompt.Suffix,
+		strconv.Itoa(prompt.Title.Min), strconv.Itoa(prompt.Title.Max),
+		strconv.Itoa(prompt.Description.Min), strconv.Itoa(prompt.Description.Max),
+		content,
+	} {
+		// the length prefix keeps the boundaries between the fields unambiguous
+		fmt.Fprintf(h, "%d:%s", len(s), s)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isFallback reports whether title or description is the default of a response which lacks it, see
+// [DefaultTitle] and [DefaultDescription]. Such responses are not cached, so that the next run asks again.
+func isFallback(title, description string) bool {
+	return title == DefaultTitle || description == DefaultDescription
+}
+
+type cacheClient struct {
+	client           SummarizerClient
+	cache            *Cache
+	model            string
+	endpoint         string
+	maxContentLength int
+	logger           *slog.Logger
+}
+
+var _ SummarizerClient = (*cacheClient)(nil)
+
+// NewCacheClient wraps client so that its responses are stored in cache and reused for the same model, endpoint,
+// prompt and content. Cache hits are recorded in the [Stats] of the context. Failures and responses falling back to
+// [DefaultTitle] or [DefaultDescription] are never cached.
+func NewCacheClient(client SummarizerClient, cache *Cache, model, endpoint string, maxContentLength int) SummarizerClient {
+	return &cacheClient{
+		client:           client,
+		cache:            cache,
+		model:            model,
+		endpoint:         endpoint,
+		maxContentLength: maxContentLength,
+		logger:           slog.Default().WithGroup("cache"),
+	}
+}
+
+// SummarizeContent implements [SummarizerClient].
+func (c *cacheClient) SummarizeContent(ctx context.Context, prompt Prompt, content string) (title, description string, err error) {
+	key := CacheKey(c.model, c.endpoint, c.maxContentLength, prompt, content)
+	if e, ok := c.cache.Get(key); ok {
+		StatsFromContext(ctx).add(Usage{CacheHits: 1})
+		return e.Title, e.Description, nil
+	}
+
+	title, description, err = c.client.SummarizeContent(ctx, prompt, content)
+	if err != nil {
+		return "", "", err
+	}
+	if isFallback(title, description) {
+		return title, description, nil
+	}
+
+	err = c.cache.Put(&CacheEntry{
+		Key:         key,
+		Model:       c.model,
+		Title:       title,
+		Description: description,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		// the response is still good; the next run only misses the cache
+		c.logger.WarnContext(ctx, "Failed to cache LLM response", slog.String("model", c.model), slog.Any("error", err))
+	}
+
+	return title, description, nil
+}
+
+type cacheBatchClient struct {
+	client           BatchClient
+	cache            *Cache
+	model            string
+	endpoint         string
+	maxContentLength int
+	logger           *slog.Logger
+}
+
+var _ BatchClient = (*cacheBatchClient)(nil)
+
+// NewCacheBatchClient wraps client like [NewCacheClient], so that only the requests missing from cache are submitted
+// in the batch.
+func NewCacheBatchClient(client BatchClient, cache *Cache, model, endpoint string, maxContentLength int) BatchClient {
+	return &cacheBatchClient{
+		client:           client,
+		cache:            cache,
+		model:            model,
+		endpoint:         endpoint,
+		maxContentLength: maxContentLength,
+		logger:           slog.Default().WithGroup("cache"),
+	}
+}
+
+// SummarizeBatch implements [BatchClient].
+func (c *cacheBatchClient) SummarizeBatch(ctx context.Context, reqs []BatchRequest) ([]BatchResult, error) {
+	results := make([]BatchResult, len(reqs))
+	keys := make([]string, len(reqs))
+
+	var (
+		misses  []BatchRequest
+		missing []int
+	)
+	for i, req := range reqs {
+		keys[i] = CacheKey(c.model, c.endpoint, c.maxContentLength, req.Prompt, req.Content)
+		if e, ok := c.cache.Get(keys[i]); ok {
+			StatsFromContext(ctx).add(Usage{CacheHits: 1})
+			results[i] = BatchResult{Title: e.Title, Description: e.Description, Usage: Usage{CacheHits: 1}}
+			continue
+		}
+		misses = append(misses, req)
+		missing = append(missing, i)
+	}
+	if len(misses) == 0 {
+		return results, nil
+	}
+
+	missResults, err := c.client.SummarizeBatch(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range missing {
+		results[i] = missResults[j]
+		if results[i].Err != nil || isFallback(results[i].Title, results[i].Description) {
+			continue
+		}
+		err := c.cache.Put(&CacheEntry{
+			Key:         keys[i],
+			Model:       c.model,
+			Title:       results[i].Title,
+			Description: results[i].Description,
+			CreatedAt:   time.Now().UTC(),
+		})
+		if err != nil {
+			c.logger.WarnContext(ctx, "Failed to cache LLM response", slog.String("model", c.model), slog.Any("error", err))
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// scriptedClient is a [SummarizerClient] and [BatchClient] returning its responses in order, one per request, and
+// recording their usage in the [Stats] of the context like a provider.
+type scriptedClient struct {
+	responses []BatchResult
+	calls     int
+	// prompts are the prompts of the requests, in order.
+	prompts []Prompt
+	// batches are the numbers of requests of the batches.
+	batches []int
+}
+
+func (c *scriptedClient) next(ctx context.Context, prompt Prompt) BatchResult {
+	r := c.responses[min(c.calls, len(c.responses)-1)]
+	c.calls++
+	c.prompts = append(c.prompts, prompt)
+	StatsFromContext(ctx).add(r.Usage)
+	return r
+}
+
+func (c *scriptedClient) SummarizeContent(ctx context.Context, prompt Prompt, _ string) (title, description string, err error) {
+	r := c.next(ctx, prompt)
+	return r.Title, r.Description, r.Err
+}
+
+func (c *scriptedClient) SummarizeBatch(ctx context.Context, reqs []BatchRequest) ([]BatchResult, error) {
+	c.batches = append(c.batches, len(reqs))
+	results := make([]BatchResult, len(reqs))
+	for i, req := range reqs {
+		results[i] = c.next(ctx, req.Prompt)
+	}
+	return results, nil
+}
+
+func TestCacheKey(t *testing.T) {
+	type keyArgs struct {
+		model, endpoint  string
+		maxContentLength int
+		prompt           Prompt
+		content          string
+	}
+	base := keyArgs{
+		model:            "openai/gpt-4o",
+		maxContentLength: 10,
+		prompt: Prompt{
+			System:      "system",
+			User:        "user",
+			Suffix:      "URL: https://example.com/",
+			Title:       WordRange{Min: 3, Max: 4},
+			Description: WordRange{Min: 9, Max: 10},
+		},
+		content: "0123456789",
+	}
+	key := func(a keyArgs) string {
+		return CacheKey(a.model, a.endpoint, a.maxContentLength, a.prompt, a.content)
+	}
+	baseKey := key(base)
+
+	past := base
+	past.content += " past the limit"
+	if key(past) != baseKey {
+		t.Errorf("content past the limit changed the key")
+	}
+
+	changes := map[string]func(a *keyArgs){
+		"model":            func(a *keyArgs) { a.model = "openai/gpt-4o-mini" },
+		"endpoint":         func(a *keyArgs) { a.endpoint = "http://localhost:4000/v1" },
+		"max length":       func(a *keyArgs) { a.maxContentLength = 20 },
+		"system":           func(a *keyArgs) { a.prompt.System = "other" },
+		"user":             func(a *keyArgs) { a.prompt.User = "other" },
+		"suffix":           func(a *keyArgs) { a.prompt.Suffix = "URL: https://example.com/other" },
+		"title min":        func(a *keyArgs) { a.prompt.Title.Min = 2 },
+		"title max":        func(a *keyArgs) { a.prompt.Title.Max = 5 },
+		"description min":  func(a *keyArgs) { a.prompt.Description.Min = 8 },
+		"description max":  func(a *keyArgs) { a.prompt.Description.Max = 20 },
+		"content":          func(a *keyArgs) { a.content = "9876543210" },
+		"field boundaries": func(a *keyArgs) { a.prompt.System, a.prompt.User = "systemuser", "" },
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			a := base
+			change(&a)
+			if got := key(a); got == baseKey {
+				t.Errorf("changing the %s kept the key %s", name, got)
+			}
+		})
+	}
+}
+
+func TestCacheClient(t *testing.T) {
+	tests := map[string]struct {
+		response   BatchResult
+		wantCached bool
+	}{
+		"success": {
+			response:   BatchResult{Title: "Getting Started", Description: "How to install the tool."},
+			wantCached: true,
+		},
+		"default title": {
+			response: BatchResult{Title: DefaultTitle, Description: "How to install the tool."},
+		},
+		"default description": {
+			response: BatchResult{Title: "Getting Started", Description: DefaultDescription},
+		},
+		"failure": {
+			response: BatchResult{Err: errors.New("boom")},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cache, err := OpenCache(t.TempDir(), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prompt := Prompt{System: "system", User: "user"}
+
+			t.Run("sync", func(t *testing.T) {
+				inner := &scriptedClient{responses: []BatchResult{tt.response}}
+				client := NewCacheClient(inner, cache, "openai/gpt-4o", "", 0)
+				for range 2 {
+					title, description, err := client.SummarizeContent(context.Background(), prompt, "sync content")
+					if title != tt.response.Title || description != tt.response.Description || !errors.Is(err, tt.response.Err) {
+						t.Fatalf("SummarizeContent() = (%q, %q, %v), want (%q, %q, %v)", title, description, err, tt.response.Title, tt.response.Description, tt.response.Err)
+					}
+				}
+				if want := map[bool]int{true: 1, false: 2}[tt.wantCached]; inner.calls != want {
+					t.Errorf("got %d requests, want %d", inner.calls, want)
+				}
+			})
+
+			t.Run("batch", func(t *testing.T) {
+				inner := &scriptedClient{responses: []BatchResult{tt.response}}
+				client := NewCacheBatchClient(inner, cache, "openai/gpt-4o", "", 0)
+				for range 2 {
+					results, err := client.SummarizeBatch(context.Background(), []BatchRequest{{Prompt: prompt, Content: "batch content"}})
+					if err != nil {
+						t.Fatal(err)
+					}
+					if r := results[0]; r.Title != tt.response.Title || r.Description != tt.response.Description || !errors.Is(r.Err, tt.response.Err) {
+						t.Fatalf("SummarizeBatch() = %+v, want %+v", r, tt.response)
+					}
+				}
+				if want := map[bool]int{true: 1, false: 2}[tt.wantCached]; inner.calls != want {
+					t.Errorf("got %d requests, want %d", inner.calls, want)
+				}
+			})
+		})
+	}
+}
+
+func TestCacheClientEndpoint(t *testing.T) {
+	cache, err := OpenCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prompt := Prompt{System: "system", User: "user"}
+
+	for i, endpoint := range []string{"http://localhost:11434", "http://gpu-server:11434"} {
+		inner := &scriptedClient{responses: []BatchResult{{Title: "Title " + endpoint, Description: "A description."}}}
+		client := NewCacheClient(inner, cache, "ollama/llama3.1", endpoint, 0)
+		title, _, err := client.SummarizeContent(context.Background(), prompt, "content")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if inner.calls != 1 || !strings.HasSuffix(title, endpoint) {
+			t.Errorf("endpoint %d: got %q after %d requests, want the response of %s", i, title, inner.calls, endpoint)
+		}
+	}
+}
@@ -0,0 +1,362 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultMaxChunks is the default maximum number of chunks summarized by [NewChunkedClient].
+const DefaultMaxChunks = 16
+
+// chunkConcurrency is the maximum number of chunks of a content summarized concurrently.
+const chunkConcurrency = 4
+
+const (
+	chunkUserPrompt = `Below is a part of a long web page. Summarize this part for a reader who has not seen it: return a 3-6 word heading of the part as the title, and a single sentence of the topics it covers as the description.
+
+Return the response in JSON format:
+{
+    "title": "3-6 word heading",
+    "description": "single sentence summary"
+}`
+
+	chunkPromptNoteFmt = `This is part %d of %d of the page.`
+
+	reducePromptNoteFmt = `The page is too long to be read at once, so the content below is the summaries of its %d parts, in order. Base the response on ALL the parts.`
+)
+
+// chunker splits the contents longer than maxContentLength into chunks, and builds the requests summarizing the
+// chunks and reducing their summaries, for [NewChunkedClient] and [NewChunkedBatchClient].
+type chunker struct {
+	maxContentLength int
+	maxChunks        int
+	logger           *slog.Logger
+}
+
+func newChunker(maxContentLength, maxChunks int) chunker {
+	if maxChunks <= 0 {
+		maxChunks = DefaultMaxChunks
+	}
+	return chunker{
+		maxContentLength: maxContentLength,
+		maxChunks:        maxChunks,
+		logger:           slog.Default().WithGroup("chunked"),
+	}
+}
+
+// fits reports whether content is summarized as is.
+func (c *chunker) fits(content string) bool {
+	return c.maxContentLength <= 0 || len(content) <= c.maxContentLength
+}
+
+// split splits content into at most maxChunks chunks.
+func (c *chunker) split(ctx context.Context, content string) []string {
+	chunks := SplitMarkdown(content, c.maxContentLength)
+	if len(chunks) > c.maxChunks {
+		c.logger.DebugContext(ctx, "Truncating content to the maximum number of chunks", "chunks", len(chunks), "max_chunks", c.maxChunks)
+		chunks = chunks[:c.maxChunks]
+	}
+	return chunks
+}
+
+// chunkPrompt returns the prompt summarizing the chunk i of n of a content summarized with prompt.
+func chunkPrompt(prompt Prompt, i, n int) Prompt {
+	return Prompt{
+		System: prompt.System,
+		User:   chunkUserPrompt,
+		Suffix: fmt.Sprintf(chunkPromptNoteFmt, i+1, n),
+		Title:  WordRange{Min: 3, Max: 6},
+	}
+}
+
+// chunkSummary is the summary of a chunk.
+type chunkSummary struct {
+	title, description string
+	err                error
+}
+
+// reduce returns the prompt and the content generating the title and description of a content summarized with prompt
+// from the summaries of its chunks. The failed chunks are skipped; if all chunks failed, their errors are returned.
+func (c *chunker) reduce(ctx context.Context, prompt Prompt, summaries []chunkSummary) (Prompt, string, error) {
+	var (
+		sb    strings.Builder
+		parts int
+		errs  []error
+	)
+	for i, s := range summaries {
+		if s.err != nil {
+			c.logger.WarnContext(ctx, "Failed to summarize chunk, skipping it", "chunk", i+1, "chunks", len(summaries), "error", s.err)
+			errs = append(errs, fmt.Errorf("summarize chunk %d of %d: %w", i+1, len(summaries), s.err))
+			continue
+		}
+		parts++
+		fmt.Fprintf(&sb, "Part %d: %s\n%s\n\n", i+1, s.title, s.description)
+	}
+	if parts == 0 {
+		return Prompt{}, "", errors.Join(errs...)
+	}
+
+	return prompt.withSuffix(fmt.Sprintf(reducePromptNoteFmt, len(summaries))), sb.String(), nil
+}
+
+type chunkedClient struct {
+	client SummarizerClient
+	chunker
+}
+
+var _ SummarizerClient = (*chunkedClient)(nil)
+
+// NewChunkedClient wraps client so that a content longer than maxContentLength is summarized by map-reduce instead of
+// being truncated: the content is split into chunks at its Markdown headings (see [SplitMarkdown]), each chunk is
+// summarized, and the title and description are generated by prompt from the summaries of the chunks.
+//
+// At most maxChunks chunks are summarized, the rest of the content is truncated; zero means [DefaultMaxChunks].
+// A content within maxContentLength, or any content if maxContentLength is zero, is passed to client as is.
+func NewChunkedClient(client SummarizerClient, maxContentLength, maxChunks int) SummarizerClient {
+	return &chunkedClient{
+		client:  client,
+		chunker: newChunker(maxContentLength, maxChunks),
+	}
+}
+
+// SummarizeContent implements [SummarizerClient].
+func (c *chunkedClient) SummarizeContent(ctx context.Context, prompt Prompt, content string) (title, description string, err error) {
+	if c.fits(content) {
+		return c.client.SummarizeContent(ctx, prompt, content)
+	}
+
+	chunks := c.split(ctx, content)
+	summaries := make([]chunkSummary, len(chunks))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(chunkConcurrency)
+	for i, chunk := range chunks {
+		eg.Go(func() error {
+			title, description, err := c.client.SummarizeContent(egCtx, chunkPrompt(prompt, i, len(chunks)), chunk)
+			summaries[i] = chunkSummary{title: title, description: description, err: err}
+			// a failed chunk only leaves a gap in the summaries, unless the run is canceled
+			return ctx.Err()
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return "", "", err
+	}
+
+	reducePrompt, reduceContent, err := c.reduce(ctx, prompt, summaries)
+	if err != nil {
+		return "", "", err
+	}
+	return c.client.SummarizeContent(ctx, reducePrompt, reduceContent)
+}
+
+type chunkedBatchClient struct {
+	client BatchClient
+	chunker
+}
+
+var _ BatchClient = (*chunkedBatchClient)(nil)
+
+// NewChunkedBatchClient wraps client like [NewChunkedClient] in two batches: the first summarizes the contents within
+// maxContentLength and the chunks of the longer ones, and the second generates the title and description of the longer
+// contents from the summaries of their chunks.
+func NewChunkedBatchClient(client BatchClient, maxContentLength, maxChunks int) BatchClient {
+	return &chunkedBatchClient{
+		client:  client,
+		chunker: newChunker(maxContentLength, maxChunks),
+	}
+}
+
+// SummarizeBatch implements [BatchClient].
+func (c *chunkedBatchClient) SummarizeBatch(ctx context.Context, reqs []BatchRequest) ([]BatchResult, error) {
+	// first[i] are the indices in the first batch of the request of reqs[i] or of its chunks
+	first := make([][]int, len(reqs))
+	var mapReqs []BatchRequest
+	for i, req := range reqs {
+		if c.fits(req.Content) {
+			first[i] = []int{len(mapReqs)}
+			mapReqs = append(mapReqs, req)
+			continue
+		}
+		chunks := c.split(ctx, req.Content)
+		for j, chunk := range chunks {
+			first[i] = append(first[i], len(mapReqs))
+			mapReqs = append(mapReqs, BatchRequest{Prompt: chunkPrompt(req.Prompt, j, len(chunks)), Content: chunk})
+		}
+	}
+
+	mapResults, err := c.client.SummarizeBatch(ctx, mapReqs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(reqs))
+	// second[i] is the index in the second batch of the reduce request of reqs[i], or -1
+	second := make([]int, len(reqs))
+	var reduceReqs []BatchRequest
+	for i, req := range reqs {
+		second[i] = -1
+		if c.fits(req.Content) {
+			results[i] = mapResults[first[i][0]]
+			continue
+		}
+
+		summaries := make([]chunkSummary, len(first[i]))
+		for j, k := range first[i] {
+			r := mapResults[k]
+			results[i].Usage.Add(r.Usage)
+			summaries[j] = chunkSummary{title: r.Title, description: r.Description, err: r.Err}
+		}
+		reducePrompt, reduceContent, err := c.reduce(ctx, req.Prompt, summaries)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		second[i] = len(reduceReqs)
+		reduceReqs = append(reduceReqs, BatchRequest{Prompt: reducePrompt, Content: reduceContent})
+	}
+	if len(reduceReqs) == 0 {
+		return results, nil
+	}
+
+	reduceResults, err := c.client.SummarizeBatch(ctx, reduceReqs)
+	if err != nil {
+		return nil, err
+	}
+	for i, k := range second {
+		if k < 0 {
+			continue
+		}
+		r := reduceResults[k]
+		results[i].Title, results[i].Description, results[i].Err = r.Title, r.Description, r.Err
+		results[i].Usage.Add(r.Usage)
+	}
+
+	return results, nil
+}
+
+// SplitMarkdown splits content into chunks of at most maxLength bytes, at the Markdown headings where possible.
+//
+// The content is split into sections at each ATX heading outside of code fences, and consecutive sections are joined
+// into chunks up to maxLength. A section longer than maxLength is split at its blank lines, then at its line breaks,
+// and as a last resort at a UTF-8 character boundary. If maxLength is zero, content is returned as a single chunk.
+func SplitMarkdown(content string, maxLength int) []string {
+	if maxLength <= 0 || len(content) <= maxLength {
+		return []string{content}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+	}
+	for _, section := range splitHeadings(content) {
+		if current.Len()+len(section) > maxLength {
+			flush()
+		}
+		if len(section) <= maxLength {
+			current.WriteString(section)
+			continue
+		}
+		for _, piece := range splitLong(section, maxLength) {
+			if current.Len()+len(piece) > maxLength {
+				flush()
+			}
+			current.WriteString(piece)
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+// splitHeadings splits content before each ATX heading outside of code fences. The sections keep their line breaks.
+func splitHeadings(content string) []string {
+	var (
+		sections []string
+		start    int
+		fence    string
+	)
+	for offset := 0; offset < len(content); {
+		end := strings.IndexByte(content[offset:], '\n') + 1
+		if end == 0 {
+			end = len(content) - offset
+		}
+		line := strings.TrimLeft(content[offset:offset+end], " ")
+
+		switch {
+		case fence != "":
+			if strings.HasPrefix(line, fence) {
+				fence = ""
+			}
+		case strings.HasPrefix(line, "```"), strings.HasPrefix(line, "~~~"):
+			fence = line[:3]
+		case isATXHeading(line) && offset > start:
+			sections = append(sections, content[start:offset])
+			start = offset
+		}
+		offset += end
+	}
+
+	return append(sections, content[start:])
+}
+
+// isATXHeading reports whether line is a Markdown heading, such as "## Title".
+func isATXHeading(line string) bool {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 {
+		return false
+	}
+	rest := line[level:]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r'
+}
+
+// splitLong splits s into pieces of at most maxLength bytes, at blank lines, line breaks or characters, in that order
+// of preference. A separator in the first half of a piece is not used, so that a heading stays with its text.
+// The pieces keep their separators, so that joining them returns s.
+func splitLong(s string, maxLength int) []string {
+	var pieces []string
+	for len(s) > maxLength {
+		cut := strings.LastIndex(s[:maxLength], "\n\n") + 2
+		if cut <= maxLength/2 {
+			cut = strings.LastIndexByte(s[:maxLength], '\n') + 1
+		}
+		if cut <= maxLength/2 {
+			cut = maxLength
+			for cut > 0 && !utf8.RuneStart(s[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = maxLength
+			}
+		}
+		pieces = append(pieces, s[:cut])
+		s = s[cut:]
+	}
+
+	return append(pieces, s)
+}
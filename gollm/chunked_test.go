@@ -0,0 +1,402 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+// chunkRequest is a request received by a [summaryClient].
+type chunkRequest struct {
+	prompt  Prompt
+	content string
+}
+
+// summaryClient is a [SummarizerClient] and [BatchClient] safe for concurrent use, which summarizes a chunk by its
+// first line, the reduction of the summaries of the chunks as "Whole Page", and other contents as "Short Page".
+// Every request uses 100 input and 10 output tokens.
+type summaryClient struct {
+	// fail returns the error of the request of content, if not nil.
+	fail func(prompt Prompt, content string) error
+
+	mu       sync.Mutex
+	requests []chunkRequest
+	batches  []int
+}
+
+func (c *summaryClient) summarize(ctx context.Context, prompt Prompt, content string) BatchResult {
+	c.mu.Lock()
+	c.requests = append(c.requests, chunkRequest{prompt: prompt, content: content})
+	c.mu.Unlock()
+
+	r := BatchResult{Usage: Usage{Requests: 1, InputTokens: 100, OutputTokens: 10}}
+	StatsFromContext(ctx).add(r.Usage)
+	if c.fail != nil {
+		if r.Err = c.fail(prompt, content); r.Err != nil {
+			return r
+		}
+	}
+	switch {
+	case prompt.User == chunkUserPrompt:
+		heading, _, _ := strings.Cut(content, "\n")
+		r.Title, r.Description = "Chunk "+heading, "About "+heading+"."
+	case strings.HasPrefix(content, "Part "):
+		r.Title, r.Description = "Whole Page", "About the whole page."
+	default:
+		r.Title, r.Description = "Short Page", "About the short page."
+	}
+	return r
+}
+
+func (c *summaryClient) SummarizeContent(ctx context.Context, prompt Prompt, content string) (title, description string, err error) {
+	r := c.summarize(ctx, prompt, content)
+	return r.Title, r.Description, r.Err
+}
+
+func (c *summaryClient) SummarizeBatch(ctx context.Context, reqs []BatchRequest) ([]BatchResult, error) {
+	c.mu.Lock()
+	c.batches = append(c.batches, len(reqs))
+	c.mu.Unlock()
+
+	results := make([]BatchResult, len(reqs))
+	for i, req := range reqs {
+		results[i] = c.summarize(ctx, req.Prompt, req.Content)
+	}
+	return results, nil
+}
+
+// chunkedPage is a page of three short sections, split into a chunk per section by a maximum content length
+// of [chunkedPageMaxLength].
+const (
+	chunkedPage          = "# A\n\nThe first section text.\n\n# B\n\nThe second section text\n\n# C\n\nThe third section text.\n"
+	chunkedPageMaxLength = 40
+)
+
+// chunkedPagePrompt is the prompt summarizing pages in the tests of the chunked clients.
+var chunkedPagePrompt = Prompt{System: "system", User: "user", Suffix: "URL: https://example.com/"}
+
+// checkChunkRequests checks that requests are the requests summarizing the first n chunks of [chunkedPage] in any
+// order, followed by the request reducing the summaries of the chunks reduced, unless reduced is nil.
+func checkChunkRequests(t *testing.T, requests []chunkRequest, n int, reduced []int) {
+	t.Helper()
+
+	chunks := []string{"# A\n\nThe first section text.", "# B\n\nThe second section text", "# C\n\nThe third section text."}[:n]
+	want := len(chunks)
+	if reduced != nil {
+		want++
+	}
+	if len(requests) != want {
+		t.Fatalf("got %d requests, want %d", len(requests), want)
+	}
+
+	for _, req := range requests[:n] {
+		i := slices.Index(chunks, req.content)
+		if i < 0 {
+			t.Errorf("unexpected chunk %q", req.content)
+			continue
+		}
+		wantPrompt := Prompt{System: chunkedPagePrompt.System, User: chunkUserPrompt, Suffix: fmt.Sprintf("This is part %d of %d of the page.", i+1, n), Title: WordRange{Min: 3, Max: 6}}
+		if req.prompt != wantPrompt {
+			t.Errorf("prompt of chunk %d = %+v, want %+v", i+1, req.prompt, wantPrompt)
+		}
+	}
+
+	if reduced == nil {
+		return
+	}
+	reduce := requests[n]
+	wantPrompt := chunkedPagePrompt.withSuffix(fmt.Sprintf(reducePromptNoteFmt, n))
+	if reduce.prompt != wantPrompt {
+		t.Errorf("reduce prompt = %+v, want %+v", reduce.prompt, wantPrompt)
+	}
+	var wantContent strings.Builder
+	for _, i := range reduced {
+		heading, _, _ := strings.Cut(chunks[i], "\n")
+		fmt.Fprintf(&wantContent, "Part %d: Chunk %s\nAbout %s.\n\n", i+1, heading, heading)
+	}
+	if reduce.content != wantContent.String() {
+		t.Errorf("reduce content = %q, want %q", reduce.content, wantContent.String())
+	}
+}
+
+// checkChunks reports the chunks of content returned by [SplitMarkdown] which exceed maxLength, and whether the
+// chunks lost or reordered any of the content. Only the white space around the chunks may be dropped.
+func checkChunks(t *testing.T, content string, maxLength int, chunks []string) {
+	t.Helper()
+
+	if maxLength <= 0 || len(content) <= maxLength {
+		if len(chunks) != 1 || chunks[0] != content {
+			t.Fatalf("SplitMarkdown(%q, %d) = %q, want the content as a single chunk", content, maxLength, chunks)
+		}
+		return
+	}
+
+	var offset int
+	for i, chunk := range chunks {
+		next := -1
+		for j := offset; j <= len(content); j++ {
+			k := strings.Index(content[j:], chunk)
+			if k < 0 {
+				break
+			}
+			if strings.TrimSpace(content[offset:j+k]) == "" {
+				next = j + k
+				break
+			}
+			j += k
+		}
+		if next < 0 {
+			t.Fatalf("chunk %d %q does not follow the previous chunk in %q", i, chunk, content)
+		}
+		offset = next + len(chunk)
+
+		if len(chunk) > maxLength {
+			t.Errorf("chunk %d has %d bytes, want at most %d", i, len(chunk), maxLength)
+		}
+		if strings.TrimSpace(chunk) == "" {
+			t.Errorf("chunk %d is blank", i)
+		}
+		if utf8.ValidString(content) && maxLength >= utf8.UTFMax && !utf8.ValidString(chunk) {
+			t.Errorf("chunk %d splits a UTF-8 character: %q", i, chunk)
+		}
+	}
+	if rest := content[offset:]; strings.TrimSpace(rest) != "" {
+		t.Errorf("content %q is missing from the chunks", rest)
+	}
+}
+
+func TestSplitMarkdown(t *testing.T) {
+	tests := map[string]struct {
+		content   string
+		maxLength int
+		want      []string
+	}{
+		"fits": {
+			content:   "# Title\n\nText.\n",
+			maxLength: 100,
+			want:      []string{"# Title\n\nText.\n"},
+		},
+		"unlimited": {
+			content:   strings.Repeat("word ", 100),
+			maxLength: 0,
+			want:      []string{strings.Repeat("word ", 100)},
+		},
+		"headings": {
+			content:   "# One\n\nFirst section.\n\n## Two\n\nSecond section.\n\n## Three\n\nThird section.\n",
+			maxLength: 40,
+			want:      []string{"# One\n\nFirst section.", "## Two\n\nSecond section.", "## Three\n\nThird section."},
+		},
+		"sections are joined up to the limit": {
+			content:   "# A\n\na\n\n# B\n\nb\n\n# C\n\nc\n",
+			maxLength: 16,
+			want:      []string{"# A\n\na\n\n# B\n\nb", "# C\n\nc"},
+		},
+		"headings in code fences": {
+			content:   "# Install\n\n```sh\n# fetch the sources\ngit clone repo\n# build\nmake\n```\n\n# Usage\n\nRun it.\n",
+			maxLength: 70,
+			want:      []string{"# Install\n\n```sh\n# fetch the sources\ngit clone repo\n# build\nmake\n```", "# Usage\n\nRun it."},
+		},
+		"tilde fences": {
+			content:   "# Install\n\n~~~\n# not a heading\n~~~\n\n# Usage\n\nRun it.\n",
+			maxLength: 40,
+			want:      []string{"# Install\n\n~~~\n# not a heading\n~~~", "# Usage\n\nRun it."},
+		},
+		"long section at blank lines": {
+			content:   "# Title\n\n" + strings.Repeat("a", 20) + "\n\n" + strings.Repeat("b", 20) + "\n",
+			maxLength: 35,
+			want:      []string{"# Title\n\n" + strings.Repeat("a", 20), strings.Repeat("b", 20)},
+		},
+		"long line at characters": {
+			content:   strings.Repeat("é", 10),
+			maxLength: 5,
+			want:      []string{"éé", "éé", "éé", "éé", "éé"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := SplitMarkdown(tt.content, tt.maxLength)
+			checkChunks(t, tt.content, tt.maxLength, got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SplitMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func FuzzSplitMarkdown(f *testing.F) {
+	f.Add("# Title\n\nText.\n", 100)
+	f.Add("# One\n\nFirst section.\n\n## Two\n\nSecond section.\n", 20)
+	f.Add("# Install\n\n```sh\n# fetch the sources\ngit clone repo\n```\n\n# Usage\n\nRun it.\n", 30)
+	f.Add("```\n# unterminated fence\n\n# still code\n", 10)
+	f.Add("~~~\n```\n# inside\n~~~\n# outside\n", 8)
+	f.Add(strings.Repeat("日本語 ", 50), 7)
+	f.Add("#\n##\n###### six\n####### seven\n", 4)
+	f.Add("\n\n\n\r\n \t\n", 1)
+	f.Add("text\xe6\xac \xac", 6)
+
+	f.Fuzz(func(t *testing.T, content string, maxLength int) {
+		maxLength %= 1 << 12
+		checkChunks(t, content, maxLength, SplitMarkdown(content, maxLength))
+	})
+}
+
+func TestChunkedClient(t *testing.T) {
+	errChunk := errors.New("chunk failed")
+
+	tests := map[string]struct {
+		content   string
+		maxChunks int
+		// failChunk is the heading of the chunk whose request fails, if not empty.
+		failChunk  string
+		failAll    bool
+		failReduce bool
+		wantTitle  string
+		wantErr    string
+		// wantChunks are the number of chunks summarized, and wantReduced the indices of the chunks reduced.
+		wantChunks  int
+		wantReduced []int
+	}{
+		"short page": {
+			content:   "# A\n\nShort.",
+			wantTitle: "Short Page",
+		},
+		"long page": {
+			content:     chunkedPage,
+			wantTitle:   "Whole Page",
+			wantChunks:  3,
+			wantReduced: []int{0, 1, 2},
+		},
+		"failed chunk is skipped": {
+			content:     chunkedPage,
+			failChunk:   "# B",
+			wantTitle:   "Whole Page",
+			wantChunks:  3,
+			wantReduced: []int{0, 2},
+		},
+		"all chunks failed": {
+			content:    chunkedPage,
+			failAll:    true,
+			wantErr:    "summarize chunk 1 of 3: chunk failed",
+			wantChunks: 3,
+		},
+		"failed reduce": {
+			content:     chunkedPage,
+			failReduce:  true,
+			wantErr:     "reduce failed",
+			wantChunks:  3,
+			wantReduced: []int{0, 1, 2},
+		},
+		"truncated to the maximum chunks": {
+			content:     chunkedPage,
+			maxChunks:   2,
+			wantTitle:   "Whole Page",
+			wantChunks:  2,
+			wantReduced: []int{0, 1},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := &summaryClient{fail: func(prompt Prompt, content string) error {
+				switch {
+				case prompt.User != chunkUserPrompt && tt.failReduce:
+					return errors.New("reduce failed")
+				case prompt.User == chunkUserPrompt && (tt.failAll || (tt.failChunk != "" && strings.HasPrefix(content, tt.failChunk+"\n"))):
+					return errChunk
+				}
+				return nil
+			}}
+			client := NewChunkedClient(fake, chunkedPageMaxLength, tt.maxChunks)
+
+			stats := NewStats(nil)
+			title, _, err := client.SummarizeContent(WithStats(context.Background(), stats), chunkedPagePrompt, tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("SummarizeContent() error = %v, want %q", err, tt.wantErr)
+				}
+				if tt.failAll && !errors.Is(err, errChunk) {
+					t.Errorf("SummarizeContent() error = %v, want wrapping %v", err, errChunk)
+				}
+			} else if err != nil || title != tt.wantTitle {
+				t.Errorf("SummarizeContent() = (%q, %v), want %q", title, err, tt.wantTitle)
+			}
+
+			if tt.wantChunks == 0 {
+				if len(fake.requests) != 1 || fake.requests[0].prompt != chunkedPagePrompt || fake.requests[0].content != tt.content {
+					t.Errorf("requests = %+v, want the page as is", fake.requests)
+				}
+			} else {
+				checkChunkRequests(t, fake.requests, tt.wantChunks, tt.wantReduced)
+			}
+
+			// the usage of every chunk and of the reduction add up
+			n := len(fake.requests)
+			if got, want := stats.Usage(), (Usage{Requests: n, InputTokens: 100 * n, OutputTokens: 10 * n}); got != want {
+				t.Errorf("usage = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestChunkedBatchClient(t *testing.T) {
+	fake := &summaryClient{fail: func(prompt Prompt, content string) error {
+		if strings.HasPrefix(content, "# D\n") || strings.HasPrefix(content, "# E\n") {
+			return errors.New("chunk failed")
+		}
+		return nil
+	}}
+	client := NewChunkedBatchClient(fake, chunkedPageMaxLength, 0)
+
+	failedPage := "# D\n\nThe fourth section text.\n\n# E\n\nThe fifth section text.\n"
+	reqs := []BatchRequest{
+		{Prompt: chunkedPagePrompt, Content: "# A\n\nShort."},
+		{Prompt: chunkedPagePrompt, Content: chunkedPage},
+		{Prompt: chunkedPagePrompt, Content: failedPage},
+	}
+	results, err := client.SummarizeBatch(context.Background(), reqs)
+	if err != nil {
+		t.Fatalf("SummarizeBatch() error = %v", err)
+	}
+
+	// the first batch summarizes the short page and the chunks of the long ones, and the second reduces the long page
+	if want := []int{1 + 3 + 2, 1}; !slices.Equal(fake.batches, want) {
+		t.Errorf("got batches of %v requests, want %v", fake.batches, want)
+	}
+	checkChunkRequests(t, fake.requests[1:4], 3, nil)
+	if reduce := fake.requests[len(fake.requests)-1]; !strings.HasPrefix(reduce.content, "Part 1: Chunk # A\n") {
+		t.Errorf("reduce content = %q, want the summaries of the chunks of the long page", reduce.content)
+	}
+
+	if len(results) != len(reqs) {
+		t.Fatalf("got %d results, want %d", len(results), len(reqs))
+	}
+	if r := results[0]; r.Err != nil || r.Title != "Short Page" || r.Usage.Requests != 1 {
+		t.Errorf("result of the short page = %+v", r)
+	}
+	if r := results[1]; r.Err != nil || r.Title != "Whole Page" || r.Usage != (Usage{Requests: 4, InputTokens: 400, OutputTokens: 40}) {
+		t.Errorf("result of the long page = %+v, want the reduction with the usage of 3 chunks and the reduction", r)
+	}
+	if r := results[2]; r.Err == nil || !strings.Contains(r.Err.Error(), "summarize chunk 2 of 2: chunk failed") || r.Usage.Requests != 2 {
+		t.Errorf("result of the failed page = %+v, want the errors of its chunks", r)
+	}
+}
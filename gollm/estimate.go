@@ -0,0 +1,76 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+)
+
+const (
+	// bytesPerToken is the average number of bytes of a token of English prose and Markdown, used by
+	// [EstimateTokens].
+	bytesPerToken = 4
+
+	// messageTokens is the estimated number of tokens added to each request by the chat format.
+	messageTokens = 10
+
+	// EstimatedOutputTokens is the estimated number of tokens of a response: a JSON object of a short title and
+	// description. Reasoning models also bill their reasoning tokens, which are not included.
+	EstimatedOutputTokens = 50
+)
+
+// Placeholder title and description returned by the client of [NewEstimateClient].
+const (
+	EstimatedTitle       = "Estimated Page Title"
+	EstimatedDescription = "Placeholder description of the page estimated without calling the model."
+)
+
+// EstimateTokens returns the estimated number of tokens of s, without the tokenizer of any model.
+func EstimateTokens(s string) int {
+	return (len(s) + bytesPerToken - 1) / bytesPerToken
+}
+
+type estimateClient struct {
+	maxContentLength int
+}
+
+var _ SummarizerClient = (*estimateClient)(nil)
+
+// NewEstimateClient returns a [SummarizerClient] which calls no model. It records a request with the estimated tokens
+// of the prompt and the content truncated to maxContentLength in the [Stats] of the context, and returns
+// [EstimatedTitle] and [EstimatedDescription].
+//
+// Wrapping it with the same decorators as a real client, such as [NewChunkedClient], estimates the usage of a run
+// before launching it.
+func NewEstimateClient(maxContentLength int) SummarizerClient {
+	return &estimateClient{maxContentLength: maxContentLength}
+}
+
+// SummarizeContent implements [SummarizerClient].
+func (c *estimateClient) SummarizeContent(ctx context.Context, prompt Prompt, content string) (title, description string, err error) {
+	if c.maxContentLength > 0 && len(content) > c.maxContentLength {
+		content = content[:c.maxContentLength]
+	}
+
+	StatsFromContext(ctx).add(Usage{
+		Requests:     1,
+		InputTokens:  messageTokens + EstimateTokens(prompt.System) + EstimateTokens(prompt.userText()) + EstimateTokens(content),
+		OutputTokens: EstimatedOutputTokens,
+	})
+
+	return EstimatedTitle, EstimatedDescription, nil
+}
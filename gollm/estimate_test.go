@@ -0,0 +1,79 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := map[string]int{
+		"":          0,
+		"a":         1,
+		"abcd":      1,
+		"abcde":     2,
+		"日本語":       3,
+		"# Heading": 3,
+	}
+	for s, want := range tests {
+		if got := EstimateTokens(s); got != want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestEstimateClient(t *testing.T) {
+	prompt := Prompt{System: strings.Repeat("s", 40), User: strings.Repeat("u", 78), Suffix: "URL: https://example.com/"}
+	content := strings.Repeat("c", 1000)
+	promptTokens := 10 + 10 + (78+2+25+3)/4 // chat format, system, user and suffix joined by a blank line, rounded up
+
+	tests := map[string]struct {
+		maxContentLength int
+		wantInput        int
+	}{
+		"unlimited": {
+			maxContentLength: 0,
+			wantInput:        promptTokens + 250,
+		},
+		"truncated": {
+			maxContentLength: 400,
+			wantInput:        promptTokens + 100,
+		},
+		"shorter than the limit": {
+			maxContentLength: 2000,
+			wantInput:        promptTokens + 250,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			stats := NewStats(nil)
+			title, description, err := NewEstimateClient(tt.maxContentLength).SummarizeContent(WithStats(t.Context(), stats), prompt, content)
+			if err != nil {
+				t.Fatalf("SummarizeContent() error = %v", err)
+			}
+			if title != EstimatedTitle || description != EstimatedDescription {
+				t.Errorf("SummarizeContent() = %q, %q, want %q, %q", title, description, EstimatedTitle, EstimatedDescription)
+			}
+
+			want := Usage{Requests: 1, InputTokens: tt.wantInput, OutputTokens: EstimatedOutputTokens}
+			if got := stats.Usage(); got != want {
+				t.Errorf("SummarizeContent() usage = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
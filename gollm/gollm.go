@@ -18,12 +18,124 @@ package gollm
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+	"github.com/kaptinlin/jsonrepair"
+)
+
+// Default title and description used when the model returns an empty value.
+const (
+	DefaultTitle       = "Page"
+	DefaultDescription = "No description available"
 )
 
 // Prompt defines the structure of the prompt used for summarizing content.
 type Prompt struct {
 	System string
 	User   string
+
+	// Suffix is the part of the user prompt specific to the request, such as the URL of the page, sent after User.
+	// Keeping it out of User leaves System and User a static prefix shared by many requests, which Anthropic caches,
+	// see [AnthropicConfig.CacheTTL].
+	Suffix string
+
+	// Title and Description are the numbers of words the prompt requires of the title and description of the
+	// response, enforced by [NewValidateClient]. Zero values are unconstrained.
+	Title       WordRange
+	Description WordRange
+}
+
+// userText returns the user prompt of p, User followed by Suffix.
+func (p Prompt) userText() string {
+	if p.Suffix == "" {
+		return p.User
+	}
+	return p.User + "\n\n" + p.Suffix
+}
+
+// withSuffix returns a copy of p with s appended to its Suffix.
+func (p Prompt) withSuffix(s string) Prompt {
+	if p.Suffix == "" {
+		p.Suffix = s
+	} else {
+		p.Suffix += "\n\n" + s
+	}
+	return p
+}
+
+// Validate returns a [*ValidationError] if title or description violates the word ranges of p.
+func (p Prompt) Validate(title, description string) error {
+	var violations []string
+	if v := p.Title.check("title", title); v != "" {
+		violations = append(violations, v)
+	}
+	if v := p.Description.check("description", description); v != "" {
+		violations = append(violations, v)
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// WordRange is an inclusive range of the number of words of a text. A zero Max means no upper bound.
+type WordRange struct {
+	Min, Max int
+}
+
+// check returns the violation of r by the text s of field, or "".
+func (r WordRange) check(field, s string) string {
+	n := len(strings.Fields(s))
+	if n >= r.Min && (r.Max == 0 || n <= r.Max) {
+		return ""
+	}
+	want := fmt.Sprintf("%d-%d", r.Min, r.Max)
+	switch {
+	case r.Max == 0:
+		want = fmt.Sprintf("at least %d", r.Min)
+	case r.Min == r.Max:
+		want = strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("the %s has %d words instead of %s", field, n, want)
+}
+
+// ValidationError is the error of a response violating the constraints of its [Prompt].
+type ValidationError struct {
+	Violations []string
+}
+
+// Error implements [error].
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Violations, " and ")
+}
+
+// DescriptionRequest is the title and description of a response.
+type DescriptionRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// descriptionSchemaName is the name of [descriptionSchema] in the requests of structured outputs.
+const descriptionSchemaName = "summary"
+
+// descriptionSchema is the JSON schema of [DescriptionRequest], used for the structured outputs of the providers.
+var descriptionSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"title": map[string]any{
+			"type":        "string",
+			"description": "The title, as requested by the instructions.",
+		},
+		"description": map[string]any{
+			"type":        "string",
+			"description": "The description, as requested by the instructions.",
+		},
+	},
+	"required":             []string{"title", "description"},
+	"additionalProperties": false,
 }
 
 // Config contains the configuration for the client.
@@ -36,3 +148,31 @@ type SummarizerClient interface {
 	// SummarizeContent takes a context, a prompt, and the content to summarize,
 	SummarizeContent(ctx context.Context, prompt Prompt, content string) (title, description string, err error)
 }
+
+// parseDescription parses the JSON response of the model into [DescriptionRequest], repairing malformed JSON first.
+func parseDescription(content string) (*DescriptionRequest, error) {
+	if repaired, err := jsonrepair.JSONRepair(content); err == nil {
+		content = repaired
+	}
+
+	var result DescriptionRequest
+	if err := json.UnmarshalRead(strings.NewReader(content), &result); err != nil {
+		return nil, fmt.Errorf("parse JSON response: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (r *DescriptionRequest) titleOrDefault() string {
+	if r.Title == "" {
+		return DefaultTitle
+	}
+	return r.Title
+}
+
+func (r *DescriptionRequest) descriptionOrDefault() string {
+	if r.Description == "" {
+		return DefaultDescription
+	}
+	return r.Description
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-json-experiment/json"
+)
+
+// maxErrorBodySize is the maximum number of bytes of an error response body kept in [StatusError].
+const maxErrorBodySize = 4 << 10
+
+// StatusError is returned by the HTTP based clients when the server responds with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// Error implements [error].
+func (e *StatusError) Error() string {
+	body := strings.TrimSpace(e.Body)
+	if body == "" {
+		return fmt.Sprintf("unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("unexpected status %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), body)
+}
+
+// postJSON POSTs the JSON encoded req to url and decodes the JSON response into resp.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, req, resp any) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	for key, vals := range header {
+		httpReq.Header[key] = vals
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", url, err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		errBody, _ := io.ReadAll(io.LimitReader(httpResp.Body, maxErrorBodySize))
+		return &StatusError{
+			StatusCode: httpResp.StatusCode,
+			Header:     httpResp.Header,
+			Body:       string(errBody),
+		}
+	}
+
+	if err := json.UnmarshalRead(httpResp.Body, resp); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	return nil
+}
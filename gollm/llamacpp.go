@@ -0,0 +1,154 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// DefaultLlamaCppURL is the address of a local llama.cpp server.
+const DefaultLlamaCppURL = "http://127.0.0.1:8080"
+
+// LlamaCppConfig contains the configuration for the llama.cpp server client.
+type LlamaCppConfig struct {
+	Config
+
+	// BaseURL is the base URL of the llama.cpp server.
+	BaseURL string
+}
+
+type llamaCppClient struct {
+	httpClient       *http.Client
+	baseURL          string
+	apiKey           string
+	model            string
+	maxContentLength int
+	logger           *slog.Logger
+}
+
+var _ SummarizerClient = (*llamaCppClient)(nil)
+
+func init() {
+	Register(Provider{
+		Name:        ProviderLlamaCpp,
+		Description: "llama.cpp server (--llamacpp-url); an empty model uses the model loaded by the server",
+		Prices:      freePrices,
+		Endpoint: func(opts *Options) string {
+			return normalizeBaseURL(opts.LlamaCpp.BaseURL, DefaultLlamaCppURL)
+		},
+		New: func(model string, opts *Options) (SummarizerClient, error) {
+			return NewLlamaCppClient(opts.LlamaCpp.BaseURL, cmp.Or(opts.APIKey, opts.LlamaCpp.APIKey), model, opts.MaxContentLength), nil
+		},
+	})
+}
+
+// NewLlamaCppClient creates a new instance of [SummarizerClient] given the llama.cpp server URL, API key, model and maximum content length.
+//
+// apiKey is only required when the server is started with --api-key. model may be empty, in which case the
+// model loaded by the server is used.
+func NewLlamaCppClient(baseURL, apiKey, model string, maxContentLength int) *llamaCppClient {
+	return &llamaCppClient{
+		httpClient:       &http.Client{},
+		baseURL:          normalizeBaseURL(baseURL, DefaultLlamaCppURL),
+		apiKey:           apiKey,
+		model:            model,
+		maxContentLength: maxContentLength,
+		logger:           slog.Default().WithGroup("llamacpp"),
+	}
+}
+
+// llamaCppChatRequest is the request of the OpenAI compatible chat completions API of the llama.cpp server.
+//
+// See https://github.com/ggml-org/llama.cpp/tree/master/tools/server#post-v1chatcompletions-openai-compatible-chat-completions-api.
+type llamaCppChatRequest struct {
+	Model          string            `json:"model,omitempty"`
+	Messages       []ollamaMessage   `json:"messages"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+	Temperature    float64           `json:"temperature"`
+}
+
+type llamaCppChatResponse struct {
+	Choices []struct {
+		Message ollamaMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// SummarizeContent summarizes and generates a title and description for the given uri and content using a llama.cpp server.
+//
+// SummarizeContent implements [SummarizerClient].
+func (c *llamaCppClient) SummarizeContent(ctx context.Context, prompt Prompt, content string) (title, description string, err error) {
+	c.logger.DebugContext(ctx, "Summarizes description",
+		slog.String("model", c.model),
+		slog.Group("prompt",
+			slog.String("system", prompt.System),
+			slog.String("user", prompt.userText()),
+		),
+	)
+
+	if c.maxContentLength > 0 && len(content) > c.maxContentLength {
+		content = content[:c.maxContentLength]
+	}
+
+	req := &llamaCppChatRequest{
+		Model: c.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: prompt.System},
+			{Role: "user", Content: fmt.Sprintf("%s\n\nPage content:\n%s", prompt.userText(), content)},
+		},
+		ResponseFormat: map[string]string{
+			"type": "json_object",
+		},
+	}
+
+	header := make(http.Header)
+	if c.apiKey != "" {
+		header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	var resp llamaCppChatResponse
+	if err := postJSON(ctx, c.httpClient, c.baseURL+"/v1/chat/completions", header, req, &resp); err != nil {
+		c.logger.ErrorContext(ctx, "Failed to generate description", slog.Any("error", err))
+		return "", "", fmt.Errorf("generate description: %w", err)
+	}
+	addTokens(ctx, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	if len(resp.Choices) == 0 {
+		c.logger.ErrorContext(ctx, "No choices returned from llama.cpp")
+		return "", "", fmt.Errorf("no choices returned")
+	}
+
+	content = resp.Choices[0].Message.Content
+	if content == "" {
+		c.logger.ErrorContext(ctx, "Empty content returned from llama.cpp")
+		return "", "", fmt.Errorf("empty content returned")
+	}
+
+	result, err := parseDescription(content)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Failed to parse JSON response", slog.String("content", content), slog.Any("error", err))
+		return "", "", err
+	}
+
+	return result.titleOrDefault(), result.descriptionOrDefault(), nil
+}
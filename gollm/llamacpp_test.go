@@ -0,0 +1,76 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLlamaCppClientSummarizeContent(t *testing.T) {
+	tests := map[string]struct {
+		status          int
+		body            string
+		wantTitle       string
+		wantDescription string
+		wantErr         string
+	}{
+		"success": {
+			status:          http.StatusOK,
+			body:            `{"choices":[{"message":{"role":"assistant","content":"{\"title\":\"Guide\",\"description\":\"How to start.\"}"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`,
+			wantTitle:       "Guide",
+			wantDescription: "How to start.",
+		},
+		"empty content": {
+			status:  http.StatusOK,
+			body:    `{"choices":[{"message":{"role":"assistant","content":""}}]}`,
+			wantErr: "empty content returned",
+		},
+		"no choices": {
+			status:  http.StatusOK,
+			body:    `{"choices":[]}`,
+			wantErr: "no choices returned",
+		},
+		"unauthorized": {
+			status:  http.StatusUnauthorized,
+			body:    `{"error":{"code":401,"message":"Invalid API Key","type":"authentication_error"}}`,
+			wantErr: "unexpected status 401 Unauthorized",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newChatServer(t, "/v1/chat/completions", tt.status, tt.body)
+			client := NewLlamaCppClient(srv.URL, "test-key", "", 0)
+
+			title, description, err := client.SummarizeContent(context.Background(), Prompt{System: "system", User: "user"}, "content")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SummarizeContent() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SummarizeContent() error = %v", err)
+			}
+			if title != tt.wantTitle || description != tt.wantDescription {
+				t.Errorf("SummarizeContent() = (%q, %q), want (%q, %q)", title, description, tt.wantTitle, tt.wantDescription)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// DefaultOllamaHost is the address of a local Ollama server.
+const DefaultOllamaHost = "http://127.0.0.1:11434"
+
+// OllamaConfig contains the configuration for the Ollama client.
+type OllamaConfig struct {
+	Config
+
+	// Host is the base URL of the Ollama server. The scheme may be omitted as in OLLAMA_HOST.
+	Host string
+}
+
+type ollamaClient struct {
+	httpClient       *http.Client
+	baseURL          string
+	model            string
+	maxContentLength int
+	logger           *slog.Logger
+}
+
+var _ SummarizerClient = (*ollamaClient)(nil)
+
+func init() {
+	Register(Provider{
+		Name:        ProviderOllama,
+		Description: "Ollama server (--ollama-host); any model pulled on the server",
+		Prices:      freePrices,
+		Endpoint: func(opts *Options) string {
+			return normalizeBaseURL(opts.Ollama.Host, DefaultOllamaHost)
+		},
+		New: func(model string, opts *Options) (SummarizerClient, error) {
+			if model == "" {
+				return nil, fmt.Errorf("model name is required")
+			}
+			return NewOllamaClient(opts.Ollama.Host, model, opts.MaxContentLength), nil
+		},
+	})
+}
+
+// NewOllamaClient creates a new instance of [SummarizerClient] given the Ollama host, model and maximum content length.
+func NewOllamaClient(host, model string, maxContentLength int) *ollamaClient {
+	return &ollamaClient{
+		httpClient:       &http.Client{},
+		baseURL:          normalizeBaseURL(host, DefaultOllamaHost),
+		model:            model,
+		maxContentLength: maxContentLength,
+		logger:           slog.Default().WithGroup("ollama"),
+	}
+}
+
+// ollamaMessage is a message of the Ollama chat API.
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ollamaChatRequest is the request of the Ollama chat API.
+//
+// See https://github.com/ollama/ollama/blob/main/docs/api.md#generate-a-chat-completion.
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Format   string          `json:"format,omitempty"`
+	Options  map[string]any  `json:"options,omitempty"`
+}
+
+// ollamaChatResponse is the non-streaming response of the Ollama chat API.
+type ollamaChatResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+}
+
+// SummarizeContent summarizes and generates a title and description for the given uri and content using a local Ollama model.
+//
+// SummarizeContent implements [SummarizerClient].
+func (c *ollamaClient) SummarizeContent(ctx context.Context, prompt Prompt, content string) (title, description string, err error) {
+	c.logger.DebugContext(ctx, "Summarizes description",
+		slog.String("model", c.model),
+		slog.Group("prompt",
+			slog.String("system", prompt.System),
+			slog.String("user", prompt.userText()),
+		),
+	)
+
+	if c.maxContentLength > 0 && len(content) > c.maxContentLength {
+		content = content[:c.maxContentLength]
+	}
+
+	req := &ollamaChatRequest{
+		Model: c.model,
+		Messages: []ollamaMessage{
+			{Role: "system", Content: prompt.System},
+			{Role: "user", Content: fmt.Sprintf("%s\n\nPage content:\n%s", prompt.userText(), content)},
+		},
+		Stream: false,
+		Format: "json",
+		Options: map[string]any{
+			"temperature": 0,
+		},
+	}
+
+	var resp ollamaChatResponse
+	if err := postJSON(ctx, c.httpClient, c.baseURL+"/api/chat", nil, req, &resp); err != nil {
+		c.logger.ErrorContext(ctx, "Failed to generate description", slog.Any("error", err))
+		return "", "", fmt.Errorf("generate description: %w", err)
+	}
+	addTokens(ctx, resp.PromptEvalCount, resp.EvalCount)
+
+	if resp.Message.Content == "" {
+		c.logger.ErrorContext(ctx, "Empty content returned from Ollama")
+		return "", "", fmt.Errorf("empty content returned")
+	}
+
+	content = resp.Message.Content
+	result, err := parseDescription(content)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Failed to parse JSON response", slog.String("content", content), slog.Any("error", err))
+		return "", "", err
+	}
+
+	return result.titleOrDefault(), result.descriptionOrDefault(), nil
+}
+
+// normalizeBaseURL returns the base URL of host without the trailing slash, defaulting to fallback
+// and to the http scheme when host has none.
+func normalizeBaseURL(host, fallback string) string {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		host = fallback
+	}
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return strings.TrimRight(host, "/")
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOllamaClientSummarizeContent(t *testing.T) {
+	tests := map[string]struct {
+		status          int
+		body            string
+		wantTitle       string
+		wantDescription string
+		wantErr         string
+		wantRetryable   bool
+	}{
+		"success": {
+			status:          http.StatusOK,
+			body:            `{"model":"llama3","message":{"role":"assistant","content":"{\"title\":\"Guide\",\"description\":\"How to start.\"}"},"done":true,"prompt_eval_count":12,"eval_count":3}`,
+			wantTitle:       "Guide",
+			wantDescription: "How to start.",
+		},
+		"missing fields": {
+			status:          http.StatusOK,
+			body:            `{"model":"llama3","message":{"role":"assistant","content":"{}"},"done":true}`,
+			wantTitle:       DefaultTitle,
+			wantDescription: DefaultDescription,
+		},
+		"empty content": {
+			status:  http.StatusOK,
+			body:    `{"model":"llama3","message":{"role":"assistant","content":""},"done":true}`,
+			wantErr: "empty content returned",
+		},
+		"model not found": {
+			status:  http.StatusNotFound,
+			body:    `{"error":"model \"llama3\" not found, try pulling it first"}`,
+			wantErr: "unexpected status 404 Not Found",
+		},
+		"server error": {
+			status:        http.StatusServiceUnavailable,
+			body:          `{"error":"server busy"}`,
+			wantErr:       "unexpected status 503 Service Unavailable",
+			wantRetryable: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newChatServer(t, "/api/chat", tt.status, tt.body)
+			client := NewOllamaClient(srv.URL, "llama3", 0)
+
+			title, description, err := client.SummarizeContent(context.Background(), Prompt{System: "system", User: "user"}, "content")
+			if len(srv.requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(srv.requests))
+			}
+			if got := srv.requests[0]["stream"]; got != false {
+				t.Errorf("request stream = %v, want false", got)
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SummarizeContent() error = %v, want containing %q", err, tt.wantErr)
+				}
+				if got := IsRetryable(err); got != tt.wantRetryable {
+					t.Errorf("IsRetryable(%v) = %t, want %t", err, got, tt.wantRetryable)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SummarizeContent() error = %v", err)
+			}
+			if title != tt.wantTitle || description != tt.wantDescription {
+				t.Errorf("SummarizeContent() = (%q, %q), want (%q, %q)", title, description, tt.wantTitle, tt.wantDescription)
+			}
+		})
+	}
+}
@@ -14,102 +14,270 @@
 //
 // SPDX-License-Identifier: Apache-2.0
 
-// OpenAI models from https://github.com/openai/openai-go/blob/v2.0.2/shared/shared.go#L23-L86.
-// "gpt-5"
-// "gpt-5-mini"
-// "gpt-5-nano"
-// "gpt-5-2025-08-07"
-// "gpt-5-mini-2025-08-07"
-// "gpt-5-nano-2025-08-07"
-// "gpt-5-chat-latest"
-// "gpt-4.1"
-// "gpt-4.1-mini"
-// "gpt-4.1-nano"
-// "gpt-4.1-2025-04-14"
-// "gpt-4.1-mini-2025-04-14"
-// "gpt-4.1-nano-2025-04-14"
-// "o4-mini"
-// "o4-mini-2025-04-16"
-// "o3"
-// "o3-2025-04-16"
-// "o3-mini"
-// "o3-mini-2025-01-31"
-// "o1"
-// "o1-2024-12-17"
-// "o1-preview"
-// "o1-preview-2024-09-12"
-// "o1-mini"
-// "o1-mini-2024-09-12"
-// "gpt-4o"
-// "gpt-4o-2024-11-20"
-// "gpt-4o-2024-08-06"
-// "gpt-4o-2024-05-13"
-// "gpt-4o-audio-preview"
-// "gpt-4o-audio-preview-2024-10-01"
-// "gpt-4o-audio-preview-2024-12-17"
-// "gpt-4o-audio-preview-2025-06-03"
-// "gpt-4o-mini-audio-preview"
-// "gpt-4o-mini-audio-preview-2024-12-17"
-// "gpt-4o-search-preview"
-// "gpt-4o-mini-search-preview"
-// "gpt-4o-search-preview-2025-03-11"
-// "gpt-4o-mini-search-preview-2025-03-11"
-// "chatgpt-4o-latest"
-// "codex-mini-latest"
-// "gpt-4o-mini"
-// "gpt-4o-mini-2024-07-18"
-// "gpt-4-turbo"
-// "gpt-4-turbo-2024-04-09"
-// "gpt-4-0125-preview"
-// "gpt-4-turbo-preview"
-// "gpt-4-1106-preview"
-// "gpt-4-vision-preview"
-// "gpt-4"
-// "gpt-4-0314"
-// "gpt-4-0613"
-// "gpt-4-32k"
-// "gpt-4-32k-0314"
-// "gpt-4-32k-0613"
-// "gpt-3.5-turbo"
-// "gpt-3.5-turbo-16k"
-// "gpt-3.5-turbo-0301"
-// "gpt-3.5-turbo-0613"
-// "gpt-3.5-turbo-1106"
-// "gpt-3.5-turbo-0125"
-// "gpt-3.5-turbo-16k-0613"
-
 package gollm
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 
-	"github.com/go-json-experiment/json"
-	"github.com/kaptinlin/jsonrepair"
 	openai "github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
 	"github.com/openai/openai-go/v2/shared"
 )
 
+// DefaultAzureAPIVersion is the Azure OpenAI API version used when [OpenAIConfig.AzureAPIVersion] is empty.
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// OpenAI models from https://github.com/openai/openai-go/blob/v2.0.2/shared/shared.go#L23-L86.
+var openAIModels = []string{
+	"gpt-5",
+	"gpt-5-mini",
+	"gpt-5-nano",
+	"gpt-5-2025-08-07",
+	"gpt-5-mini-2025-08-07",
+	"gpt-5-nano-2025-08-07",
+	"gpt-5-chat-latest",
+	"gpt-4.1",
+	"gpt-4.1-mini",
+	"gpt-4.1-nano",
+	"gpt-4.1-2025-04-14",
+	"gpt-4.1-mini-2025-04-14",
+	"gpt-4.1-nano-2025-04-14",
+	"o4-mini",
+	"o4-mini-2025-04-16",
+	"o3",
+	"o3-2025-04-16",
+	"o3-mini",
+	"o3-mini-2025-01-31",
+	"o1",
+	"o1-2024-12-17",
+	"o1-preview",
+	"o1-preview-2024-09-12",
+	"o1-mini",
+	"o1-mini-2024-09-12",
+	"gpt-4o",
+	"gpt-4o-2024-11-20",
+	"gpt-4o-2024-08-06",
+	"gpt-4o-2024-05-13",
+	"gpt-4o-audio-preview",
+	"gpt-4o-audio-preview-2024-10-01",
+	"gpt-4o-audio-preview-2024-12-17",
+	"gpt-4o-audio-preview-2025-06-03",
+	"gpt-4o-mini-audio-preview",
+	"gpt-4o-mini-audio-preview-2024-12-17",
+	"gpt-4o-search-preview",
+	"gpt-4o-mini-search-preview",
+	"gpt-4o-search-preview-2025-03-11",
+	"gpt-4o-mini-search-preview-2025-03-11",
+	"chatgpt-4o-latest",
+	"codex-mini-latest",
+	"gpt-4o-mini",
+	"gpt-4o-mini-2024-07-18",
+	"gpt-4-turbo",
+	"gpt-4-turbo-2024-04-09",
+	"gpt-4-0125-preview",
+	"gpt-4-turbo-preview",
+	"gpt-4-1106-preview",
+	"gpt-4-vision-preview",
+	"gpt-4",
+	"gpt-4-0314",
+	"gpt-4-0613",
+	"gpt-4-32k",
+	"gpt-4-32k-0314",
+	"gpt-4-32k-0613",
+	"gpt-3.5-turbo",
+	"gpt-3.5-turbo-16k",
+	"gpt-3.5-turbo-0301",
+	"gpt-3.5-turbo-0613",
+	"gpt-3.5-turbo-1106",
+	"gpt-3.5-turbo-0125",
+	"gpt-3.5-turbo-16k-0613",
+}
+
+// openAIUnstructuredModels are the model name prefixes of the OpenAI models without structured outputs.
+// See https://platform.openai.com/docs/guides/structured-outputs#supported-models.
+var openAIUnstructuredModels = []string{
+	"gpt-3.5-",
+	"gpt-4-",
+	"gpt-4o-2024-05-13",
+	"chatgpt-4o-latest",
+	"o1-mini",
+	"o1-preview",
+}
+
+// supportsStructuredOutputs reports whether model supports the json_schema response format. The models of the
+// OpenAI-compatible endpoints are assumed to support it.
+func supportsStructuredOutputs(model string) bool {
+	if model == "gpt-4" {
+		return false
+	}
+	return !slices.ContainsFunc(openAIUnstructuredModels, func(prefix string) bool {
+		return strings.HasPrefix(model, prefix)
+	})
+}
+
+// openAIMaxCompletionTokens is the max_completion_tokens sent to the OpenAI reasoning models, leaving room for their
+// reasoning tokens. See https://platform.openai.com/docs/guides/reasoning#allocating-space-for-reasoning.
+const openAIMaxCompletionTokens = 25000
+
+// isOpenAIReasoningModel reports whether model is an OpenAI reasoning model, which supports the max_completion_tokens
+// and verbosity parameters.
+func isOpenAIReasoningModel(model string) bool {
+	if strings.HasPrefix(model, "gpt-5-chat") {
+		return false
+	}
+	return slices.ContainsFunc([]string{"gpt-5", "o1", "o3", "o4"}, func(prefix string) bool {
+		return strings.HasPrefix(model, prefix)
+	})
+}
+
 // OpenAIConfig contains the configuration for the OpenAI client.
 type OpenAIConfig struct {
 	Config
+
+	// BaseURL is the base URL of an OpenAI-compatible API such as vLLM, LiteLLM or OpenRouter.
+	// Empty uses the OpenAI API.
+	BaseURL string
+
+	// Organization and Project are sent as the OpenAI-Organization and OpenAI-Project headers.
+	Organization string
+	Project      string
+
+	// Headers are extra HTTP headers sent with every request.
+	Headers map[string]string
+
+	// AzureEndpoint is the Azure OpenAI resource endpoint, e.g. "https://<resource>.openai.azure.com".
+	// If set, requests are sent to AzureDeployment instead of BaseURL.
+	AzureEndpoint   string
+	AzureDeployment string
+	AzureAPIVersion string
+
+	// MaxCompletionTokens is the max_completion_tokens of the requests. Zero sends a cap leaving room for the reasoning
+	// tokens to the reasoning models of the OpenAI API, and none to the other models and to custom endpoints.
+	MaxCompletionTokens int
+
+	// Verbosity is the verbosity of the responses: "low", "medium" or "high". Empty sends "high" to the GPT-5 reasoning
+	// models of the OpenAI API, and none to the other models and to custom endpoints, which may reject it.
+	Verbosity string
+}
+
+// IsAzure reports whether c targets an Azure OpenAI endpoint.
+func (c *OpenAIConfig) IsAzure() bool {
+	return c.AzureEndpoint != ""
+}
+
+// IsCustomEndpoint reports whether c targets an endpoint other than the OpenAI API,
+// which may serve models with arbitrary names.
+func (c *OpenAIConfig) IsCustomEndpoint() bool {
+	return c.BaseURL != "" || c.IsAzure()
+}
+
+// RequestOptions returns the [option.RequestOption] for the endpoint, organization, project and headers of c.
+//
+// For Azure OpenAI, apiKey is sent as the Api-Key header instead of the Authorization header.
+func (c *OpenAIConfig) RequestOptions(apiKey string) []option.RequestOption {
+	var opts []option.RequestOption
+
+	switch {
+	case c.IsAzure():
+		endpoint := strings.TrimRight(c.AzureEndpoint, "/") + "/openai/deployments/" + c.AzureDeployment + "/"
+		opts = append(opts,
+			option.WithBaseURL(endpoint),
+			option.WithQuery("api-version", cmp.Or(c.AzureAPIVersion, DefaultAzureAPIVersion)),
+			option.WithHeaderDel("Authorization"),
+			option.WithHeader("Api-Key", apiKey),
+		)
+	case c.BaseURL != "":
+		opts = append(opts, option.WithBaseURL(c.BaseURL))
+	}
+
+	if c.Organization != "" {
+		opts = append(opts, option.WithOrganization(c.Organization))
+	}
+	if c.Project != "" {
+		opts = append(opts, option.WithProject(c.Project))
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.Headers)) {
+		opts = append(opts, option.WithHeader(key, c.Headers[key]))
+	}
+
+	return opts
 }
 
 type openaiClient struct {
 	client           *openai.Client
 	model            string
 	maxContentLength int
-	logger           *slog.Logger
+	// customEndpoint is set if the client targets an endpoint other than the OpenAI API, which may not support the
+	// parameters specific to the OpenAI models.
+	customEndpoint      bool
+	maxCompletionTokens int
+	verbosity           string
+	logger              *slog.Logger
 }
 
 var _ SummarizerClient = (*openaiClient)(nil)
 
+func init() {
+	Register(Provider{
+		Name:        ProviderOpenAI,
+		Description: "OpenAI and OpenAI-compatible APIs (--openai-base-url, --azure-endpoint)",
+		Prefixes:    []string{"chatgpt-", "codex-", "gpt-", "o1", "o3", "o4"},
+		Models:      openAIModels,
+		Prices:      openAIPrices,
+		Fallback: func(opts *Options) bool {
+			// OpenAI-compatible endpoints serve models with arbitrary names
+			return opts != nil && opts.OpenAI.IsCustomEndpoint()
+		},
+		Endpoint: func(opts *Options) string {
+			if opts.OpenAI.IsAzure() {
+				// the deployment defaults to the model, which is part of the cache key already
+				return strings.TrimRight(opts.OpenAI.AzureEndpoint, "/") + "/openai/deployments/" + opts.OpenAI.AzureDeployment
+			}
+			return opts.OpenAI.BaseURL
+		},
+		New: func(model string, opts *Options) (SummarizerClient, error) {
+			if model == "" {
+				return nil, fmt.Errorf("model name is required")
+			}
+			cfg := opts.OpenAI
+			if cfg.IsAzure() && cfg.AzureDeployment == "" {
+				cfg.AzureDeployment = model
+			}
+			apiKey := cmp.Or(opts.APIKey, cfg.APIKey)
+			reqOpts := append(cfg.RequestOptions(apiKey), option.WithMaxRetries(0))
+			c := NewOpenAIClient(apiKey, model, opts.MaxContentLength, reqOpts...)
+			c.setCompletionOptions(&cfg)
+			return c, nil
+		},
+		NewBatch: func(model string, opts *Options) (BatchClient, error) {
+			if model == "" {
+				return nil, fmt.Errorf("model name is required")
+			}
+			cfg := opts.OpenAI
+			if cfg.IsAzure() {
+				return nil, fmt.Errorf("batch API of Azure OpenAI is not supported")
+			}
+			apiKey := cmp.Or(opts.APIKey, cfg.APIKey)
+			c := NewOpenAIBatchClient(apiKey, model, opts.MaxContentLength, opts.Batch, cfg.RequestOptions(apiKey)...)
+			c.setCompletionOptions(&cfg)
+			return c, nil
+		},
+	})
+}
+
 // NewOpenAIClient creates a new instance of [SummarizerClient] given the API key, model, maximum content length and request options.
+//
+// The opts are applied after the API key, so they may point the client at any OpenAI-compatible endpoint.
+// See [OpenAIConfig.RequestOptions].
 func NewOpenAIClient(apiKey, model string, maxContentLength int, opts ...option.RequestOption) *openaiClient {
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+	// [option.RequestOption] are last win
+	client := openai.NewClient(append([]option.RequestOption{option.WithAPIKey(apiKey)}, opts...)...)
 
 	return &openaiClient{
 		client:           &client,
@@ -119,9 +287,11 @@ func NewOpenAIClient(apiKey, model string, maxContentLength int, opts ...option.
 	}
 }
 
-type DescriptionRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+// setCompletionOptions sets the endpoint and the completion parameters of cfg.
+func (c *openaiClient) setCompletionOptions(cfg *OpenAIConfig) {
+	c.customEndpoint = cfg.IsCustomEndpoint()
+	c.maxCompletionTokens = cfg.MaxCompletionTokens
+	c.verbosity = cfg.Verbosity
 }
 
 // SummarizeContent summarizes and generates a title and description for the given uri and content using OpenAI LLM model.
@@ -132,10 +302,23 @@ func (c *openaiClient) SummarizeContent(ctx context.Context, prompt Prompt, cont
 		slog.String("model", c.model),
 		slog.Group("prompt",
 			slog.String("system", prompt.System),
-			slog.String("user", prompt.User),
+			slog.String("user", prompt.userText()),
 		),
 	)
 
+	chatCompletion, err := c.client.Chat.Completions.New(ctx, c.completionParams(prompt, content))
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Failed to generate description", slog.Any("error", err))
+		return "", "", fmt.Errorf("generate description: %w", err)
+	}
+	addTokens(ctx, int(chatCompletion.Usage.PromptTokens), int(chatCompletion.Usage.CompletionTokens))
+
+	return c.parseCompletion(ctx, chatCompletion)
+}
+
+// completionParams returns the parameters of the chat completion summarizing content with prompt, truncated to the
+// maximum content length.
+func (c *openaiClient) completionParams(prompt Prompt, content string) openai.ChatCompletionNewParams {
 	if c.maxContentLength > 0 && len(content) > c.maxContentLength {
 		content = content[:c.maxContentLength]
 	}
@@ -144,13 +327,37 @@ func (c *openaiClient) SummarizeContent(ctx context.Context, prompt Prompt, cont
 		Model: c.model,
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage(prompt.System),
-			openai.UserMessage(fmt.Sprintf("%s\n\nPage content:\n%s", prompt.User, content)),
+			openai.UserMessage(fmt.Sprintf("%s\n\nPage content:\n%s", prompt.userText(), content)),
 		},
 		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
-			OfText: openai.Ptr(shared.NewResponseFormatTextParam()),
+			OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   descriptionSchemaName,
+					Schema: descriptionSchema,
+					Strict: openai.Bool(true),
+				},
+			},
 		},
-		Verbosity:           openai.ChatCompletionNewParamsVerbosityHigh,
-		MaxCompletionTokens: openai.Int(25000), // https://platform.openai.com/docs/guides/reasoning#allocating-space-for-reasoning
+	}
+	// the compatible servers and the older models reject the parameters they don't know or a cap above their limit
+	reasoning := !c.customEndpoint && isOpenAIReasoningModel(c.model)
+	switch {
+	case c.maxCompletionTokens > 0:
+		params.MaxCompletionTokens = openai.Int(int64(c.maxCompletionTokens))
+	case reasoning:
+		params.MaxCompletionTokens = openai.Int(openAIMaxCompletionTokens)
+	}
+	switch {
+	case c.verbosity != "":
+		params.Verbosity = openai.ChatCompletionNewParamsVerbosity(c.verbosity)
+	case reasoning && strings.HasPrefix(c.model, "gpt-5"):
+		params.Verbosity = openai.ChatCompletionNewParamsVerbosityHigh
+	}
+	if !supportsStructuredOutputs(c.model) {
+		// the JSON is only requested by the prompt
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfText: openai.Ptr(shared.NewResponseFormatTextParam()),
+		}
 	}
 	switch {
 	case strings.HasPrefix(c.model, "gpt-5"):
@@ -159,48 +366,36 @@ func (c *openaiClient) SummarizeContent(ctx context.Context, prompt Prompt, cont
 	case strings.HasPrefix(c.model, "gpt"):
 		// nothing to do
 
-	case strings.HasPrefix(c.model, "o"):
+	case strings.HasPrefix(c.model, "o1"), strings.HasPrefix(c.model, "o3"), strings.HasPrefix(c.model, "o4"):
 		params.ReasoningEffort = openai.ReasoningEffortHigh
 	}
 
-	chatCompletion, err := c.client.Chat.Completions.New(ctx, params)
-	if err != nil {
-		c.logger.ErrorContext(ctx, "Failed to generate description", slog.Any("error", err))
-		return "", "", fmt.Errorf("generate description: %w", err)
-	}
+	return params
+}
+
+// parseCompletion parses the title and description of the first choice of chatCompletion.
+func (c *openaiClient) parseCompletion(ctx context.Context, chatCompletion *openai.ChatCompletion) (title, description string, err error) {
 	if len(chatCompletion.Choices) == 0 {
 		c.logger.ErrorContext(ctx, "No choices returned from OpenAI")
 		return "", "", fmt.Errorf("no choices returned")
 	}
 
-	content = chatCompletion.Choices[0].Message.Content
+	if refusal := chatCompletion.Choices[0].Message.Refusal; refusal != "" {
+		c.logger.ErrorContext(ctx, "Request refused by OpenAI", slog.String("refusal", refusal))
+		return "", "", fmt.Errorf("request refused: %s", refusal)
+	}
+
+	content := chatCompletion.Choices[0].Message.Content
 	if content == "" {
 		c.logger.ErrorContext(ctx, "Empty content returned from OpenAI")
-		return "", "", fmt.Errorf("empty content returned for")
+		return "", "", fmt.Errorf("empty content returned for %s", c.model)
 	}
 
-	content, err = jsonrepair.JSONRepair(content)
+	result, err := parseDescription(content)
 	if err != nil {
-		c.logger.ErrorContext(ctx, "Repair JSON payload dailed", slog.Any("error", err))
-	}
-
-	var result DescriptionRequest
-	opts := json.JoinOptions(
-		json.DiscardUnknownMembers(true), // strictly parsing
-	)
-	if err := json.UnmarshalRead(strings.NewReader(content), &result, opts); err != nil {
 		c.logger.ErrorContext(ctx, "Failed to parse JSON response", slog.String("content", content), slog.Any("error", err))
-		return "", "", fmt.Errorf("parse JSON response: %w", err)
-	}
-
-	title = result.Title
-	if title == "" {
-		title = "Page"
-	}
-	description = result.Description
-	if description == "" {
-		description = "No description available"
+		return "", "", err
 	}
 
-	return title, description, nil
+	return result.titleOrDefault(), result.descriptionOrDefault(), nil
 }
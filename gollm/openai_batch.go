@@ -0,0 +1,236 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	openai "github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// openaiBatchLine is a request line of the input file of an OpenAI batch.
+type openaiBatchLine struct {
+	CustomID string                         `json:"custom_id"`
+	Method   string                         `json:"method"`
+	URL      string                         `json:"url"`
+	Body     openai.ChatCompletionNewParams `json:"body"`
+}
+
+// openaiBatchOutput is a line of the output or error file of an OpenAI batch.
+type openaiBatchOutput struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int            `json:"status_code"`
+		Body       jsontext.Value `json:"body"`
+	} `json:"response"`
+	Error *openaiBatchError `json:"error"`
+}
+
+// openaiBatchError is the error of a request of an OpenAI batch.
+type openaiBatchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type openaiBatchClient struct {
+	*openaiClient
+	pollInterval time.Duration
+}
+
+var _ BatchClient = (*openaiBatchClient)(nil)
+
+// NewOpenAIBatchClient creates a new instance of [BatchClient] using the OpenAI Batch API, given the API key, model,
+// maximum content length, batch options and request options.
+func NewOpenAIBatchClient(apiKey, model string, maxContentLength int, batchOpts BatchOptions, opts ...option.RequestOption) *openaiBatchClient {
+	return &openaiBatchClient{
+		openaiClient: NewOpenAIClient(apiKey, model, maxContentLength, opts...),
+		pollInterval: batchOpts.PollInterval,
+	}
+}
+
+// SummarizeBatch uploads reqs as the input file of a batch of chat completions, polls the batch until it is complete,
+// and maps the lines of its output and error files back to reqs by their custom ID.
+//
+// SummarizeBatch implements [BatchClient].
+func (c *openaiBatchClient) SummarizeBatch(ctx context.Context, reqs []BatchRequest) ([]BatchResult, error) {
+	var input bytes.Buffer
+	for i, req := range reqs {
+		line := openaiBatchLine{
+			CustomID: batchCustomID(i),
+			Method:   "POST",
+			URL:      string(openai.BatchNewParamsEndpointV1ChatCompletions),
+			Body:     c.completionParams(req.Prompt, req.Content),
+		}
+		if err := json.MarshalWrite(&input, line); err != nil {
+			return nil, fmt.Errorf("marshal batch request: %w", err)
+		}
+		input.WriteByte('\n')
+	}
+
+	file, err := c.client.Files.New(ctx, openai.FileNewParams{
+		File:    openai.File(&input, "batch.jsonl", "application/jsonl"),
+		Purpose: openai.FilePurposeBatch,
+	})
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Failed to upload batch input file", slog.Any("error", err))
+		return nil, fmt.Errorf("upload batch input file: %w", err)
+	}
+	defer c.deleteFile(ctx, file.ID)
+
+	batch, err := c.client.Batches.New(ctx, openai.BatchNewParams{
+		InputFileID:      file.ID,
+		Endpoint:         openai.BatchNewParamsEndpointV1ChatCompletions,
+		CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+	})
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Failed to create batch", slog.Any("error", err))
+		return nil, fmt.Errorf("create batch: %w", err)
+	}
+	c.logger.InfoContext(ctx, "Created batch", slog.String("id", batch.ID), slog.Int("requests", len(reqs)))
+
+	poll := func(ctx context.Context) (bool, error) {
+		b, err := c.client.Batches.Get(ctx, batch.ID)
+		if err != nil {
+			return false, fmt.Errorf("get batch %s: %w", batch.ID, err)
+		}
+		batch = b
+		c.logger.DebugContext(ctx, "Polled batch",
+			slog.String("id", batch.ID),
+			slog.String("status", string(batch.Status)),
+			slog.Int64("completed", batch.RequestCounts.Completed),
+			slog.Int64("total", batch.RequestCounts.Total),
+		)
+		switch batch.Status {
+		case openai.BatchStatusCompleted, openai.BatchStatusExpired, openai.BatchStatusCancelled:
+			// an expired or cancelled batch still has the results of the completed requests
+			return true, nil
+		case openai.BatchStatusFailed:
+			var msgs []string
+			for _, e := range batch.Errors.Data {
+				msgs = append(msgs, e.Message)
+			}
+			return false, fmt.Errorf("batch %s failed: %s", batch.ID, strings.Join(msgs, "; "))
+		}
+		return false, nil
+	}
+	cancel := func(ctx context.Context) error {
+		_, err := c.client.Batches.Cancel(ctx, batch.ID)
+		return err
+	}
+	if err := pollBatch(ctx, c.pollInterval, c.logger, poll, cancel); err != nil {
+		return nil, err
+	}
+
+	results := newBatchResults(len(reqs))
+	for _, id := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if id == "" {
+			continue
+		}
+		if err := c.readResults(ctx, id, results); err != nil {
+			return nil, err
+		}
+		c.deleteFile(ctx, id)
+	}
+	if batch.Status != openai.BatchStatusCompleted {
+		for i := range results {
+			if errors.Is(results[i].Err, errNoBatchResult) {
+				results[i].Err = fmt.Errorf("batch %s", batch.Status)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// readResults reads the output or error file id of a batch into results.
+func (c *openaiBatchClient) readResults(ctx context.Context, id string, results []BatchResult) error {
+	resp, err := c.client.Files.Content(ctx, id)
+	if err != nil {
+		return fmt.Errorf("download batch file %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	r := bufio.NewReader(resp.Body)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			c.readResult(ctx, line, results)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read batch file %s: %w", id, err)
+		}
+	}
+}
+
+// readResult reads a line of the output or error file of a batch into results.
+func (c *openaiBatchClient) readResult(ctx context.Context, line []byte, results []BatchResult) {
+	var out openaiBatchOutput
+	if err := json.Unmarshal(line, &out); err != nil {
+		c.logger.WarnContext(ctx, "Ignoring malformed batch result", slog.Any("error", err))
+		return
+	}
+	i, ok := parseBatchCustomID(out.CustomID, len(results))
+	if !ok {
+		c.logger.WarnContext(ctx, "Ignoring result of unknown request", slog.String("custom_id", out.CustomID))
+		return
+	}
+
+	result := &results[i]
+	switch {
+	case out.Error != nil:
+		result.Usage = recordBatchUsage(ctx, Usage{})
+		result.Err = fmt.Errorf("request failed: %s: %s", out.Error.Code, out.Error.Message)
+	case out.Response == nil:
+		result.Err = errNoBatchResult
+	case out.Response.StatusCode != 200:
+		var body struct {
+			Error openaiBatchError `json:"error"`
+		}
+		json.Unmarshal(out.Response.Body, &body)
+		result.Usage = recordBatchUsage(ctx, Usage{})
+		result.Err = fmt.Errorf("request failed with status %d: %s", out.Response.StatusCode, body.Error.Message)
+	default:
+		var completion openai.ChatCompletion
+		if err := completion.UnmarshalJSON(out.Response.Body); err != nil {
+			result.Err = fmt.Errorf("parse chat completion: %w", err)
+			return
+		}
+		result.Usage = recordBatchUsage(ctx, Usage{InputTokens: int(completion.Usage.PromptTokens), OutputTokens: int(completion.Usage.CompletionTokens)})
+		result.Title, result.Description, result.Err = c.parseCompletion(ctx, &completion)
+	}
+}
+
+// deleteFile deletes the batch file id, which is no longer needed, ignoring failures.
+func (c *openaiBatchClient) deleteFile(ctx context.Context, id string) {
+	if _, err := c.client.Files.Delete(context.WithoutCancel(ctx), id); err != nil {
+		c.logger.DebugContext(ctx, "Failed to delete batch file", slog.String("id", id), slog.Any("error", err))
+	}
+}
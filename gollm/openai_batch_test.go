@@ -0,0 +1,325 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// openaiBatchServer is a fake of the files and batches endpoints of the OpenAI API.
+type openaiBatchServer struct {
+	*httptest.Server
+
+	// pollsUntilDone is the number of polls answered with the in_progress status before status.
+	pollsUntilDone int
+	// status is the final status of the batch.
+	status string
+	// output and errors are the contents of the output and error files of the batch, if not empty.
+	output, errors string
+	// onPoll is called on each poll, if not nil.
+	onPoll func(polls int)
+
+	mu       sync.Mutex
+	input    []string // the custom IDs of the uploaded input file
+	polls    int
+	canceled bool
+	deleted  []string
+}
+
+func newOpenAIBatchServer(t *testing.T, s *openaiBatchServer) *openaiBatchServer {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/files", func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("read uploaded file: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+
+		var ids []string
+		sc := bufio.NewScanner(f)
+		sc.Buffer(nil, 1<<20)
+		for sc.Scan() {
+			var line openaiBatchLine
+			if err := json.Unmarshal(sc.Bytes(), &line, json.DiscardUnknownMembers(true)); err != nil {
+				t.Errorf("unmarshal batch line %q: %v", sc.Text(), err)
+			}
+			ids = append(ids, line.CustomID)
+		}
+		s.mu.Lock()
+		s.input = ids
+		s.mu.Unlock()
+
+		writeJSON(w, `{"id":"file-input","object":"file","bytes":1,"created_at":1,"filename":"batch.jsonl","purpose":"batch","status":"processed"}`)
+	})
+	mux.HandleFunc("POST /v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, s.batch("validating"))
+	})
+	mux.HandleFunc("GET /v1/batches/batch-1", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.polls++
+		polls, canceled := s.polls, s.canceled
+		s.mu.Unlock()
+		if s.onPoll != nil {
+			s.onPoll(polls)
+		}
+
+		status := "in_progress"
+		switch {
+		case canceled:
+			status = "cancelled"
+		case polls > s.pollsUntilDone:
+			status = s.status
+		}
+		writeJSON(w, s.batch(status))
+	})
+	mux.HandleFunc("POST /v1/batches/batch-1/cancel", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.canceled = true
+		s.mu.Unlock()
+		writeJSON(w, s.batch("cancelling"))
+	})
+	mux.HandleFunc("GET /v1/files/{id}/content", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("id") {
+		case "file-output":
+			io.WriteString(w, s.output)
+		case "file-errors":
+			io.WriteString(w, s.errors)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	mux.HandleFunc("DELETE /v1/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.deleted = append(s.deleted, r.PathValue("id"))
+		s.mu.Unlock()
+		writeJSON(w, fmt.Sprintf(`{"id":%q,"object":"file","deleted":true}`, r.PathValue("id")))
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// batch returns the batch object with status.
+func (s *openaiBatchServer) batch(status string) string {
+	var files string
+	if status == "completed" || status == "expired" || status == "cancelled" {
+		if s.output != "" {
+			files += `,"output_file_id":"file-output"`
+		}
+		if s.errors != "" {
+			files += `,"error_file_id":"file-errors"`
+		}
+	}
+	var errs string
+	if status == "failed" {
+		errs = `,"errors":{"object":"list","data":[{"code":"invalid_request","message":"the input file is invalid"}]}`
+	}
+	return fmt.Sprintf(`{"id":"batch-1","object":"batch","endpoint":"/v1/chat/completions","input_file_id":"file-input","completion_window":"24h","created_at":1,"status":%q,"request_counts":{"completed":0,"failed":0,"total":3}%s%s}`, status, files, errs)
+}
+
+// writeJSON writes body as a JSON response.
+func writeJSON(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, body)
+}
+
+// openaiBatchOutputLine returns a line of the output file of a batch with the completion of content.
+func openaiBatchOutputLine(t *testing.T, id, content string) string {
+	t.Helper()
+	return fmt.Sprintf(`{"id":"batch_req_%s","custom_id":%q,"response":{"status_code":200,"request_id":"req","body":%s},"error":null}`, id, id, chatCompletion(t, content))
+}
+
+func TestOpenAIBatchClientSummarizeBatch(t *testing.T) {
+	reqs := []BatchRequest{
+		{Prompt: Prompt{System: "system", User: "user", Suffix: "URL: https://example.com/0"}, Content: "zero"},
+		{Prompt: Prompt{System: "system", User: "user", Suffix: "URL: https://example.com/1"}, Content: "one"},
+		{Prompt: Prompt{System: "system", User: "user", Suffix: "URL: https://example.com/2"}, Content: "two"},
+	}
+
+	type want struct {
+		title string
+		err   string
+	}
+	tests := map[string]struct {
+		server      *openaiBatchServer
+		want        []want
+		wantErr     string
+		wantDeleted []string
+	}{
+		"out of order": {
+			server: &openaiBatchServer{
+				pollsUntilDone: 2,
+				status:         "completed",
+				output: strings.Join([]string{
+					openaiBatchOutputLine(t, "request-2", `{"title":"Two","description":"The second page."}`),
+					openaiBatchOutputLine(t, "request-0", `{"title":"Zero","description":"The first page."}`),
+					openaiBatchOutputLine(t, "request-1", `{"title":"One","description":"The middle page."}`),
+				}, "\n") + "\n",
+			},
+			want:        []want{{title: "Zero"}, {title: "One"}, {title: "Two"}},
+			wantDeleted: []string{"file-output", "file-input"},
+		},
+		"missing results": {
+			server: &openaiBatchServer{
+				status: "completed",
+				output: strings.Join([]string{
+					openaiBatchOutputLine(t, "request-1", `{"title":"One","description":"The middle page."}`),
+					openaiBatchOutputLine(t, "request-7", `{"title":"Unknown","description":"Not a request of the batch."}`),
+					`{"custom_id":"request-2","response":null,"error":null}`,
+					`not JSON`,
+				}, "\n"),
+			},
+			want: []want{{err: errNoBatchResult.Error()}, {title: "One"}, {err: errNoBatchResult.Error()}},
+		},
+		"request errors": {
+			server: &openaiBatchServer{
+				status: "completed",
+				output: strings.Join([]string{
+					openaiBatchOutputLine(t, "request-0", `{"title":"Zero","description":"The first page."}`),
+					`{"custom_id":"request-1","response":{"status_code":400,"body":{"error":{"message":"invalid model","type":"invalid_request_error"}}},"error":null}`,
+				}, "\n"),
+				errors: `{"custom_id":"request-2","response":null,"error":{"code":"server_error","message":"internal error"}}` + "\n",
+			},
+			want: []want{
+				{title: "Zero"},
+				{err: "request failed with status 400: invalid model"},
+				{err: "request failed: server_error: internal error"},
+			},
+			wantDeleted: []string{"file-output", "file-errors", "file-input"},
+		},
+		"expired": {
+			server: &openaiBatchServer{
+				status: "expired",
+				output: openaiBatchOutputLine(t, "request-0", `{"title":"Zero","description":"The first page."}`),
+				errors: `{"custom_id":"request-1","response":null,"error":{"code":"batch_expired","message":"This request could not be executed before the completion window expired."}}`,
+			},
+			want: []want{
+				{title: "Zero"},
+				{err: "request failed: batch_expired: This request could not be executed before the completion window expired."},
+				{err: "batch expired"},
+			},
+		},
+		"failed": {
+			server:      &openaiBatchServer{status: "failed"},
+			wantErr:     "batch batch-1 failed: the input file is invalid",
+			wantDeleted: []string{"file-input"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newOpenAIBatchServer(t, tt.server)
+			client := NewOpenAIBatchClient("test-key", "test-model", 0, BatchOptions{PollInterval: time.Millisecond},
+				option.WithBaseURL(srv.URL+"/v1"), option.WithMaxRetries(0))
+
+			stats := NewStats(nil)
+			results, err := client.SummarizeBatch(WithStats(context.Background(), stats), reqs)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SummarizeBatch() error = %v, want containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("SummarizeBatch() error = %v", err)
+			}
+
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if got, want := strings.Join(srv.input, ","), "request-0,request-1,request-2"; got != want {
+				t.Errorf("uploaded custom IDs = %s, want %s", got, want)
+			}
+			if tt.wantDeleted != nil {
+				if got, want := strings.Join(srv.deleted, ","), strings.Join(tt.wantDeleted, ","); got != want {
+					t.Errorf("deleted files = %s, want %s", got, want)
+				}
+			}
+			if len(tt.want) == 0 {
+				return
+			}
+
+			if len(results) != len(reqs) {
+				t.Fatalf("got %d results, want %d", len(results), len(reqs))
+			}
+			var requests int
+			for i, w := range tt.want {
+				r := results[i]
+				if w.err != "" {
+					if r.Err == nil || r.Err.Error() != w.err {
+						t.Errorf("result %d error = %v, want %s", i, r.Err, w.err)
+					}
+					if w.err == errNoBatchResult.Error() && !errors.Is(r.Err, errNoBatchResult) {
+						t.Errorf("result %d error = %v, want %v", i, r.Err, errNoBatchResult)
+					}
+				} else if r.Err != nil || r.Title != w.title {
+					t.Errorf("result %d = (%q, %v), want %q", i, r.Title, r.Err, w.title)
+				}
+				requests += r.Usage.Requests
+			}
+			if got := stats.Usage().Requests; got != requests {
+				t.Errorf("recorded %d requests, want the %d requests of the results", got, requests)
+			}
+		})
+	}
+}
+
+func TestOpenAIBatchClientCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := newOpenAIBatchServer(t, &openaiBatchServer{
+		pollsUntilDone: 1000,
+		status:         "completed",
+		onPoll: func(polls int) {
+			if polls == 2 {
+				cancel()
+			}
+		},
+	})
+	client := NewOpenAIBatchClient("test-key", "test-model", 0, BatchOptions{PollInterval: time.Millisecond},
+		option.WithBaseURL(srv.URL+"/v1"), option.WithMaxRetries(0))
+
+	_, err := client.SummarizeBatch(ctx, []BatchRequest{{Prompt: Prompt{System: "system", User: "user"}, Content: "content"}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("SummarizeBatch() error = %v, want %v", err, context.Canceled)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if !srv.canceled {
+		t.Error("the batch was not canceled")
+	}
+	if got := strings.Join(srv.deleted, ","); got != "file-input" {
+		t.Errorf("deleted files = %s, want file-input", got)
+	}
+}
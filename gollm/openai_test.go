@@ -0,0 +1,323 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// chatServer is a fake chat API answering the requests to a single path with a fixed response.
+type chatServer struct {
+	*httptest.Server
+
+	// requests are the decoded bodies of the requests received by the server.
+	requests []map[string]any
+}
+
+// newChatServer starts a [chatServer] responding to POST requests to path with status and body.
+func newChatServer(t *testing.T, path string, status int, body string) *chatServer {
+	t.Helper()
+
+	srv := &chatServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read request: %v", err)
+		}
+		var req map[string]any
+		if err := json.Unmarshal(b, &req); err != nil {
+			t.Errorf("unmarshal request %q: %v", b, err)
+		}
+		srv.requests = append(srv.requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// chatCompletion returns the body of an OpenAI chat completion whose single choice has content.
+func chatCompletion(t *testing.T, content string) string {
+	t.Helper()
+
+	b, err := json.Marshal(map[string]any{
+		"id":      "chatcmpl-1",
+		"object":  "chat.completion",
+		"created": 1,
+		"model":   "test-model",
+		"choices": []any{
+			map[string]any{
+				"index":         0,
+				"finish_reason": "stop",
+				"message": map[string]any{
+					"role":    "assistant",
+					"content": content,
+				},
+			},
+		},
+		"usage": map[string]any{
+			"prompt_tokens":     12,
+			"completion_tokens": 3,
+			"total_tokens":      15,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestOpenAIClientSummarizeContent(t *testing.T) {
+	tests := map[string]struct {
+		status          int
+		body            string
+		wantTitle       string
+		wantDescription string
+		wantErr         string
+		wantRetryable   bool
+	}{
+		"success": {
+			status:          http.StatusOK,
+			body:            chatCompletion(t, `{"title":"Getting Started","description":"How to install and configure the tool."}`),
+			wantTitle:       "Getting Started",
+			wantDescription: "How to install and configure the tool.",
+		},
+		"repaired JSON": {
+			status:          http.StatusOK,
+			body:            chatCompletion(t, `{"title":"Guide","description":"How to start.",}`),
+			wantTitle:       "Guide",
+			wantDescription: "How to start.",
+		},
+		"missing fields": {
+			status:          http.StatusOK,
+			body:            chatCompletion(t, `{}`),
+			wantTitle:       DefaultTitle,
+			wantDescription: DefaultDescription,
+		},
+		"empty content": {
+			status:  http.StatusOK,
+			body:    chatCompletion(t, ""),
+			wantErr: "empty content returned for test-model",
+		},
+		"no choices": {
+			status:  http.StatusOK,
+			body:    `{"id":"chatcmpl-1","object":"chat.completion","model":"test-model","choices":[]}`,
+			wantErr: "no choices returned",
+		},
+		"refusal": {
+			status:  http.StatusOK,
+			body:    `{"id":"chatcmpl-1","object":"chat.completion","model":"test-model","choices":[{"index":0,"message":{"role":"assistant","content":"","refusal":"I can't help with that."}}]}`,
+			wantErr: "request refused: I can't help with that.",
+		},
+		"bad request": {
+			status:  http.StatusBadRequest,
+			body:    `{"error":{"message":"invalid model","type":"invalid_request_error"}}`,
+			wantErr: "400 Bad Request",
+		},
+		"unauthorized": {
+			status:  http.StatusUnauthorized,
+			body:    `{"error":{"message":"invalid api key","type":"invalid_request_error"}}`,
+			wantErr: "401 Unauthorized",
+		},
+		"rate limited": {
+			status:        http.StatusTooManyRequests,
+			body:          `{"error":{"message":"slow down","type":"rate_limit_error"}}`,
+			wantErr:       "429 Too Many Requests",
+			wantRetryable: true,
+		},
+		"server error": {
+			status:        http.StatusInternalServerError,
+			body:          `{"error":{"message":"boom","type":"server_error"}}`,
+			wantErr:       "500 Internal Server Error",
+			wantRetryable: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newChatServer(t, "/v1/chat/completions", tt.status, tt.body)
+			client := NewOpenAIClient("test-key", "test-model", 0, option.WithBaseURL(srv.URL+"/v1"), option.WithMaxRetries(0))
+
+			stats := NewStats(nil)
+			ctx := WithStats(context.Background(), stats)
+			prompt := Prompt{System: "system prompt", User: "user prompt", Suffix: "URL: https://example.com/"}
+			title, description, err := client.SummarizeContent(ctx, prompt, "# Getting Started")
+
+			if len(srv.requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(srv.requests))
+			}
+			if got := srv.requests[0]["model"]; got != "test-model" {
+				t.Errorf("request model = %v, want test-model", got)
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("SummarizeContent() error = %v, want containing %q", err, tt.wantErr)
+				}
+				if got := IsRetryable(err); got != tt.wantRetryable {
+					t.Errorf("IsRetryable(%v) = %t, want %t", err, got, tt.wantRetryable)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SummarizeContent() error = %v", err)
+			}
+			if title != tt.wantTitle || description != tt.wantDescription {
+				t.Errorf("SummarizeContent() = (%q, %q), want (%q, %q)", title, description, tt.wantTitle, tt.wantDescription)
+			}
+			if got := stats.Usage(); got.InputTokens != 12 || got.OutputTokens != 3 {
+				t.Errorf("usage = %+v, want 12 input and 3 output tokens", got)
+			}
+		})
+	}
+}
+
+func TestOpenAIClientCanceled(t *testing.T) {
+	srv := newChatServer(t, "/v1/chat/completions", http.StatusOK, chatCompletion(t, `{"title":"Guide","description":"How to start."}`))
+	client := NewOpenAIClient("test-key", "test-model", 0, option.WithBaseURL(srv.URL+"/v1"), option.WithMaxRetries(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := client.SummarizeContent(ctx, Prompt{System: "system", User: "user"}, "content")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("SummarizeContent() error = %v, want %v", err, context.Canceled)
+	}
+	if IsRetryable(err) {
+		t.Errorf("IsRetryable(%v) = true, want false", err)
+	}
+}
+
+func TestOpenAIClientMaxContentLength(t *testing.T) {
+	srv := newChatServer(t, "/v1/chat/completions", http.StatusOK, chatCompletion(t, `{"title":"Guide","description":"How to start."}`))
+	client := NewOpenAIClient("test-key", "test-model", 5, option.WithBaseURL(srv.URL+"/v1"), option.WithMaxRetries(0))
+
+	if _, _, err := client.SummarizeContent(context.Background(), Prompt{System: "system", User: "user"}, "0123456789"); err != nil {
+		t.Fatalf("SummarizeContent() error = %v", err)
+	}
+
+	messages, _ := srv.requests[0]["messages"].([]any)
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+	user, _ := messages[1].(map[string]any)
+	if got, want := user["content"], "user\n\nPage content:\n01234"; got != want {
+		t.Errorf("user message = %q, want %q", got, want)
+	}
+}
+
+func TestOpenAIClientCompletionParameters(t *testing.T) {
+	body := chatCompletion(t, `{"title":"Guide","description":"How to start."}`)
+
+	tests := map[string]struct {
+		model string
+		// custom is the OpenAI configuration of a custom endpoint, or nil for the OpenAI API.
+		custom              *OpenAIConfig
+		wantMaxTokens       any
+		wantVerbosity       any
+		wantReasoningEffort any
+	}{
+		"gpt-5": {
+			model:               "gpt-5-mini",
+			wantMaxTokens:       float64(openAIMaxCompletionTokens),
+			wantVerbosity:       "high",
+			wantReasoningEffort: "low",
+		},
+		"o-series": {
+			model:               "o4-mini",
+			wantMaxTokens:       float64(openAIMaxCompletionTokens),
+			wantReasoningEffort: "high",
+		},
+		"non-reasoning": {
+			model: "gpt-4o-mini",
+		},
+		"gpt-5 chat": {
+			model:               "gpt-5-chat-latest",
+			wantReasoningEffort: "low",
+		},
+		"custom endpoint": {
+			model:  "meta-llama/llama-3.1-70b-instruct",
+			custom: &OpenAIConfig{},
+		},
+		"custom endpoint with a gpt-5 model": {
+			model:               "gpt-5",
+			custom:              &OpenAIConfig{},
+			wantReasoningEffort: "low",
+		},
+		"custom endpoint with configured parameters": {
+			model:         "meta-llama/llama-3.1-70b-instruct",
+			custom:        &OpenAIConfig{MaxCompletionTokens: 2048, Verbosity: "low"},
+			wantMaxTokens: float64(2048),
+			wantVerbosity: "low",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newChatServer(t, "/v1/chat/completions", http.StatusOK, body)
+
+			var client SummarizerClient
+			if tt.custom == nil {
+				client = NewOpenAIClient("test-key", tt.model, 0, option.WithBaseURL(srv.URL+"/v1"), option.WithMaxRetries(0))
+			} else {
+				cfg := *tt.custom
+				cfg.BaseURL = srv.URL + "/v1"
+				var err error
+				client, err = New(tt.model, &Options{APIKey: "test-key", OpenAI: cfg})
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+			}
+
+			if _, _, err := client.SummarizeContent(context.Background(), Prompt{System: "system", User: "user"}, "content"); err != nil {
+				t.Fatalf("SummarizeContent() error = %v", err)
+			}
+			if len(srv.requests) != 1 {
+				t.Fatalf("got %d requests, want 1", len(srv.requests))
+			}
+			req := srv.requests[0]
+			if got := req["model"]; got != tt.model {
+				t.Errorf("request model = %v, want %s", got, tt.model)
+			}
+			for key, want := range map[string]any{
+				"max_completion_tokens": tt.wantMaxTokens,
+				"verbosity":             tt.wantVerbosity,
+				"reasoning_effort":      tt.wantReasoningEffort,
+			} {
+				if got := req[key]; got != want {
+					t.Errorf("request %s = %v, want %v", key, got, want)
+				}
+			}
+			if _, ok := req["max_tokens"]; ok {
+				t.Errorf("request max_tokens = %v, want none", req["max_tokens"])
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"strings"
+)
+
+// Price is the price of a model in US dollars per million tokens.
+type Price struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// Prices of the prompt cache of Anthropic relative to the input price, see
+// https://docs.anthropic.com/en/docs/build-with-claude/prompt-caching#pricing.
+const (
+	cacheReadRate    = 0.1
+	cacheWriteRate   = 1.25
+	cacheWrite1hRate = 2
+)
+
+// Cost returns the cost of the tokens of u in US dollars.
+func (p Price) Cost(u Usage) float64 {
+	input := float64(u.InputTokens) +
+		float64(u.CacheReadTokens)*cacheReadRate +
+		float64(u.CacheWriteTokens-u.CacheWrite1hTokens)*cacheWriteRate +
+		float64(u.CacheWrite1hTokens)*cacheWrite1hRate
+	return (input*p.Input + float64(u.OutputTokens)*p.Output) / 1e6
+}
+
+// Batch returns the price of the requests sent through a [BatchClient], which the OpenAI and Anthropic batch APIs
+// bill at half of the standard price.
+func (p Price) Batch() Price {
+	return Price{Input: p.Input / 2, Output: p.Output / 2}
+}
+
+// openAIPrices are the standard prices of the OpenAI models by model name prefix, as of August 2025.
+// See https://openai.com/api/pricing/.
+var openAIPrices = map[string]Price{
+	"gpt-5":                {Input: 1.25, Output: 10},
+	"gpt-5-mini":           {Input: 0.25, Output: 2},
+	"gpt-5-nano":           {Input: 0.05, Output: 0.40},
+	"gpt-4.1":              {Input: 2, Output: 8},
+	"gpt-4.1-mini":         {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":         {Input: 0.10, Output: 0.40},
+	"o4-mini":              {Input: 1.10, Output: 4.40},
+	"o3":                   {Input: 2, Output: 8},
+	"o3-mini":              {Input: 1.10, Output: 4.40},
+	"o3-pro":               {Input: 20, Output: 80},
+	"o1":                   {Input: 15, Output: 60},
+	"o1-mini":              {Input: 1.10, Output: 4.40},
+	"o1-pro":               {Input: 150, Output: 600},
+	"gpt-4o":               {Input: 2.50, Output: 10},
+	"gpt-4o-2024-05-13":    {Input: 5, Output: 15},
+	"gpt-4o-mini":          {Input: 0.15, Output: 0.60},
+	"chatgpt-4o-latest":    {Input: 5, Output: 15},
+	"codex-mini-latest":    {Input: 1.50, Output: 6},
+	"gpt-4-turbo":          {Input: 10, Output: 30},
+	"gpt-4-0125-preview":   {Input: 10, Output: 30},
+	"gpt-4-1106-preview":   {Input: 10, Output: 30},
+	"gpt-4-vision-preview": {Input: 10, Output: 30},
+	"gpt-4":                {Input: 30, Output: 60},
+	"gpt-4-32k":            {Input: 60, Output: 120},
+	"gpt-3.5-turbo":        {Input: 0.50, Output: 1.50},
+	"gpt-3.5-turbo-16k":    {Input: 3, Output: 4},
+}
+
+// anthropicPrices are the standard prices of the Anthropic models by model name prefix, as of August 2025.
+// See https://www.anthropic.com/pricing#api.
+var anthropicPrices = map[string]Price{
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-4-opus":     {Input: 15, Output: 75},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-4-sonnet":   {Input: 3, Output: 15},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4},
+	"claude-3-opus":     {Input: 15, Output: 75},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+}
+
+// freePrices are the prices of the providers running the models locally.
+var freePrices = map[string]Price{"": {}}
+
+// LookupPrice returns the price of model resolved by [Resolve] from the [Provider.Prices] of its provider.
+// The price of the longest model name prefix is used, so that dated snapshots share the price of their model.
+func LookupPrice(model string, opts *Options) (Price, bool) {
+	p, name, err := Resolve(model, opts)
+	if err != nil {
+		return Price{}, false
+	}
+
+	var (
+		price  Price
+		prefix = -1
+	)
+	for pre, pr := range p.Prices {
+		if len(pre) > prefix && strings.HasPrefix(name, pre) {
+			price, prefix = pr, len(pre)
+		}
+	}
+
+	return price, prefix >= 0
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPriceCost(t *testing.T) {
+	price := Price{Input: 3, Output: 15}
+	tests := map[string]struct {
+		usage Usage
+		want  float64
+	}{
+		"uncached input and output": {
+			usage: Usage{InputTokens: 1_000_000, OutputTokens: 100_000},
+			want:  3 + 1.5,
+		},
+		"cache read": {
+			usage: Usage{CacheReadTokens: 1_000_000},
+			want:  0.3,
+		},
+		"cache write": {
+			usage: Usage{CacheWriteTokens: 1_000_000},
+			want:  3.75,
+		},
+		"1h cache write": {
+			usage: Usage{CacheWriteTokens: 1_000_000, CacheWrite1hTokens: 1_000_000},
+			want:  6,
+		},
+		"mixed": {
+			usage: Usage{
+				Requests:           10,
+				InputTokens:        200_000,
+				CacheReadTokens:    800_000,
+				CacheWriteTokens:   300_000,
+				CacheWrite1hTokens: 100_000,
+				OutputTokens:       10_000,
+			},
+			want: 0.6 + 0.24 + 0.75 + 0.6 + 0.15,
+		},
+		"no tokens": {
+			usage: Usage{Requests: 1, CacheHits: 1},
+			want:  0,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := price.Cost(tt.usage); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost(%+v) = %v, want %v", tt.usage, got, tt.want)
+			}
+			if got := price.Batch().Cost(tt.usage); math.Abs(got-tt.want/2) > 1e-9 {
+				t.Errorf("Batch().Cost(%+v) = %v, want %v", tt.usage, got, tt.want/2)
+			}
+		})
+	}
+}
+
+func TestLookupPrice(t *testing.T) {
+	tests := map[string]struct {
+		model  string
+		opts   *Options
+		want   Price
+		wantOK bool
+	}{
+		"openai": {
+			model:  "gpt-4.1",
+			want:   Price{Input: 2, Output: 8},
+			wantOK: true,
+		},
+		"longest prefix": {
+			model:  "gpt-4o-mini-2024-07-18",
+			want:   Price{Input: 0.15, Output: 0.60},
+			wantOK: true,
+		},
+		"dated snapshot": {
+			model:  "gpt-4o-2024-05-13",
+			want:   Price{Input: 5, Output: 15},
+			wantOK: true,
+		},
+		"anthropic": {
+			model:  "claude-sonnet-4-20250514",
+			want:   Price{Input: 3, Output: 15},
+			wantOK: true,
+		},
+		"provider name": {
+			model:  "anthropic:claude-3-5-haiku-latest",
+			want:   Price{Input: 0.80, Output: 4},
+			wantOK: true,
+		},
+		"local model": {
+			model:  "ollama:llama3.2",
+			want:   Price{},
+			wantOK: true,
+		},
+		"unknown openai model": {
+			model: "gpt-99",
+		},
+		"unknown model": {
+			model: "mistral-large",
+		},
+		"custom endpoint model": {
+			model: "meta-llama/llama-3-70b",
+			opts:  &Options{OpenAI: OpenAIConfig{BaseURL: "http://localhost:8000/v1"}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := LookupPrice(tt.model, tt.opts)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("LookupPrice(%q) = %+v, %t, want %+v, %t", tt.model, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
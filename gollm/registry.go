@@ -0,0 +1,239 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Names of the built-in providers.
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+	ProviderLlamaCpp  = "llamacpp"
+)
+
+// DefaultModel is the model used when none is configured.
+const DefaultModel = "gpt-4.1-mini"
+
+// Options contains the configuration passed to a [Factory].
+type Options struct {
+	// APIKey overrides the API key of the provider configuration if not empty.
+	APIKey string
+
+	// MaxContentLength is the maximum length of the content sent to the model. 0 means unlimited.
+	MaxContentLength int
+
+	// Retry configures the [NewRetryClient] wrapping the client created by [New].
+	Retry RetryOptions
+
+	// MaxReasks is the maximum number of times a response violating its [Prompt] is re-asked by the
+	// [NewValidateClient] wrapping the client created by [New]. Zero disables re-asking.
+	MaxReasks int
+
+	// Cache stores the responses of the client created by [New], see [NewCacheClient]. It may be nil.
+	Cache *Cache
+
+	// Batch configures the [BatchClient] created by [NewBatch].
+	Batch BatchOptions
+
+	OpenAI    OpenAIConfig
+	Anthropic AnthropicConfig
+	Ollama    OllamaConfig
+	LlamaCpp  LlamaCppConfig
+}
+
+// Factory creates a [SummarizerClient] for the model name without the provider name.
+type Factory func(model string, opts *Options) (SummarizerClient, error)
+
+// Provider is an LLM provider registered by [Register].
+type Provider struct {
+	// Name is the unique name of the provider, used as "<name>/<model>" to address its models.
+	Name string
+
+	// Description is a short human readable description of the provider.
+	Description string
+
+	// Prefixes are the model name prefixes resolved to the provider without an explicit provider name.
+	Prefixes []string
+
+	// Models are the well-known models of the provider. The provider may accept other models.
+	Models []string
+
+	// Prices are the prices of the models of the provider by model name prefix, used by [LookupPrice].
+	// The empty prefix applies to all models. It may be nil if the prices are unknown.
+	Prices map[string]Price
+
+	// Fallback reports whether the provider accepts model names not resolved by any provider name or prefix.
+	// It may be nil.
+	Fallback func(opts *Options) bool
+
+	// Endpoint returns the base URL the clients of the provider send their requests to, which is part of the
+	// [CacheKey] of their responses. It may be nil if the provider has a single endpoint.
+	Endpoint func(opts *Options) string
+
+	// New creates the client for a model of the provider.
+	New Factory
+
+	// NewBatch creates the [BatchClient] for a model of the provider. It may be nil if the provider has no batch API.
+	NewBatch BatchFactory
+}
+
+var registry = struct {
+	mu        sync.RWMutex
+	providers map[string]*Provider
+}{
+	providers: make(map[string]*Provider),
+}
+
+// Register makes p available by its name. It panics if p has no name or factory, or a provider with the same name is
+// already registered.
+func Register(p Provider) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if p.Name == "" || p.New == nil {
+		panic("gollm: Register provider without name or factory")
+	}
+	if _, ok := registry.providers[p.Name]; ok {
+		panic("gollm: Register called twice for provider " + p.Name)
+	}
+	registry.providers[p.Name] = &p
+}
+
+// Providers returns the registered providers sorted by name.
+func Providers() []Provider {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	providers := make([]Provider, 0, len(registry.providers))
+	for _, p := range registry.providers {
+		providers = append(providers, *p)
+	}
+	slices.SortFunc(providers, func(a, b Provider) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return providers
+}
+
+// UnknownModelError is returned by [Resolve] when a model can't be resolved to a provider.
+type UnknownModelError struct {
+	Model     string
+	Providers []Provider
+}
+
+// Error implements [error].
+func (e *UnknownModelError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "unknown model %q: use \"<provider>:<model>\" or a known model prefix; available providers:", e.Model)
+	for _, p := range e.Providers {
+		fmt.Fprintf(&sb, "\n  %s", p.Name)
+		if len(p.Prefixes) > 0 {
+			fmt.Fprintf(&sb, " (%s)", strings.Join(p.Prefixes, ", "))
+		}
+	}
+	return sb.String()
+}
+
+// Resolve returns the provider of model and the model name without the provider name.
+//
+// The model is resolved in order:
+//  1. "<provider>:<model>" or "<provider>/<model>" addresses a registered provider by name
+//  2. the provider with the longest [Provider.Prefixes] matching the model
+//  3. the first provider, by name, whose [Provider.Fallback] accepts the model
+//
+// The "<provider>/<model>" form is not recognized when [OpenAIConfig.IsCustomEndpoint] reports true, since the
+// OpenAI-compatible endpoints use it for their own model names, e.g. "anthropic/claude-3.5-sonnet" on OpenRouter.
+// Such names are passed through unchanged.
+//
+// If none matches, Resolve returns an [*UnknownModelError].
+func Resolve(model string, opts *Options) (Provider, string, error) {
+	providers := Providers()
+
+	separators := "/:"
+	if opts != nil && opts.OpenAI.IsCustomEndpoint() {
+		separators = ":"
+	}
+	if i := strings.IndexAny(model, separators); i >= 0 {
+		for _, p := range providers {
+			if p.Name == model[:i] {
+				return p, model[i+1:], nil
+			}
+		}
+	}
+
+	best, matched := -1, 0
+	for i, p := range providers {
+		for _, prefix := range p.Prefixes {
+			if strings.HasPrefix(model, prefix) && len(prefix) > matched {
+				best, matched = i, len(prefix)
+			}
+		}
+	}
+	if best >= 0 {
+		return providers[best], model, nil
+	}
+
+	for _, p := range providers {
+		if p.Fallback != nil && p.Fallback(opts) {
+			return p, model, nil
+		}
+	}
+
+	return Provider{}, "", &UnknownModelError{Model: model, Providers: providers}
+}
+
+// endpoint returns the [Provider.Endpoint] of p for opts, or "".
+func (p *Provider) endpoint(opts *Options) string {
+	if p.Endpoint == nil {
+		return ""
+	}
+	return p.Endpoint(opts)
+}
+
+// New creates the [SummarizerClient] for model resolved by [Resolve], wrapped by [NewRetryClient],
+// [NewValidateClient] and, if [Options.Cache] is set, [NewCacheClient].
+//
+// The built-in providers disable the retries of their SDKs in favor of [NewRetryClient].
+func New(model string, opts *Options) (SummarizerClient, error) {
+	p, name, err := Resolve(model, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := p.New(name, opts)
+	if err != nil {
+		return nil, fmt.Errorf("create %s client: %w", p.Name, err)
+	}
+
+	client = NewRetryClient(client, opts.Retry)
+	if opts.MaxReasks > 0 {
+		client = NewValidateClient(client, opts.MaxReasks)
+	}
+	if opts.Cache != nil {
+		// the cache wraps the retries and re-asks, so that cache hits never wait for them
+		client = NewCacheClient(client, opts.Cache, p.Name+"/"+name, p.endpoint(opts), opts.MaxContentLength)
+	}
+
+	return client, nil
+}
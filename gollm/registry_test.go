@@ -0,0 +1,106 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	custom := &Options{OpenAI: OpenAIConfig{BaseURL: "https://openrouter.ai/api/v1"}}
+
+	tests := map[string]struct {
+		model        string
+		opts         *Options
+		wantProvider string
+		wantModel    string
+		wantErr      bool
+	}{
+		"prefix": {
+			model:        "gpt-4.1-mini",
+			wantProvider: ProviderOpenAI,
+			wantModel:    "gpt-4.1-mini",
+		},
+		"provider slash": {
+			model:        "ollama/llama3.1",
+			wantProvider: ProviderOllama,
+			wantModel:    "llama3.1",
+		},
+		"provider colon": {
+			model:        "ollama:llama3.1:8b",
+			wantProvider: ProviderOllama,
+			wantModel:    "llama3.1:8b",
+		},
+		"model tag is not a provider": {
+			model:   "llama3.1:8b",
+			wantErr: true,
+		},
+		"unknown": {
+			model:   "unknown-model",
+			wantErr: true,
+		},
+		"custom endpoint passes namespaced models through": {
+			model:        "openai/gpt-4o",
+			opts:         custom,
+			wantProvider: ProviderOpenAI,
+			wantModel:    "openai/gpt-4o",
+		},
+		"custom endpoint does not route namespaced models to other providers": {
+			model:        "anthropic/claude-3.5-sonnet",
+			opts:         custom,
+			wantProvider: ProviderOpenAI,
+			wantModel:    "anthropic/claude-3.5-sonnet",
+		},
+		"custom endpoint keeps the colon selector": {
+			model:        "anthropic:claude-sonnet-4-0",
+			opts:         custom,
+			wantProvider: ProviderAnthropic,
+			wantModel:    "claude-sonnet-4-0",
+		},
+		"custom endpoint accepts arbitrary names": {
+			model:        "meta-llama/llama-3.1-70b-instruct:free",
+			opts:         custom,
+			wantProvider: ProviderOpenAI,
+			wantModel:    "meta-llama/llama-3.1-70b-instruct:free",
+		},
+		"azure endpoint passes namespaced models through": {
+			model:        "openai/gpt-4o",
+			opts:         &Options{OpenAI: OpenAIConfig{AzureEndpoint: "https://example.openai.azure.com"}},
+			wantProvider: ProviderOpenAI,
+			wantModel:    "openai/gpt-4o",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, model, err := Resolve(tt.model, tt.opts)
+			if tt.wantErr {
+				var unknown *UnknownModelError
+				if !errors.As(err, &unknown) {
+					t.Fatalf("Resolve(%q) error = %v, want %T", tt.model, err, unknown)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tt.model, err)
+			}
+			if p.Name != tt.wantProvider || model != tt.wantModel {
+				t.Errorf("Resolve(%q) = (%q, %q), want (%q, %q)", tt.model, p.Name, model, tt.wantProvider, tt.wantModel)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	anthropic "github.com/anthropics/anthropic-sdk-go"
+	openai "github.com/openai/openai-go/v2"
+)
+
+// RetryOptions configures the retries of [NewRetryClient].
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of attempts, including the first one. Values less than 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry, doubled for each following retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff and the Retry-After delay requested by the provider. Zero means no cap.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryOptions are the [RetryOptions] used by [New].
+var DefaultRetryOptions = RetryOptions{
+	MaxAttempts: 4,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// statusOverloaded is the non-standard status code used by Anthropic when the API is temporarily overloaded.
+const statusOverloaded = 529
+
+type retryClient struct {
+	client  SummarizerClient
+	options RetryOptions
+	logger  *slog.Logger
+}
+
+var _ SummarizerClient = (*retryClient)(nil)
+
+// NewRetryClient wraps client so that requests failing with a retryable error are retried with jittered exponential
+// backoff, honoring the Retry-After header of the provider.
+//
+// Retries stop when the attempts are exhausted, the error is not retryable (see [IsRetryable]) or the next attempt
+// would start after the deadline of the context. Retries are recorded in the [Stats] of the context.
+func NewRetryClient(client SummarizerClient, options RetryOptions) SummarizerClient {
+	return &retryClient{
+		client:  client,
+		options: options,
+		logger:  slog.Default().WithGroup("retry"),
+	}
+}
+
+// SummarizeContent implements [SummarizerClient].
+func (c *retryClient) SummarizeContent(ctx context.Context, prompt Prompt, content string) (title, description string, err error) {
+	stats := StatsFromContext(ctx)
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			stats.add(Usage{Retries: 1})
+		}
+		stats.add(Usage{Requests: 1})
+
+		title, description, err = c.client.SummarizeContent(ctx, prompt, content)
+		if err == nil {
+			return title, description, nil
+		}
+
+		retryable, retryAfter := classifyError(err)
+		if !retryable || ctx.Err() != nil {
+			return "", "", err
+		}
+		if attempt >= c.options.MaxAttempts {
+			return "", "", fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > 0 {
+			delay = retryAfter
+			if c.options.MaxDelay > 0 {
+				delay = min(delay, c.options.MaxDelay)
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return "", "", fmt.Errorf("giving up after %d attempts, retry delay %s exceeds the deadline: %w", attempt, delay, err)
+		}
+
+		c.logger.WarnContext(ctx, "Retrying LLM request",
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.Any("error", err),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", "", ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the full jitter exponential backoff before the retry following attempt.
+func (c *retryClient) backoff(attempt int) time.Duration {
+	if c.options.BaseDelay <= 0 {
+		return 0
+	}
+
+	ceiling := c.options.BaseDelay << min(attempt-1, 30)
+	if ceiling <= 0 {
+		// overflow
+		ceiling = math.MaxInt64
+	}
+	if c.options.MaxDelay > 0 {
+		ceiling = min(ceiling, c.options.MaxDelay)
+	}
+
+	return rand.N(ceiling) + 1
+}
+
+// IsRetryable reports whether err is a transient provider error worth retrying, such as a rate limit,
+// an overloaded or unavailable server, a timeout, or a connection reset, refused or closed unexpectedly.
+func IsRetryable(err error) bool {
+	retryable, _ := classifyError(err)
+	return retryable
+}
+
+// classifyError reports whether err is retryable along with the delay requested by the provider, if any.
+func classifyError(err error) (retryable bool, retryAfter time.Duration) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false, 0
+	}
+
+	var openaiErr *openai.Error
+	if errors.As(err, &openaiErr) {
+		return isRetryableStatus(openaiErr.StatusCode), retryAfterFromResponse(openaiErr.Response)
+	}
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		return isRetryableStatus(anthropicErr.StatusCode), retryAfterFromResponse(anthropicErr.Response)
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return isRetryableStatus(statusErr.StatusCode), parseRetryAfter(statusErr.Header)
+	}
+
+	var streamErr *streamError
+	if errors.As(err, &streamErr) {
+		return isRetryableErrorType(streamErr.Type), 0
+	}
+
+	// permanent network errors, such as an unknown host, are not retried
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true, 0
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true, 0
+	}
+
+	return false, 0
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusConflict,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+		statusOverloaded:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableErrorType reports whether typ is the type of a transient Anthropic error, see
+// https://docs.anthropic.com/en/api/errors.
+func isRetryableErrorType(typ string) bool {
+	switch typ {
+	case "api_error", "overloaded_error", "rate_limit_error":
+		return true
+	default:
+		return false
+	}
+}
+
+func retryAfterFromResponse(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	return parseRetryAfter(resp.Header)
+}
+
+// parseRetryAfter returns the delay of the Retry-After-Ms or Retry-After header, in seconds or as an HTTP date.
+func parseRetryAfter(header http.Header) time.Duration {
+	if ms, err := strconv.ParseFloat(header.Get("Retry-After-Ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+
+	val := header.Get("Retry-After")
+	if val == "" {
+		return 0
+	}
+	if secs, err := strconv.ParseFloat(val, 64); err == nil && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	if t, err := http.ParseTime(val); err == nil {
+		return max(time.Until(t), 0)
+	}
+
+	return 0
+}
@@ -0,0 +1,264 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v2/option"
+)
+
+// scriptedResponse is a response of a [scriptedServer].
+type scriptedResponse struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+// scriptedServer is a fake chat API answering its requests with the scripted responses in order, and with the last
+// one once they are exhausted.
+type scriptedServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests int
+}
+
+func newScriptedServer(t *testing.T, responses []scriptedResponse) *scriptedServer {
+	t.Helper()
+
+	srv := &scriptedServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		resp := responses[min(srv.requests, len(responses)-1)]
+		srv.requests++
+		srv.mu.Unlock()
+
+		if resp.retryAfter != "" {
+			w.Header().Set("Retry-After", resp.retryAfter)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.status)
+		io.WriteString(w, resp.body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestRetryClient(t *testing.T) {
+	ok := scriptedResponse{status: http.StatusOK, body: chatCompletion(t, `{"title":"Guide","description":"How to start."}`)}
+	rateLimited := scriptedResponse{status: http.StatusTooManyRequests, retryAfter: "1", body: `{"error":{"message":"rate limited","type":"rate_limit_error"}}`}
+	internal := scriptedResponse{status: http.StatusInternalServerError, body: `{"error":{"message":"internal","type":"server_error"}}`}
+	unavailable := scriptedResponse{status: http.StatusServiceUnavailable, body: `{"error":{"message":"unavailable","type":"server_error"}}`}
+	badRequest := scriptedResponse{status: http.StatusBadRequest, body: `{"error":{"message":"bad request","type":"invalid_request_error"}}`}
+
+	tests := map[string]struct {
+		responses []scriptedResponse
+		// timeout is the timeout of the context, if not zero.
+		timeout      time.Duration
+		maxDelay     time.Duration
+		wantRequests int
+		wantErr      string
+	}{
+		"rate limit then success": {
+			responses:    []scriptedResponse{rateLimited, ok},
+			wantRequests: 2,
+		},
+		"server errors then success": {
+			responses:    []scriptedResponse{internal, unavailable, ok},
+			wantRequests: 3,
+		},
+		"attempts exhausted": {
+			responses:    []scriptedResponse{unavailable},
+			wantRequests: 3,
+			wantErr:      "giving up after 3 attempts",
+		},
+		"bad request is not retried": {
+			responses:    []scriptedResponse{badRequest, ok},
+			wantRequests: 1,
+			wantErr:      "400 Bad Request",
+		},
+		"Retry-After in seconds past the deadline": {
+			responses:    []scriptedResponse{{status: http.StatusTooManyRequests, retryAfter: "120"}, ok},
+			timeout:      time.Minute,
+			maxDelay:     -1,
+			wantRequests: 1,
+			wantErr:      "retry delay 2m0s exceeds the deadline",
+		},
+		"Retry-After as an HTTP date past the deadline": {
+			responses: []scriptedResponse{
+				{status: http.StatusServiceUnavailable, retryAfter: time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)},
+				ok,
+			},
+			timeout:      time.Minute,
+			maxDelay:     -1,
+			wantRequests: 1,
+			wantErr:      "exceeds the deadline",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := newScriptedServer(t, tt.responses)
+			options := RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+			if tt.maxDelay < 0 {
+				options.MaxDelay = 0
+			}
+			client := NewRetryClient(NewOpenAIClient("test-key", "test-model", 0, option.WithBaseURL(srv.URL+"/v1"), option.WithMaxRetries(0)), options)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			stats := NewStats(nil)
+			title, _, err := client.SummarizeContent(WithStats(ctx, stats), Prompt{System: "system", User: "user"}, "content")
+
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("SummarizeContent() error = %v", err)
+			case tt.wantErr == "" && title != "Guide":
+				t.Errorf("SummarizeContent() title = %q, want %q", title, "Guide")
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("SummarizeContent() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if srv.requests != tt.wantRequests {
+				t.Errorf("server got %d requests, want %d", srv.requests, tt.wantRequests)
+			}
+			if got := stats.Usage(); got.Requests != tt.wantRequests || got.Retries != tt.wantRequests-1 {
+				t.Errorf("recorded %d requests and %d retries, want %d and %d", got.Requests, got.Retries, tt.wantRequests, tt.wantRequests-1)
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	retryAfter := http.Header{"Retry-After": {"7"}}
+
+	tests := map[string]struct {
+		err            error
+		wantRetryable  bool
+		wantRetryAfter time.Duration
+	}{
+		"canceled":              {err: fmt.Errorf("request: %w", context.Canceled)},
+		"deadline exceeded":     {err: context.DeadlineExceeded},
+		"status 429":            {err: &StatusError{StatusCode: http.StatusTooManyRequests, Header: retryAfter}, wantRetryable: true, wantRetryAfter: 7 * time.Second},
+		"status 529":            {err: &StatusError{StatusCode: statusOverloaded}, wantRetryable: true},
+		"status 500 wrapped":    {err: fmt.Errorf("chat: %w", &StatusError{StatusCode: http.StatusInternalServerError}), wantRetryable: true},
+		"status 400":            {err: &StatusError{StatusCode: http.StatusBadRequest, Header: retryAfter}, wantRetryAfter: 7 * time.Second},
+		"status 401":            {err: &StatusError{StatusCode: http.StatusUnauthorized}},
+		"timeout":               {err: &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, wantRetryable: true},
+		"DNS timeout":           {err: &net.DNSError{Err: "timeout", Name: "example.com", IsTimeout: true}, wantRetryable: true},
+		"no such host":          {err: &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}},
+		"connection reset":      {err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, wantRetryable: true},
+		"connection refused":    {err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, wantRetryable: true},
+		"unexpected EOF":        {err: fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), wantRetryable: true},
+		"invalid address":       {err: &net.AddrError{Err: "missing port in address", Addr: "example.com"}},
+		"stream overloaded":     {err: parseStreamError(errors.New(streamErrorPrefix + `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)), wantRetryable: true},
+		"stream api error":      {err: parseStreamError(errors.New(streamErrorPrefix + `{"type":"error","error":{"type":"api_error","message":"Internal server error"}}`)), wantRetryable: true},
+		"stream invalid":        {err: parseStreamError(errors.New(streamErrorPrefix + `{"type":"error","error":{"type":"invalid_request_error","message":"api_error"}}`))},
+		"error type in message": {err: errors.New("parse response: unknown field api_error")},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			retryable, retryAfter := classifyError(tt.err)
+			if retryable != tt.wantRetryable || retryAfter != tt.wantRetryAfter {
+				t.Errorf("classifyError(%v) = (%t, %s), want (%t, %s)", tt.err, retryable, retryAfter, tt.wantRetryable, tt.wantRetryAfter)
+			}
+		})
+	}
+}
+
+func TestParseStreamError(t *testing.T) {
+	err := parseStreamError(errors.New(streamErrorPrefix + `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+	var streamErr *streamError
+	if !errors.As(err, &streamErr) || streamErr.Type != "overloaded_error" || streamErr.Message != "Overloaded" {
+		t.Errorf("parseStreamError() = %#v, want an overloaded_error", err)
+	}
+
+	for _, msg := range []string{"connection reset", streamErrorPrefix + "not JSON", streamErrorPrefix + `{"type":"error"}`} {
+		orig := errors.New(msg)
+		if err := parseStreamError(orig); err != orig {
+			t.Errorf("parseStreamError(%q) = %v, want the error unchanged", msg, err)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]struct {
+		header http.Header
+		// want is the expected delay, within a second for HTTP dates.
+		want time.Duration
+	}{
+		"none":             {header: http.Header{}, want: 0},
+		"seconds":          {header: http.Header{"Retry-After": {"3"}}, want: 3 * time.Second},
+		"fractional":       {header: http.Header{"Retry-After": {"1.5"}}, want: 1500 * time.Millisecond},
+		"milliseconds":     {header: http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"3"}}, want: 250 * time.Millisecond},
+		"HTTP date":        {header: http.Header{"Retry-After": {time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)}}, want: 30 * time.Second},
+		"HTTP date passed": {header: http.Header{"Retry-After": {time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)}}, want: 0},
+		"negative":         {header: http.Header{"Retry-After": {"-1"}}, want: 0},
+		"invalid":          {header: http.Header{"Retry-After": {"soon"}}, want: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := parseRetryAfter(tt.header)
+			if got > tt.want || got < tt.want-time.Second || (tt.want == 0 && got != 0) {
+				t.Errorf("parseRetryAfter(%v) = %s, want %s", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryClientBackoff(t *testing.T) {
+	tests := map[string]struct {
+		options RetryOptions
+		attempt int
+		// want is the ceiling of the backoff.
+		want time.Duration
+	}{
+		"first retry":   {options: RetryOptions{BaseDelay: time.Second}, attempt: 1, want: time.Second},
+		"doubled":       {options: RetryOptions{BaseDelay: time.Second}, attempt: 3, want: 4 * time.Second},
+		"capped":        {options: RetryOptions{BaseDelay: time.Second, MaxDelay: 3 * time.Second}, attempt: 3, want: 3 * time.Second},
+		"overflow":      {options: RetryOptions{BaseDelay: time.Hour, MaxDelay: time.Minute}, attempt: 100, want: time.Minute},
+		"no base delay": {options: RetryOptions{MaxDelay: time.Minute}, attempt: 2, want: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &retryClient{options: tt.options}
+			for range 100 {
+				got := c.backoff(tt.attempt)
+				if got > tt.want || (tt.want > 0 && got <= 0) {
+					t.Fatalf("backoff(%d) = %s, want in (0, %s]", tt.attempt, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"sync"
+)
+
+// Usage is the accumulated statistics of LLM requests.
+type Usage struct {
+	// Requests is the number of requests sent to the provider, including retries.
+	Requests int `json:"requests"`
+	// Retries is the number of requests which were retries of a failed request.
+	Retries int `json:"retries"`
+	// CacheHits is the number of responses served from the [Cache] without a request.
+	CacheHits int `json:"cache_hits"`
+	// Reasks is the number of requests which re-asked a response violating its [Prompt], see [NewValidateClient].
+	Reasks int `json:"reasks"`
+	// InputTokens and OutputTokens are the numbers of tokens reported by the provider, summed over the requests.
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	// CacheReadTokens and CacheWriteTokens are the numbers of input tokens read from and written to the prompt cache of
+	// the provider, not included in InputTokens. CacheWrite1hTokens of the CacheWriteTokens are cached for an hour.
+	CacheReadTokens    int `json:"cache_read_tokens,omitzero"`
+	CacheWriteTokens   int `json:"cache_write_tokens,omitzero"`
+	CacheWrite1hTokens int `json:"cache_write_1h_tokens,omitzero"`
+}
+
+// Add adds the statistics of u2 to u.
+func (u *Usage) Add(u2 Usage) {
+	u.Requests += u2.Requests
+	u.Retries += u2.Retries
+	u.CacheHits += u2.CacheHits
+	u.Reasks += u2.Reasks
+	u.InputTokens += u2.InputTokens
+	u.OutputTokens += u2.OutputTokens
+	u.CacheReadTokens += u2.CacheReadTokens
+	u.CacheWriteTokens += u2.CacheWriteTokens
+	u.CacheWrite1hTokens += u2.CacheWrite1hTokens
+}
+
+// Stats accumulates the [Usage] of the LLM requests made with a context returned by [WithStats].
+//
+// Stats is safe for concurrent use. A nil *Stats discards everything.
+type Stats struct {
+	parent *Stats
+
+	mu    sync.Mutex
+	usage Usage
+}
+
+// NewStats returns a new [Stats] which also adds everything recorded to parent, if not nil.
+func NewStats(parent *Stats) *Stats {
+	return &Stats{parent: parent}
+}
+
+// Usage returns the usage recorded so far.
+func (s *Stats) Usage() Usage {
+	if s == nil {
+		return Usage{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usage
+}
+
+func (s *Stats) add(u Usage) {
+	for ; s != nil; s = s.parent {
+		s.mu.Lock()
+		s.usage.Add(u)
+		s.mu.Unlock()
+	}
+}
+
+// addTokens records the tokens of a response into the [Stats] of ctx.
+func addTokens(ctx context.Context, input, output int) {
+	StatsFromContext(ctx).add(Usage{InputTokens: input, OutputTokens: output})
+}
+
+type statsKey struct{}
+
+// WithStats returns a copy of ctx which carries s. The clients of this package record their usage into s.
+func WithStats(ctx context.Context, s *Stats) context.Context {
+	return context.WithValue(ctx, statsKey{}, s)
+}
+
+// StatsFromContext returns the [Stats] carried by ctx, or nil.
+func StatsFromContext(ctx context.Context) *Stats {
+	s, _ := ctx.Value(statsKey{}).(*Stats)
+	return s
+}
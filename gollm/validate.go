@@ -0,0 +1,150 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// DefaultMaxReasks is the default maximum number of times a response violating its [Prompt] is re-asked.
+const DefaultMaxReasks = 2
+
+const reaskPromptNoteFmt = `Your previous response was:
+{"title": %q, "description": %q}
+
+It is invalid because %s. Respond again and follow the instructions exactly.`
+
+// reask returns the prompt re-asking the response of title and description to p, which violates p with err.
+func (p Prompt) reask(title, description string, err error) Prompt {
+	// the note goes to the suffix, so that the re-ask still hits the prompt cache of the provider
+	return p.withSuffix(fmt.Sprintf(reaskPromptNoteFmt, title, description, err))
+}
+
+type validateClient struct {
+	client    SummarizerClient
+	maxReasks int
+	logger    *slog.Logger
+}
+
+var _ SummarizerClient = (*validateClient)(nil)
+
+// NewValidateClient wraps client so that a response violating the word ranges of its [Prompt] is re-asked, with the
+// violation appended to the prompt, up to maxReasks times. The re-asks are recorded in the [Stats] of the context.
+//
+// If the response still violates the prompt, it is returned as is, since a title of a few words too many is more
+// useful than none.
+func NewValidateClient(client SummarizerClient, maxReasks int) SummarizerClient {
+	return &validateClient{
+		client:    client,
+		maxReasks: maxReasks,
+		logger:    slog.Default().WithGroup("validate"),
+	}
+}
+
+// SummarizeContent implements [SummarizerClient].
+func (c *validateClient) SummarizeContent(ctx context.Context, prompt Prompt, content string) (title, description string, err error) {
+	ask := prompt
+	for reasks := 0; ; reasks++ {
+		title, description, err = c.client.SummarizeContent(ctx, ask, content)
+		if err != nil {
+			return "", "", err
+		}
+
+		verr := prompt.Validate(title, description)
+		if verr == nil {
+			return title, description, nil
+		}
+		if reasks == c.maxReasks {
+			c.logger.WarnContext(ctx, "Keeping invalid response", slog.String("title", title), slog.String("description", description), slog.Any("error", verr))
+			return title, description, nil
+		}
+
+		c.logger.DebugContext(ctx, "Re-asking invalid response", slog.String("title", title), slog.String("description", description), slog.Any("error", verr))
+		StatsFromContext(ctx).add(Usage{Reasks: 1})
+		ask = prompt.reask(title, description, verr)
+	}
+}
+
+type validateBatchClient struct {
+	client    BatchClient
+	maxReasks int
+	logger    *slog.Logger
+}
+
+var _ BatchClient = (*validateBatchClient)(nil)
+
+// NewValidateBatchClient wraps client like [NewValidateClient], re-asking the responses violating their [Prompt] in a
+// following batch.
+func NewValidateBatchClient(client BatchClient, maxReasks int) BatchClient {
+	return &validateBatchClient{
+		client:    client,
+		maxReasks: maxReasks,
+		logger:    slog.Default().WithGroup("validate"),
+	}
+}
+
+// SummarizeBatch implements [BatchClient].
+func (c *validateBatchClient) SummarizeBatch(ctx context.Context, reqs []BatchRequest) ([]BatchResult, error) {
+	results, err := c.client.SummarizeBatch(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	for reasks := 0; reasks < c.maxReasks; reasks++ {
+		var (
+			asks    []BatchRequest
+			invalid []int
+		)
+		for i, r := range results {
+			if r.Err != nil {
+				continue
+			}
+			if verr := reqs[i].Prompt.Validate(r.Title, r.Description); verr != nil {
+				c.logger.DebugContext(ctx, "Re-asking invalid response", slog.String("title", r.Title), slog.String("description", r.Description), slog.Any("error", verr))
+				asks = append(asks, BatchRequest{Prompt: reqs[i].Prompt.reask(r.Title, r.Description, verr), Content: reqs[i].Content})
+				invalid = append(invalid, i)
+			}
+		}
+		if len(asks) == 0 {
+			return results, nil
+		}
+
+		StatsFromContext(ctx).add(Usage{Reasks: len(asks)})
+		askResults, err := c.client.SummarizeBatch(ctx, asks)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			c.logger.WarnContext(ctx, "Failed to re-ask invalid responses, keeping them", slog.Int("responses", len(asks)), slog.Any("error", err))
+			return results, nil
+		}
+		for j, i := range invalid {
+			r := askResults[j]
+			r.Usage.Add(results[i].Usage)
+			r.Usage.Reasks++
+			if r.Err != nil {
+				// keep the invalid response rather than failing the request
+				r.Title, r.Description, r.Err = results[i].Title, results[i].Description, nil
+			}
+			results[i] = r
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,281 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gollm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// validatedPrompt is a prompt requiring a title of 3-4 words and a description of 9-10 words.
+var validatedPrompt = Prompt{
+	System:      "system",
+	User:        "user",
+	Suffix:      "URL: https://example.com/",
+	Title:       WordRange{Min: 3, Max: 4},
+	Description: WordRange{Min: 9, Max: 10},
+}
+
+const (
+	validTitle       = "Getting Started Guide"
+	validDescription = "How to install, configure and run the tool quickly."
+)
+
+// response returns a [BatchResult] of title and description, using 100 input and 10 output tokens.
+func response(title, description string) BatchResult {
+	return BatchResult{Title: title, Description: description, Usage: Usage{Requests: 1, InputTokens: 100, OutputTokens: 10}}
+}
+
+func TestPromptValidate(t *testing.T) {
+	tests := map[string]struct {
+		title, description string
+		want               string
+	}{
+		"valid":             {title: validTitle, description: validDescription},
+		"title too long":    {title: "A Very Long Getting Started Guide", description: validDescription, want: "the title has 6 words instead of 3-4"},
+		"title too short":   {title: "Guide", description: validDescription, want: "the title has 1 words instead of 3-4"},
+		"both out of range": {title: "", description: "Too short.", want: "the title has 0 words instead of 3-4 and the description has 2 words instead of 9-10"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validatedPrompt.Validate(tt.title, tt.description)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) || err.Error() != tt.want {
+				t.Errorf("Validate() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+
+	if err := (Prompt{}).Validate("", ""); err != nil {
+		t.Errorf("Validate() of an unconstrained prompt error = %v", err)
+	}
+}
+
+func TestValidateClient(t *testing.T) {
+	tooLong := response("A Very Long Getting Started Guide", validDescription)
+	tooShort := response(validTitle, "Too short.")
+	valid := response(validTitle, validDescription)
+	failed := BatchResult{Err: errors.New("boom")}
+
+	tests := map[string]struct {
+		maxReasks       int
+		responses       []BatchResult
+		wantTitle       string
+		wantDescription string
+		wantErr         bool
+		wantCalls       int
+		// wantNotes are the violations noted in the prompts of the re-asks.
+		wantNotes []string
+	}{
+		"valid": {
+			maxReasks: 2,
+			responses: []BatchResult{valid},
+			wantTitle: validTitle, wantDescription: validDescription,
+			wantCalls: 1,
+		},
+		"too long then valid": {
+			maxReasks: 2,
+			responses: []BatchResult{tooLong, valid},
+			wantTitle: validTitle, wantDescription: validDescription,
+			wantCalls: 2,
+			wantNotes: []string{"the title has 6 words instead of 3-4"},
+		},
+		"too short until the limit": {
+			maxReasks: 2,
+			responses: []BatchResult{tooShort},
+			wantTitle: validTitle, wantDescription: "Too short.",
+			wantCalls: 3,
+			wantNotes: []string{"the description has 2 words instead of 9-10", "the description has 2 words instead of 9-10"},
+		},
+		"re-asks disabled": {
+			maxReasks: 0,
+			responses: []BatchResult{tooLong, valid},
+			wantTitle: tooLong.Title, wantDescription: validDescription,
+			wantCalls: 1,
+		},
+		"error": {
+			maxReasks: 2,
+			responses: []BatchResult{failed},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		"error of a re-ask": {
+			maxReasks: 2,
+			responses: []BatchResult{tooLong, failed},
+			wantErr:   true,
+			wantCalls: 2,
+			wantNotes: []string{"the title has 6 words instead of 3-4"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := &scriptedClient{responses: tt.responses}
+			client := NewValidateClient(fake, tt.maxReasks)
+
+			stats := NewStats(nil)
+			title, description, err := client.SummarizeContent(WithStats(context.Background(), stats), validatedPrompt, "content")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SummarizeContent() error = %v, want error %t", err, tt.wantErr)
+			}
+			if title != tt.wantTitle || description != tt.wantDescription {
+				t.Errorf("SummarizeContent() = (%q, %q), want (%q, %q)", title, description, tt.wantTitle, tt.wantDescription)
+			}
+
+			if fake.calls != tt.wantCalls {
+				t.Fatalf("got %d requests, want %d", fake.calls, tt.wantCalls)
+			}
+			checkReasks(t, fake.prompts, tt.wantNotes)
+
+			// each re-ask is a request of its own, whose tokens add up
+			var want Usage
+			for i := range fake.calls {
+				want.Add(tt.responses[min(i, len(tt.responses)-1)].Usage)
+			}
+			want.Reasks = len(tt.wantNotes)
+			if got := stats.Usage(); got != want {
+				t.Errorf("usage = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// checkReasks checks that the first prompt is the original one, and the following ones re-ask with the notes.
+func checkReasks(t *testing.T, prompts []Prompt, notes []string) {
+	t.Helper()
+
+	if len(prompts) == 0 {
+		return
+	}
+	if prompts[0] != validatedPrompt {
+		t.Errorf("first prompt = %+v, want the original prompt", prompts[0])
+	}
+	for i, note := range notes {
+		if i+1 >= len(prompts) {
+			t.Errorf("missing re-ask %d with %q", i+1, note)
+			continue
+		}
+		p := prompts[i+1]
+		if p.System != validatedPrompt.System || p.User != validatedPrompt.User {
+			t.Errorf("re-ask %d changed the static prefix of the prompt: %+v", i+1, p)
+		}
+		if !strings.HasPrefix(p.Suffix, validatedPrompt.Suffix+"\n\nYour previous response was:") || !strings.Contains(p.Suffix, "It is invalid because "+note+".") {
+			t.Errorf("re-ask %d suffix = %q, want the previous response and %q", i+1, p.Suffix, note)
+		}
+	}
+}
+
+func TestValidateBatchClient(t *testing.T) {
+	tooLong := response("A Very Long Getting Started Guide", validDescription)
+	valid := response(validTitle, validDescription)
+	failed := BatchResult{Err: errors.New("boom")}
+
+	reqs := []BatchRequest{
+		{Prompt: validatedPrompt, Content: "zero"},
+		{Prompt: validatedPrompt, Content: "one"},
+		{Prompt: validatedPrompt, Content: "two"},
+	}
+
+	tests := map[string]struct {
+		maxReasks int
+		// responses are the responses of the requests of the batches in order: first reqs, then the re-asks.
+		responses   []BatchResult
+		want        []BatchResult
+		wantBatches []int
+		wantReasks  int
+	}{
+		"invalid then valid": {
+			maxReasks:   2,
+			responses:   []BatchResult{valid, tooLong, failed, valid},
+			wantBatches: []int{3, 1},
+			wantReasks:  1,
+			want: []BatchResult{
+				valid,
+				{Title: validTitle, Description: validDescription, Usage: Usage{Requests: 2, Reasks: 1, InputTokens: 200, OutputTokens: 20}},
+				failed,
+			},
+		},
+		"invalid until the limit": {
+			maxReasks:   2,
+			responses:   []BatchResult{tooLong, valid, valid, tooLong, tooLong},
+			wantBatches: []int{3, 1, 1},
+			wantReasks:  2,
+			want: []BatchResult{
+				{Title: tooLong.Title, Description: validDescription, Usage: Usage{Requests: 3, Reasks: 2, InputTokens: 300, OutputTokens: 30}},
+				valid,
+				valid,
+			},
+		},
+		"failed re-ask keeps the invalid response": {
+			maxReasks:   1,
+			responses:   []BatchResult{tooLong, valid, valid, failed},
+			wantBatches: []int{3, 1},
+			wantReasks:  1,
+			want: []BatchResult{
+				{Title: tooLong.Title, Description: validDescription, Usage: Usage{Requests: 1, Reasks: 1, InputTokens: 100, OutputTokens: 10}},
+				valid,
+				valid,
+			},
+		},
+		"re-asks disabled": {
+			maxReasks:   0,
+			responses:   []BatchResult{tooLong, valid, valid},
+			wantBatches: []int{3},
+			want:        []BatchResult{tooLong, valid, valid},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := &scriptedClient{responses: tt.responses}
+			client := NewValidateBatchClient(fake, tt.maxReasks)
+
+			stats := NewStats(nil)
+			results, err := client.SummarizeBatch(WithStats(context.Background(), stats), reqs)
+			if err != nil {
+				t.Fatalf("SummarizeBatch() error = %v", err)
+			}
+
+			if len(fake.batches) != len(tt.wantBatches) {
+				t.Fatalf("got batches of %v requests, want %v", fake.batches, tt.wantBatches)
+			}
+			for i, n := range tt.wantBatches {
+				if fake.batches[i] != n {
+					t.Errorf("got batches of %v requests, want %v", fake.batches, tt.wantBatches)
+				}
+			}
+			if len(results) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := results[i]
+				if got.Title != want.Title || got.Description != want.Description || got.Usage != want.Usage || (got.Err != nil) != (want.Err != nil) {
+					t.Errorf("result %d = %+v, want %+v", i, got, want)
+				}
+			}
+			if got := stats.Usage().Reasks; got != tt.wantReasks {
+				t.Errorf("recorded %d re-asks, want %d", got, tt.wantReasks)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package llmstxt parses, validates and formats llms.txt files.
+//
+// The format is defined at https://llmstxt.org/#format:
+//   - an H1 with the name of the project or site, which is the only required section
+//   - a blockquote with a short summary of the project
+//   - zero or more Markdown sections of any type except headings, with more detailed information
+//   - zero or more sections delimited by H2 headers, containing lists of links with optional notes
+//
+// An H2 section named "Optional" has a special meaning: its links may be skipped when a shorter context is needed.
+package llmstxt
+
+import (
+	"strings"
+)
+
+// OptionalSection is the name of the section whose links may be skipped when a shorter context is needed.
+const OptionalSection = "Optional"
+
+// Document is a parsed llms.txt file.
+type Document struct {
+	// Title is the text of the H1.
+	Title string `json:"title"`
+	// Summary is the text of the blockquote following the H1. Multiple lines are joined by a space.
+	Summary string `json:"summary,omitempty"`
+	// Details is the Markdown between the summary and the first H2, without surrounding blank lines.
+	Details string `json:"details,omitempty"`
+	// Sections are the H2 sections in document order.
+	Sections []*Section `json:"sections,omitempty"`
+}
+
+// Section is an H2 section of a [Document].
+type Section struct {
+	Name  string  `json:"name"`
+	Links []*Link `json:"links,omitempty"`
+	// Line is the 1-based line number of the H2, or zero if the section was not parsed.
+	Line int `json:"line,omitempty"`
+}
+
+// Link is a list item of a [Section] in the "- [Title](URL): Description" form.
+type Link struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+	// Line is the 1-based line number of the list item, or zero if the link was not parsed.
+	Line int `json:"line,omitempty"`
+}
+
+// Section returns the section named name, or nil.
+func (d *Document) Section(name string) *Section {
+	for _, section := range d.Sections {
+		if section.Name == name {
+			return section
+		}
+	}
+	return nil
+}
+
+// String formats d as llms.txt. Parsing the result yields a [Document] equal to d, except for line numbers, whitespace
+// collapsed into single spaces and the characters of link URLs which are percent-encoded to keep them within the link.
+func (d *Document) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("# ")
+	sb.WriteString(headingText(d.Title))
+	sb.WriteString("\n")
+
+	if summary := singleLine(d.Summary); summary != "" {
+		sb.WriteString("\n> ")
+		sb.WriteString(summary)
+		sb.WriteString("\n")
+	}
+
+	if details := trimBlankLines(d.Details); details != "" {
+		sb.WriteString("\n")
+		sb.WriteString(details)
+		sb.WriteString("\n")
+	}
+
+	for _, section := range d.Sections {
+		sb.WriteString("\n## ")
+		sb.WriteString(headingText(section.Name))
+		sb.WriteString("\n")
+		if len(section.Links) > 0 {
+			sb.WriteString("\n")
+		}
+		for _, link := range section.Links {
+			sb.WriteString(link.String())
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// String formats l as a Markdown list item.
+func (l *Link) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("- [")
+	sb.WriteString(linkTextEscaper.Replace(singleLine(l.Title)))
+	sb.WriteString("](")
+	sb.WriteString(linkURLEscaper.Replace(l.URL))
+	sb.WriteString(")")
+	if description := singleLine(l.Description); description != "" {
+		sb.WriteString(": ")
+		sb.WriteString(description)
+	}
+
+	return sb.String()
+}
+
+// singleLine collapses the whitespace of s, including newlines, into single spaces.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// headingText returns s as the text of an ATX heading. A closing sequence is added if s ends with "#", which would
+// otherwise be taken as the closing sequence itself.
+func headingText(s string) string {
+	s = singleLine(s)
+	if strings.HasSuffix(s, "#") {
+		s += " #"
+	}
+	return s
+}
+
+// trimBlankLines removes the leading and trailing blank lines of s, keeping the indentation of the first line,
+// which is significant in Markdown.
+func trimBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	linkTextEscaper   = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+	linkTextUnescaper = strings.NewReplacer(`\\`, `\`, `\[`, `[`, `\]`, `]`)
+	linkURLEscaper    = strings.NewReplacer(" ", "%20", "\t", "%09", "\n", "%0A", "\f", "%0C", "\r", "%0D", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+)
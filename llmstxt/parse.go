@@ -0,0 +1,365 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package llmstxt
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Severity is the severity of a [Diagnostic].
+type Severity string
+
+// Severities of [Diagnostic].
+const (
+	// SeverityError is a violation of the llms.txt format.
+	SeverityError Severity = "error"
+	// SeverityWarning is valid but likely unintended, or not recommended.
+	SeverityWarning Severity = "warning"
+)
+
+// Codes of [Diagnostic].
+const (
+	CodeMissingTitle      = "missing-title"
+	CodeContentBeforeH1   = "content-before-title"
+	CodeMultipleTitles    = "multiple-titles"
+	CodeMissingSummary    = "missing-summary"
+	CodeHeadingInDetails  = "heading-in-details"
+	CodeInvalidLink       = "invalid-link"
+	CodeInvalidURL        = "invalid-url"
+	CodeRelativeURL       = "relative-url"
+	CodeDuplicateURL      = "duplicate-url"
+	CodeEmptySection      = "empty-section"
+	CodeDuplicateSection  = "duplicate-section"
+	CodeOptionalNotLast   = "optional-not-last"
+	CodeUnexpectedContent = "unexpected-content"
+	CodeUnclosedFence     = "unclosed-code-fence"
+)
+
+// Diagnostic is a problem found by [Parse].
+type Diagnostic struct {
+	// Line is the 1-based line number of the problem.
+	Line     int      `json:"line"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+}
+
+// String formats d as "line: severity: message (code)".
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d: %s: %s (%s)", d.Line, d.Severity, d.Message, d.Code)
+}
+
+// HasErrors reports whether diags contains a [SeverityError].
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	reHeading  = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	reListItem = regexp.MustCompile(`^ {0,3}[-*+][ \t]+(.*)$`)
+	reLink     = regexp.MustCompile(`^\[((?:\\.|[^\\\]])*)\]\(\s*(<[^>]*>|[^\s)]*)\s*\)(?:\s*:\s*(.*))?$`)
+	reFence    = regexp.MustCompile("^ {0,3}(```+|~~~+)")
+)
+
+// parser states, in document order.
+const (
+	stateStart = iota
+	stateSummary
+	stateDetails
+	stateSection
+)
+
+// Parse parses src as llms.txt and returns the document along with the problems found, in line order.
+//
+// Parse never fails: it recovers from every problem and returns as much of the document as it can.
+// Use [HasErrors] to check whether src is a valid llms.txt.
+func Parse(src string) (*Document, []Diagnostic) {
+	p := &parser{
+		doc:      &Document{},
+		urls:     make(map[string]int),
+		sections: make(map[string]int),
+	}
+
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		p.line(i+1, line)
+	}
+	p.finish()
+
+	return p.doc, p.diags
+}
+
+type parser struct {
+	doc   *Document
+	diags []Diagnostic
+
+	state       int
+	beforeTitle bool
+	titleLine   int
+	summary     []string
+	details     []string
+	fence       string // the opening code fence, if inside a code block
+	fenceLine   int
+	section     *Section
+	lastLink    *Link
+	notes       []string       // the notes of lastLink followed by its continuation lines
+	urls        map[string]int // link URL to line
+	sections    map[string]int // section name to line
+	optionalAt  int            // line of the Optional section
+}
+
+func (p *parser) report(line int, severity Severity, code, format string, args ...any) {
+	p.diags = append(p.diags, Diagnostic{
+		Line:     line,
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (p *parser) line(n int, line string) {
+	// trailing whitespace is insignificant, and a stray carriage return must not hide a heading
+	line = strings.TrimRightFunc(line, unicode.IsSpace)
+	trimmed := strings.TrimSpace(line)
+
+	// code blocks are opaque: they may contain anything, including lines which look like headings
+	if p.fence != "" {
+		if strings.HasPrefix(trimmed, p.fence[:3]) && strings.Trim(trimmed, p.fence[:1]) == "" && len(trimmed) >= len(p.fence) {
+			p.fence = ""
+		}
+		p.content(n, line)
+		return
+	}
+	if m := reFence.FindStringSubmatch(line); m != nil {
+		p.fence, p.fenceLine = m[1], n
+		p.content(n, line)
+		return
+	}
+
+	if m := reHeading.FindStringSubmatch(line); m != nil {
+		p.heading(n, len(m[1]), strings.TrimSpace(m[2]))
+		return
+	}
+
+	p.content(n, line)
+}
+
+func (p *parser) heading(n, level int, text string) {
+	switch {
+	case level == 1 && p.state == stateStart:
+		p.doc.Title = text
+		p.titleLine = n
+		p.state = stateSummary
+		if text == "" {
+			p.report(n, SeverityError, CodeMissingTitle, "the H1 has no text")
+		}
+
+	case level == 1:
+		p.report(n, SeverityError, CodeMultipleTitles, "only one H1 is allowed; the first is on line %d", p.titleLine)
+
+	case level == 2:
+		p.startTitleIfMissing(n)
+		p.finishPreamble()
+		p.flushLink()
+		p.section = &Section{Name: text, Line: n}
+		p.doc.Sections = append(p.doc.Sections, p.section)
+		p.state = stateSection
+
+		if first, ok := p.sections[text]; ok {
+			p.report(n, SeverityWarning, CodeDuplicateSection, "section %q is already defined on line %d", text, first)
+		} else {
+			p.sections[text] = n
+		}
+		if text == OptionalSection {
+			p.optionalAt = n
+		}
+
+	case p.state == stateSection:
+		p.report(n, SeverityWarning, CodeUnexpectedContent, "H%d headings are not expected in a section; use H2 sections instead", level)
+
+	default:
+		p.startTitleIfMissing(n)
+		p.report(n, SeverityError, CodeHeadingInDetails, "the details before the first H2 must not contain headings")
+		p.state = stateDetails
+		p.details = append(p.details, strings.Repeat("#", level)+" "+text)
+	}
+}
+
+// startTitleIfMissing reports a missing H1 when the document starts with something else.
+func (p *parser) startTitleIfMissing(n int) {
+	if p.state != stateStart {
+		return
+	}
+	p.report(n, SeverityError, CodeMissingTitle, "llms.txt must start with an H1 with the name of the project or site")
+	p.state = stateSummary
+}
+
+func (p *parser) content(n int, line string) {
+	trimmed := strings.TrimSpace(line)
+
+	switch p.state {
+	case stateStart:
+		// content before the H1 is reported once and dropped, so that a following H1 is still the title
+		if trimmed == "" || p.beforeTitle {
+			return
+		}
+		p.report(n, SeverityError, CodeContentBeforeH1, "content before the H1 is not allowed")
+		p.beforeTitle = true
+
+	case stateSummary:
+		if quote, ok := strings.CutPrefix(trimmed, ">"); ok && p.fence == "" && len(p.details) == 0 {
+			p.summary = append(p.summary, strings.TrimSpace(quote))
+			return
+		}
+		if trimmed == "" && len(p.details) == 0 {
+			return
+		}
+		p.state = stateDetails
+		p.details = append(p.details, line)
+
+	case stateDetails:
+		p.details = append(p.details, line)
+
+	case stateSection:
+		p.sectionContent(n, line, trimmed)
+	}
+}
+
+func (p *parser) sectionContent(n int, line, trimmed string) {
+	if trimmed == "" {
+		return
+	}
+
+	m := reListItem.FindStringSubmatch(line)
+	if m == nil {
+		// indented continuation lines belong to the notes of the previous link
+		if p.lastLink != nil && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			p.notes = append(p.notes, trimmed)
+			return
+		}
+		p.flushLink()
+		p.report(n, SeverityWarning, CodeUnexpectedContent, "sections should only contain a list of links")
+		return
+	}
+
+	p.flushLink()
+	link := reLink.FindStringSubmatch(strings.TrimSpace(m[1]))
+	if link == nil {
+		p.report(n, SeverityError, CodeInvalidLink, "list items must be links in the form \"- [name](url): notes\"")
+		return
+	}
+
+	rawURL := link[2]
+	if len(rawURL) >= 2 && rawURL[0] == '<' && rawURL[len(rawURL)-1] == '>' {
+		rawURL = rawURL[1 : len(rawURL)-1]
+	}
+	l := &Link{
+		Title:       singleLine(linkTextUnescaper.Replace(link[1])),
+		URL:         rawURL,
+		Description: singleLine(link[3]),
+		Line:        n,
+	}
+	p.section.Links = append(p.section.Links, l)
+	p.lastLink = l
+	p.notes = []string{l.Description}
+
+	if l.Title == "" {
+		p.report(n, SeverityError, CodeInvalidLink, "the link has no name")
+	}
+	p.checkURL(n, rawURL)
+}
+
+func (p *parser) checkURL(n int, rawURL string) {
+	if rawURL == "" {
+		p.report(n, SeverityError, CodeInvalidURL, "the link has no URL")
+		return
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		p.report(n, SeverityError, CodeInvalidURL, "invalid URL %q: %v", rawURL, err)
+		return
+	}
+	if !u.IsAbs() {
+		p.report(n, SeverityWarning, CodeRelativeURL, "relative URL %q; absolute URLs are recommended", rawURL)
+	}
+
+	if first, ok := p.urls[rawURL]; ok {
+		p.report(n, SeverityWarning, CodeDuplicateURL, "URL %q is already listed on line %d", rawURL, first)
+	} else {
+		p.urls[rawURL] = n
+	}
+}
+
+// finishPreamble stores the summary and details collected before the first H2.
+func (p *parser) finishPreamble() {
+	if p.state != stateSummary && p.state != stateDetails {
+		return
+	}
+
+	p.doc.Summary = singleLine(strings.Join(p.summary, " "))
+	p.doc.Details = trimBlankLines(strings.Join(p.details, "\n"))
+	if p.doc.Summary == "" && p.titleLine > 0 {
+		p.report(p.titleLine, SeverityWarning, CodeMissingSummary, "the H1 should be followed by a blockquote with a short summary")
+	}
+}
+
+func (p *parser) finish() {
+	if p.state == stateStart {
+		p.report(1, SeverityError, CodeMissingTitle, "llms.txt must start with an H1 with the name of the project or site")
+		return
+	}
+	if p.fence != "" {
+		p.report(p.fenceLine, SeverityError, CodeUnclosedFence, "the code block is not closed")
+	}
+	p.finishPreamble()
+	p.flushLink()
+
+	for _, section := range p.doc.Sections {
+		if len(section.Links) == 0 {
+			p.report(section.Line, SeverityWarning, CodeEmptySection, "section %q has no links", section.Name)
+		}
+	}
+	if p.optionalAt > 0 && p.doc.Sections[len(p.doc.Sections)-1].Name != OptionalSection {
+		p.report(p.optionalAt, SeverityWarning, CodeOptionalNotLast, "the %q section should be the last section", OptionalSection)
+	}
+
+	// a stable sort keeps the report order of diagnostics on the same line
+	slices.SortStableFunc(p.diags, func(a, b Diagnostic) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+}
+
+// flushLink joins the notes of the last link with its indented continuation lines.
+func (p *parser) flushLink() {
+	if p.lastLink == nil {
+		return
+	}
+	p.lastLink.Description = singleLine(strings.Join(p.notes, " "))
+	p.lastLink, p.notes = nil, nil
+}
@@ -0,0 +1,324 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package llmstxt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		src  string
+		want *Document
+	}{
+		"full": {
+			src: `# Example
+
+> Example is a tool
+> for examples.
+
+Example is written in Go.
+
+- It is fast.
+
+## Docs
+
+- [Guide](https://example.com/guide): How to start.
+- [API](<https://example.com/api reference>)
+- [Notes \[beta\]](https://example.com/notes): Notes which
+  continue on the next line.
+
+## Optional
+
+- [Blog](https://example.com/blog)
+`,
+			want: &Document{
+				Title:   "Example",
+				Summary: "Example is a tool for examples.",
+				Details: "Example is written in Go.\n\n- It is fast.",
+				Sections: []*Section{
+					{Name: "Docs", Line: 10, Links: []*Link{
+						{Title: "Guide", URL: "https://example.com/guide", Description: "How to start.", Line: 12},
+						{Title: "API", URL: "https://example.com/api reference", Line: 13},
+						{Title: "Notes [beta]", URL: "https://example.com/notes", Description: "Notes which continue on the next line.", Line: 14},
+					}},
+					{Name: OptionalSection, Line: 17, Links: []*Link{
+						{Title: "Blog", URL: "https://example.com/blog", Line: 19},
+					}},
+				},
+			},
+		},
+		"title and summary only": {
+			src:  "# Example\n> Summary.",
+			want: &Document{Title: "Example", Summary: "Summary."},
+		},
+		"closing sequence and CRLF": {
+			src: "# C# #\r\n\r\n> Summary.\r\n\r\n## Docs ##\r\n\r\n* [A](https://example.com/a):   spaced   note\r\n",
+			want: &Document{
+				Title:   "C#",
+				Summary: "Summary.",
+				Sections: []*Section{
+					{Name: "Docs", Line: 5, Links: []*Link{{Title: "A", URL: "https://example.com/a", Description: "spaced note", Line: 7}}},
+				},
+			},
+		},
+		"headings in code blocks": {
+			src: "# Example\n\n> Summary.\n\n```md\n## Not a section\n```\n\n## Docs\n\n- [A](https://example.com/a)\n",
+			want: &Document{
+				Title:   "Example",
+				Summary: "Summary.",
+				Details: "```md\n## Not a section\n```",
+				Sections: []*Section{
+					{Name: "Docs", Line: 9, Links: []*Link{{Title: "A", URL: "https://example.com/a", Line: 11}}},
+				},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, diags := Parse(tt.src)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %s, want %s", sprintDocument(got), sprintDocument(tt.want))
+			}
+			if len(diags) > 0 {
+				t.Errorf("Parse() diagnostics = %v, want none", diags)
+			}
+		})
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	// diag is the line, severity and code of a [Diagnostic].
+	type diag struct {
+		line     int
+		severity Severity
+		code     string
+	}
+	tests := map[string]struct {
+		src  string
+		want []diag
+	}{
+		"empty": {
+			src:  "",
+			want: []diag{{1, SeverityError, CodeMissingTitle}},
+		},
+		"content before the title": {
+			src:  "Preamble\nmore\n# Example\n\n> Summary.\n",
+			want: []diag{{1, SeverityError, CodeContentBeforeH1}},
+		},
+		"missing title and summary": {
+			src:  "## Docs\n\n- [A](https://example.com/a)\n",
+			want: []diag{{1, SeverityError, CodeMissingTitle}},
+		},
+		"missing summary": {
+			src:  "# Example\n\n## Docs\n\n- [A](https://example.com/a)\n",
+			want: []diag{{1, SeverityWarning, CodeMissingSummary}},
+		},
+		"empty title": {
+			src:  "#\n\n> Summary.\n",
+			want: []diag{{1, SeverityError, CodeMissingTitle}},
+		},
+		"multiple titles and heading in details": {
+			src: "# Example\n\n> Summary.\n\n### Details\n# Again\n",
+			want: []diag{
+				{5, SeverityError, CodeHeadingInDetails},
+				{6, SeverityError, CodeMultipleTitles},
+			},
+		},
+		"links": {
+			src: `# Example
+
+> Summary.
+
+## Docs
+
+- [](https://example.com/a)
+- [B]()
+- [C](/c)
+- [D](https://example.com/d)
+- [E](https://example.com/d)
+- not a link
+Some text.
+### Subsection
+- [F](http://[::1)
+`,
+			want: []diag{
+				{7, SeverityError, CodeInvalidLink},
+				{8, SeverityError, CodeInvalidURL},
+				{9, SeverityWarning, CodeRelativeURL},
+				{11, SeverityWarning, CodeDuplicateURL},
+				{12, SeverityError, CodeInvalidLink},
+				{13, SeverityWarning, CodeUnexpectedContent},
+				{14, SeverityWarning, CodeUnexpectedContent},
+				{15, SeverityError, CodeInvalidURL},
+			},
+		},
+		"sections": {
+			src: "# Example\n\n> Summary.\n\n## Optional\n\n- [A](https://example.com/a)\n\n## Docs\n\n## Docs\n\n- [B](https://example.com/b)\n",
+			want: []diag{
+				{5, SeverityWarning, CodeOptionalNotLast},
+				{9, SeverityWarning, CodeEmptySection},
+				{11, SeverityWarning, CodeDuplicateSection},
+			},
+		},
+		"unclosed code fence": {
+			src:  "# Example\n\n> Summary.\n\n~~~\n## Docs\n",
+			want: []diag{{5, SeverityError, CodeUnclosedFence}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, diags := Parse(tt.src)
+
+			got := make([]diag, len(diags))
+			for i, d := range diags {
+				got[i] = diag{d.Line, d.Severity, d.Code}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() diagnostics = %v, want %v", diags, tt.want)
+			}
+			wantErrors := false
+			for _, d := range tt.want {
+				wantErrors = wantErrors || d.severity == SeverityError
+			}
+			if HasErrors(diags) != wantErrors {
+				t.Errorf("HasErrors() = %t, want %t", HasErrors(diags), wantErrors)
+			}
+		})
+	}
+}
+
+func TestDocumentString(t *testing.T) {
+	doc := &Document{
+		Title:   "Example #",
+		Summary: "Example is a tool\nfor examples.",
+		Details: "\n  Indented details.\n\nMore details.\n\n",
+		Sections: []*Section{
+			{Name: "Docs", Links: []*Link{
+				{Title: "Guide [v2]", URL: "https://example.com/guide (v2)", Description: "How\nto start."},
+				{Title: "API", URL: "https://example.com/api"},
+			}},
+			{Name: "Empty"},
+			{Name: OptionalSection, Links: []*Link{
+				{Title: "Blog", URL: "https://example.com/blog", Description: "News."},
+			}},
+		},
+	}
+
+	want := `# Example # #
+
+> Example is a tool for examples.
+
+  Indented details.
+
+More details.
+
+## Docs
+
+- [Guide \[v2\]](https://example.com/guide%20%28v2%29): How to start.
+- [API](https://example.com/api)
+
+## Empty
+
+## Optional
+
+- [Blog](https://example.com/blog): News.
+`
+	got := doc.String()
+	if got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+
+	// parsing the output yields the document again, with line numbers and normalized whitespace and URLs
+	parsed, diags := Parse(got)
+	for _, d := range diags {
+		if d.Code != CodeEmptySection {
+			t.Errorf("Parse(String()) diagnostic %v, want none but %s", d, CodeEmptySection)
+		}
+	}
+	for _, section := range parsed.Sections {
+		section.Line = 0
+		for _, link := range section.Links {
+			link.Line = 0
+		}
+	}
+	roundTrip := &Document{
+		Title:   "Example #",
+		Summary: "Example is a tool for examples.",
+		Details: "  Indented details.\n\nMore details.",
+		Sections: []*Section{
+			{Name: "Docs", Links: []*Link{
+				{Title: "Guide [v2]", URL: "https://example.com/guide%20%28v2%29", Description: "How to start."},
+				{Title: "API", URL: "https://example.com/api"},
+			}},
+			{Name: "Empty"},
+			{Name: OptionalSection, Links: []*Link{
+				{Title: "Blog", URL: "https://example.com/blog", Description: "News."},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(parsed, roundTrip) {
+		t.Errorf("Parse(String()) = %s, want %s", sprintDocument(parsed), sprintDocument(roundTrip))
+	}
+	if got := parsed.Section(OptionalSection); got == nil || len(got.Links) != 1 {
+		t.Errorf("Section(%q) = %+v, want the section with 1 link", OptionalSection, got)
+	}
+}
+
+// sprintDocument formats doc with its sections and links for test failures.
+func sprintDocument(doc *Document) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "{Title:%q Summary:%q Details:%q Sections:[", doc.Title, doc.Summary, doc.Details)
+	for _, section := range doc.Sections {
+		fmt.Fprintf(&sb, "{Name:%q Line:%d Links:[", section.Name, section.Line)
+		for _, link := range section.Links {
+			fmt.Fprintf(&sb, "%+v ", *link)
+		}
+		sb.WriteString("]} ")
+	}
+	sb.WriteString("]}")
+	return sb.String()
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("# Title\n\n> Summary.\n\nDetails.\n\n## Docs\n\n- [Guide](https://example.com/guide): How to start.\n")
+	f.Add("# Title #\n\n## Optional\n\n- [A](<https://example.com/a b>)\n  continued note\n- [B\\]](https://example.com/b)\n")
+	f.Add("Preamble\n# Title\n> one\n> two\n```\n## not a section\n```\n## Docs\n* [x](y)\n")
+	f.Add("#\n##\n- [](https://example.com/)\n## Docs\n## Docs\n")
+	f.Add("# T\r\n\r\n## S\r\n\r\n- [A](https://example.com/a):\r\n")
+
+	f.Fuzz(func(t *testing.T, src string) {
+		doc, diags := Parse(src)
+
+		lines := strings.Count(strings.ReplaceAll(src, "\r\n", "\n"), "\n") + 1
+		for _, diag := range diags {
+			if diag.Line < 0 || diag.Line > lines {
+				t.Errorf("diagnostic %v is outside of the %d lines", diag, lines)
+			}
+		}
+
+		// String normalizes the document, so formatting it again must not change it
+		want := doc.String()
+		reparsed, _ := Parse(want)
+		if got := reparsed.String(); got != want {
+			t.Errorf("Parse(%q).String() = %q, want %q", want, got, want)
+		}
+	})
+}
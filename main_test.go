@@ -0,0 +1,151 @@
+// Copyright 2025 The llmstxt-generator Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-json-experiment/json"
+)
+
+// runMainEnv makes the test binary run main instead of the tests, so that its exit code can be checked.
+const runMainEnv = "LLMSTXT_GENERATOR_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs main with args and returns its stdout and exit code.
+func runMain(t *testing.T, args ...string) (stdout string, code int) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runMainEnv+"=1")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		code = exitErr.ExitCode()
+	case err != nil:
+		t.Fatalf("run main: %v", err)
+	}
+	return out.String(), code
+}
+
+func TestValidate(t *testing.T) {
+	const (
+		valid = "# Example\n\n> Summary.\n\n## Docs\n\n- [Guide](https://example.com/guide): How to start.\n"
+		// warnings has a relative URL and an empty section.
+		warnings = "# Example\n\n> Summary.\n\n## Docs\n\n- [Guide](/guide)\n\n## Empty\n"
+		// invalid has no H1 and a link without URL.
+		invalid = "## Docs\n\n- [Guide]()\n"
+	)
+
+	tests := map[string]struct {
+		src      string
+		strict   bool
+		wantCode int
+		want     map[string]any
+	}{
+		"valid": {
+			src:  valid,
+			want: map[string]any{"valid": true, "errors": 0.0, "warnings": 0.0, "sections": 1.0, "links": 1.0},
+		},
+		"warnings": {
+			src:  warnings,
+			want: map[string]any{"valid": true, "errors": 0.0, "warnings": 2.0, "sections": 2.0, "links": 1.0},
+		},
+		"warnings with strict": {
+			src:      warnings,
+			strict:   true,
+			wantCode: 1,
+			want:     map[string]any{"valid": false, "errors": 0.0, "warnings": 2.0},
+		},
+		"errors": {
+			src:      invalid,
+			wantCode: 1,
+			want:     map[string]any{"valid": false, "errors": 2.0, "warnings": 0.0, "sections": 1.0, "links": 1.0},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "llms.txt")
+			if err := os.WriteFile(path, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			args := []string{"validate", "--json", path}
+			if tt.strict {
+				args = append(args, "--strict")
+			}
+			stdout, code := runMain(t, args...)
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+
+			var report map[string]any
+			if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+				t.Fatalf("unmarshal report %q: %v", stdout, err)
+			}
+			if report["file"] != path {
+				t.Errorf("report file = %v, want %s", report["file"], path)
+			}
+			for key, want := range tt.want {
+				if report[key] != want {
+					t.Errorf("report %s = %v, want %v", key, report[key], want)
+				}
+			}
+			diags, _ := report["diagnostics"].([]any)
+			if got, want := len(diags), int(tt.want["errors"].(float64)+tt.want["warnings"].(float64)); got != want {
+				t.Errorf("report has %d diagnostics, want %d: %s", got, want, stdout)
+			}
+		})
+	}
+}
+
+func TestValidateText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llms.txt")
+	if err := os.WriteFile(path, []byte("# Example\n\n> Summary.\n\n## Docs\n\n- [Guide](/guide)\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, code := runMain(t, "validate", path)
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	want := path + `:7: warning: relative URL "/guide"; absolute URLs are recommended (relative-url)` + "\n" +
+		path + ": 1 sections, 1 links, 0 errors, 1 warnings\n"
+	if stdout != want {
+		t.Errorf("stdout = %q, want %q", stdout, want)
+	}
+
+	if _, code := runMain(t, "validate", filepath.Join(t.TempDir(), "missing.txt")); code != 1 {
+		t.Errorf("exit code of a missing file = %d, want 1", code)
+	}
+}